@@ -0,0 +1,42 @@
+package goloadenv
+
+import (
+	"sort"
+	"sync"
+)
+
+// AccessLog records the names of the environment variables looked up during a load, so the exact set of variables a
+// service reads can be audited, e.g. to generate least-privilege policies for a secret store.
+type AccessLog struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// record adds a looked up key to the log.
+func (a *AccessLog) record(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.keys == nil {
+		a.keys = map[string]struct{}{}
+	}
+	a.keys[key] = struct{}{}
+}
+
+// Keys returns the sorted names of every environment variable looked up, whether or not it was set.
+func (a *AccessLog) Keys() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	keys := make([]string, 0, len(a.keys))
+	for key := range a.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WithAccessLog records the name of every environment variable looked up during the load in the given log.
+func WithAccessLog(log *AccessLog) Option {
+	return func(l *loader) {
+		l.access = log
+	}
+}
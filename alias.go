@@ -0,0 +1,97 @@
+package goloadenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeprecationWarning is raised as a warning when a deprecated environment variable is used: the old name of a field
+// given by its alias option, or the variable of a field with the deprecated option and no aliases.
+type DeprecationWarning struct {
+	// Env is the deprecated environment variable that was set.
+	Env string
+	// Replacement is the variable replacing it, empty when the field itself is deprecated.
+	Replacement string
+	// Message is the value of the deprecated option, if set.
+	Message string
+}
+
+// Error returns a string representation of the DeprecationWarning.
+func (e *DeprecationWarning) Error() string {
+	switch {
+	case e.Message != "":
+		return fmt.Sprintf("environment variable %s is deprecated: %s", e.Env, e.Message)
+	case e.Replacement != "":
+		return fmt.Sprintf("environment variable %s is deprecated, use %s instead", e.Env, e.Replacement)
+	}
+	return fmt.Sprintf("environment variable %s is deprecated", e.Env)
+}
+
+// aliases returns the old names of a field given by its alias option, with the prefix of the field applied.
+func aliases(tags map[string]string, prefix string) []string {
+	return nameList(tags, "alias", prefix)
+}
+
+// fallbacks returns the alternative names of a field given by its fallback option, or by the names after the first
+// in a chain like DATABASE_URL|DB_URL, with the prefix of the field applied.
+func fallbacks(tags map[string]string, prefix string) []string {
+	return nameList(tags, "fallback", prefix)
+}
+
+// nameList returns the comma separated variable names of a tag option, with the prefix of the field applied.
+func nameList(tags map[string]string, option string, prefix string) []string {
+	list, hasList := tags[option]
+	if !hasList {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, prefix+name)
+		}
+	}
+	return names
+}
+
+// aliasLookup returns a lookup that reads the variable of a field from the first of its old names that is set when
+// it is not set under its own name, raising a DeprecationWarning for the old name. A field with the deprecated option
+// and no aliases raises the warning when its own variable is set.
+// used internally by LoadEnv.
+func (l *loader) aliasLookup(lookup func(string) (string, bool), tags map[string]string, prefix string) func(string) (string, bool) {
+	name := tags["name"]
+	message, isDeprecated := tags["deprecated"]
+	names := aliases(tags, prefix)
+	if _, found := lookup(name); found {
+		if isDeprecated && len(names) == 0 {
+			l.warn(&DeprecationWarning{Env: name, Message: message})
+		}
+		return lookup
+	}
+	lookup, alias := firstSetLookup(lookup, name, names)
+	if alias != "" {
+		l.warn(&DeprecationWarning{Env: alias, Replacement: name, Message: message})
+	}
+	return lookup
+}
+
+// firstSetLookup returns a lookup that reads the variable of a field from the first of the given names that is set
+// when it is not set under its own name, together with the name that is used, or an empty name when none is set.
+// used internally by LoadEnv.
+func firstSetLookup(lookup func(string) (string, bool), name string, names []string) (func(string) (string, bool), string) {
+	if _, found := lookup(name); found {
+		return lookup, ""
+	}
+	for _, alternative := range names {
+		value, found := lookup(alternative)
+		if !found {
+			continue
+		}
+		return func(key string) (string, bool) {
+			if key == name {
+				return value, true
+			}
+			return lookup(key)
+		}, alternative
+	}
+	return lookup, ""
+}
@@ -0,0 +1,54 @@
+package goloadenv
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Banner renders a compact startup banner for a config struct: a header line identifying the build, followed by the
+// config values as logfmt pairs with secrets masked. The build info is typically obtained from debug.ReadBuildInfo
+// and may be nil, in which case the header is omitted. The options limit the size of the config values like they do for
+// Format, the output limit applies to the config line.
+//
+// Example:
+//
+//	info, _ := debug.ReadBuildInfo()
+//	banner, err := goloadenv.Banner(&cfg, info, goloadenv.WithValueTruncation(64))
+//	if err != nil {
+//	  return err
+//	}
+//	fmt.Println(banner)
+func Banner(config interface{}, info *debug.BuildInfo, opts ...PrintOption) (string, error) {
+	options := newPrintOptions(opts)
+	fields, err := printFields(config, options)
+	if err != nil {
+		return "", err
+	}
+	var pairs []string
+	appendLogfmtPairs(&pairs, options.truncateFields(fields))
+	banner := options.truncateOutput("config: " + strings.Join(pairs, " "))
+	if info != nil {
+		banner = buildHeader(info) + "\n" + banner
+	}
+	return banner, nil
+}
+
+// buildHeader formats the main module, its version, the Go version and the VCS revision of a build.
+func buildHeader(info *debug.BuildInfo) string {
+	details := []string{info.GoVersion}
+	settings := map[string]string{}
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+	if revision := settings["vcs.revision"]; revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		if settings["vcs.modified"] == "true" {
+			revision += "+dirty"
+		}
+		details = append(details, "rev "+revision)
+	}
+	return fmt.Sprintf("%s %s (%s)", info.Main.Path, info.Main.Version, strings.Join(details, ", "))
+}
@@ -0,0 +1,162 @@
+package goloadenv
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// FlagValue is the value of a field bound to a command-line flag by BindFlags. It implements flag.Value, and with its
+// Type method also the Value interface of github.com/spf13/pflag, so it can be registered on either flag set.
+type FlagValue interface {
+	flag.Value
+	// Type returns the name of the type of the field, e.g. int or duration, shown in the usage of pflag.
+	Type() string
+	// IsBoolFlag reports whether the field is a boolean, which the flag package accepts without a value.
+	IsBoolFlag() bool
+}
+
+// boundValue is the FlagValue of a field, parsing flag values straight into the field like the value of its
+// environment variable.
+type boundValue struct {
+	field reflect.Value
+	tags  map[string]string
+}
+
+func (v *boundValue) String() string {
+	if v == nil || !v.field.IsValid() {
+		return ""
+	}
+	if isSecret(v.tags, v.tags["name"]) && !v.field.IsZero() {
+		return secretMask
+	}
+	return envValue(v.field, v.tags)
+}
+
+func (v *boundValue) Set(value string) error {
+	var err error
+	if value == "" {
+		setEmptyValue(v.field)
+	} else {
+		err = setValue(v.field, value, v.tags)
+	}
+	if err == nil {
+		err = validateField(v.field, v.tags)
+	}
+	return err
+}
+
+func (v *boundValue) Type() string {
+	switch {
+	case v.field.Kind() == reflect.Bool:
+		return "bool"
+	case v.field.Type() == durationType:
+		return "duration"
+	}
+	return v.field.Type().String()
+}
+
+func (v *boundValue) IsBoolFlag() bool {
+	return v.field.Kind() == reflect.Bool
+}
+
+// BindToFlagSet registers a flag on the flag set for every tagged field of the config struct, so existing command-line
+// applications can adopt the struct tags without rewriting their flag definitions. Flags are named and described like
+// with WithFlags, and parse their value straight into the field when the flag set is parsed. Zero fields are set to
+// their default value first, which is also shown as the default of the flag, so loading the config from the
+// environment before binding makes the environment the default of the flags. The config must be a pointer to a struct,
+// and no flag is registered when the name of one is already defined on the flag set.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnv(&cfg)
+//	if err != nil {
+//	  return err
+//	}
+//	err = goloadenv.BindToFlagSet(flag.CommandLine, &cfg)
+//	if err != nil {
+//	  return err
+//	}
+//	flag.Parse()
+func BindToFlagSet(fs *flag.FlagSet, config interface{}) error {
+	type boundFlag struct {
+		name  string
+		usage string
+		value FlagValue
+	}
+	var flags []boundFlag
+	names := map[string]struct{}{}
+	var duplicate string
+	err := BindFlags(config, func(name string, usage string, value FlagValue) {
+		if _, defined := names[name]; (defined || fs.Lookup(name) != nil) && duplicate == "" {
+			duplicate = name
+		}
+		names[name] = struct{}{}
+		flags = append(flags, boundFlag{name, usage, value})
+	})
+	if err != nil {
+		return err
+	}
+	if duplicate != "" {
+		return fmt.Errorf("flag redefined: %s", duplicate)
+	}
+	for _, f := range flags {
+		fs.Var(f.value, f.name, f.usage)
+	}
+	return nil
+}
+
+// BindFlags calls bind with the flag name, usage and value of every tagged field of the config struct like
+// BindToFlagSet, for flag libraries other than the flag package. The values also implement pflag.Value.
+//
+// Example:
+//
+//	err := goloadenv.BindFlags(&cfg, func(name string, usage string, value goloadenv.FlagValue) {
+//	  flags.Var(value, name, usage)
+//	  if value.IsBoolFlag() {
+//	    flags.Lookup(name).NoOptDefVal = "true"
+//	  }
+//	})
+func BindFlags(config interface{}, bind func(name string, usage string, value FlagValue)) error {
+	val := reflect.ValueOf(config)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+	err := checkCycles(val.Elem().Type())
+	if err != nil {
+		return err
+	}
+	return iterateStruct(val.Elem(), "", "", tagName, func(f FieldInfo, v reflect.Value) error {
+		name := flagName(f)
+		if f.Name == "" || name == "" || !v.CanSet() {
+			return nil
+		}
+		err := setFlagDefault(v, f.Tags)
+		if err != nil {
+			return err
+		}
+		bind(name, f.StructField.Tag.Get(descTagName), &boundValue{field: v, tags: f.Tags})
+		return nil
+	})
+}
+
+// setFlagDefault sets a zero field to its default value, resolving variable references against the process
+// environment. Defaults that are templates of other fields are left to LoadEnv.
+func setFlagDefault(field reflect.Value, tags map[string]string) error {
+	defaultValue, hasDefault := tags["default"]
+	if !hasDefault || !field.IsZero() || isTemplateDefault(defaultValue) {
+		return nil
+	}
+	value, _, err := getField(tags, func(key string) (string, bool) {
+		if key == tags["name"] {
+			return "", false
+		}
+		return os.LookupEnv(key)
+	})
+	if err != nil {
+		return err
+	}
+	return setValue(field, value, tags)
+}
@@ -0,0 +1,88 @@
+package goloadenv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes parsed from a size with an optional unit, e.g. CACHE_SIZE=512MiB. The IEC units KiB,
+// MiB, GiB, TiB, PiB and EiB are powers of 1024 and can be shortened to Ki, Mi and so on, the SI units kB, MB, GB,
+// TB, PB and EB are powers of 1000 and can be shortened to k, M and so on. Units are case-insensitive and fractions are
+// rounded down to whole bytes, e.g. 1.5KiB is 1536 bytes. A value without a unit is a number of bytes.
+type ByteSize int64
+
+// byteUnits maps the lower case units of a ByteSize to their size in bytes.
+var byteUnits = func() map[string]float64 {
+	units := map[string]float64{"": 1, "b": 1}
+	for i, prefix := range []string{"k", "m", "g", "t", "p", "e"} {
+		units[prefix] = math.Pow(1000, float64(i+1))
+		units[prefix+"b"] = math.Pow(1000, float64(i+1))
+		units[prefix+"i"] = math.Pow(1024, float64(i+1))
+		units[prefix+"ib"] = math.Pow(1024, float64(i+1))
+	}
+	return units
+}()
+
+// UnmarshalText parses a size with an optional unit.
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	str := strings.TrimSpace(string(text))
+	split := strings.IndexFunc(str, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if split < 0 {
+		split = len(str)
+	}
+	number, unit := str[:split], strings.ToLower(strings.TrimSpace(str[split:]))
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size '%s'", str)
+	}
+	size, found := byteUnits[unit]
+	if !found {
+		return fmt.Errorf("unknown size unit '%s', expected B, kB, MB, GB, TB, PB, EB or KiB, MiB, GiB, TiB, PiB, EiB", str[split:])
+	}
+	// whole numbers are multiplied exactly, as a float64 cannot hold every int64
+	if whole, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if whole > math.MaxInt64/int64(size) {
+			return fmt.Errorf("size '%s' is too large", str)
+		}
+		*s = ByteSize(whole * int64(size))
+		return nil
+	}
+	bytes := math.Floor(value * size)
+	if bytes >= math.MaxInt64 {
+		return fmt.Errorf("size '%s' is too large", str)
+	}
+	*s = ByteSize(bytes)
+	return nil
+}
+
+// MarshalText formats the size like String, so it can be parsed again.
+func (s ByteSize) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// String formats the size with the IEC or SI unit that gives the smallest whole number, e.g. 512MiB or 10GB, and as
+// a number of bytes when it is not a whole multiple of a unit, e.g. 1023B.
+func (s ByteSize) String() string {
+	best, bestUnit := int64(s), "B"
+	for _, base := range []struct {
+		size  int64
+		units []string
+	}{
+		{1024, []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}},
+		{1000, []string{"kB", "MB", "GB", "TB", "PB", "EB"}},
+	} {
+		value := int64(s)
+		for _, unit := range base.units {
+			if value == 0 || value%base.size != 0 {
+				break
+			}
+			value /= base.size
+			if value < best {
+				best, bestUnit = value, unit
+			}
+		}
+	}
+	return strconv.FormatInt(best, 10) + bestUnit
+}
@@ -0,0 +1,123 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// countryCodes are the ISO 3166-1 alpha-2 country codes.
+var countryCodes = codeSet(`
+AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD
+CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD GE GF
+GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN
+KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ NA
+NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI
+SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI
+VN VU WF WS YE YT ZA ZM ZW`)
+
+// currencyCodes are the active ISO 4217 currency codes, including the funds and precious metals codes.
+var currencyCodes = codeSet(`
+AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN BWP BYN BZD CAD CDF CHE CHF
+CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD
+HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA
+MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB
+RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD USN
+UYI UYU UYW UZS VED VES VND VUV WST XAF XAG XAU XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF XPT XSU XTS XUA XXX YER ZAR ZMW
+ZWG ZWL`)
+
+// languageCodes are the ISO 639-1 language codes, the two letter primary language subtags of BCP 47.
+var languageCodes = codeSet(`
+aa ab ae af ak am an ar as av ay az ba be bg bi bm bn bo br bs ca ce ch co cr cs cu cv cy da de dv dz ee el en eo es et
+eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk
+kl km kn ko kr ks ku kv kw ky la lb lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny
+oc oj om or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw ta te tg th ti tk
+tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu`)
+
+// codeRules are the code tag options enforced by validateCode.
+var codeRules = []string{"iso3166", "iso4217", "bcp47"}
+
+// codeSet returns the set of the whitespace separated codes of a table.
+func codeSet(table string) map[string]struct{} {
+	codes := map[string]struct{}{}
+	for _, code := range strings.Fields(table) {
+		codes[code] = struct{}{}
+	}
+	return codes
+}
+
+// validateCode enforces a code option on a string field, iso3166 for a country code, e.g. NL, iso4217 for a currency
+// code, e.g. EUR, and bcp47 for a language tag, e.g. en-US. Codes are matched case-insensitively and canonicalized to
+// the case of the standard.
+func validateCode(field reflect.Value, tags map[string]string, rule string) error {
+	if field.Kind() != reflect.String {
+		return &EnvParseError{value: fmt.Sprint(field.Interface()), env: tags["name"], err: fmt.Errorf("%s only applies to string fields, not %s", rule, field.Type())}
+	}
+	var code, reason string
+	switch rule {
+	case "iso3166":
+		code, reason = strings.ToUpper(field.String()), "must be an ISO 3166-1 alpha-2 country code"
+		if _, found := countryCodes[code]; !found {
+			code = ""
+		}
+	case "iso4217":
+		code, reason = strings.ToUpper(field.String()), "must be an ISO 4217 currency code"
+		if _, found := currencyCodes[code]; !found {
+			code = ""
+		}
+	case "bcp47":
+		code, reason = canonicalLanguageTag(field.String()), "must be a BCP 47 language tag"
+	}
+	if code == "" {
+		return &ValidationError{Env: tags["name"], Rule: rule, Value: field.String(), Reason: reason}
+	}
+	field.SetString(code)
+	return nil
+}
+
+// canonicalLanguageTag returns the canonical form of a BCP 47 language tag of a language, an optional script, an
+// optional region and optional variants, e.g. zh-hant-tw as zh-Hant-TW, or an empty string if the tag is invalid. Two
+// letter languages and regions must be in the ISO 639-1 and ISO 3166-1 tables, three letter languages and numeric
+// regions are only checked for their form. Extensions and private use subtags are not supported.
+func canonicalLanguageTag(tag string) string {
+	subtags := strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")
+	language := strings.ToLower(subtags[0])
+	if _, found := languageCodes[language]; !found && (len(language) != 3 || !isAlpha(language)) {
+		return ""
+	}
+	canonical := []string{language}
+	rest := subtags[1:]
+	if len(rest) > 0 && len(rest[0]) == 4 && isAlpha(rest[0]) {
+		canonical = append(canonical, strings.ToUpper(rest[0][:1])+strings.ToLower(rest[0][1:]))
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && (len(rest[0]) == 2 || len(rest[0]) == 3 && isDigits(rest[0])) {
+		region := strings.ToUpper(rest[0])
+		if _, found := countryCodes[region]; !found && !isDigits(region) {
+			return ""
+		}
+		canonical = append(canonical, region)
+		rest = rest[1:]
+	}
+	for _, variant := range rest {
+		if len(variant) < 5 && !(len(variant) == 4 && variant[0] >= '0' && variant[0] <= '9') || len(variant) > 8 || !isAlphanumeric(variant) {
+			return ""
+		}
+		canonical = append(canonical, strings.ToLower(variant))
+	}
+	return strings.Join(canonical, "-")
+}
+
+func isAlpha(str string) bool {
+	return strings.IndexFunc(str, func(r rune) bool { return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') }) < 0
+}
+
+func isDigits(str string) bool {
+	return strings.IndexFunc(str, func(r rune) bool { return r < '0' || r > '9' }) < 0
+}
+
+func isAlphanumeric(str string) bool {
+	return strings.IndexFunc(str, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	}) < 0
+}
@@ -0,0 +1,58 @@
+package goloadenv
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// CommandEnv builds the environment of a child process, e.g. for exec.Cmd.Env, from a loaded config struct, so
+// supervisors and plugin hosts can forward the exact, validated configuration to subprocesses. Every field backed by an
+// environment variable is added as KEY=value to the base environment, e.g. os.Environ(), replacing a variable with the
+// same name. Values are written in the formats LoadEnv parses with the options of their field, so the child process
+// loads the same config. Secrets are not masked, also not those of Secret fields. Unset optional pointer fields and
+// fields with the omitempty flag holding their zero value are left out. A prefix map field adds a variable per entry,
+// e.g. FEATURE_DARK_MODE for env:"FEATURE_;prefixmap". When names are given, only the variables with these names are
+// added.
+//
+// Example:
+//
+//	cmd := exec.Command("worker")
+//	cmd.Env, err = goloadenv.CommandEnv(&cfg, os.Environ(), "DB_HOST", "DB_PORT")
+func CommandEnv(config interface{}, base []string, names ...string) ([]string, error) {
+	values := map[string]string{}
+	var order []string
+	err := Iterate(config, func(f FieldInfo, v reflect.Value) error {
+		if f.Name == "" || !f.StructField.IsExported() {
+			return nil
+		}
+		return fieldVariables(f.Name, v, f.Tags, func(name string, v reflect.Value, tags map[string]string) error {
+			if len(names) > 0 && !slices.Contains(names, name) || v.Kind() == reflect.Ptr && v.IsNil() || isOmitted(v, tags, false) {
+				return nil
+			}
+			if _, found := values[name]; !found {
+				order = append(order, name)
+			}
+			if secret, isSecret := v.Interface().(Secret); isSecret {
+				values[name] = secret.Expose()
+				return nil
+			}
+			values[name] = envValue(v, tags)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	env := make([]string, 0, len(base)+len(order))
+	for _, entry := range base {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, replaced := values[key]; !replaced {
+			env = append(env, entry)
+		}
+	}
+	for _, name := range order {
+		env = append(env, name+"="+values[name])
+	}
+	return env, nil
+}
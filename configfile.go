@@ -0,0 +1,83 @@
+package goloadenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const configFileTagName = "file"
+
+// readConfigFile reads a JSON or YAML configuration file, chosen by its extension, into JSON compatible values.
+func readConfigFile(path string) (interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	var document interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&document)
+	case ".yaml", ".yml":
+		document, err = parseYAML(string(content))
+	default:
+		return nil, fmt.Errorf("unsupported config file '%s', expected a .json, .yaml or .yml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file '%s': %w", path, err)
+	}
+	return document, nil
+}
+
+// configFileValue returns the value at the dotted key in a configuration file document, e.g. server.port.
+func configFileValue(document interface{}, key string) (interface{}, bool) {
+	value := document
+	for _, name := range strings.Split(key, ".") {
+		mapping, isMapping := value.(map[string]interface{})
+		if !isMapping {
+			return nil, false
+		}
+		value, isMapping = mapping[name]
+		if !isMapping {
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
+
+// readConfigFileValues reads the values of the fields with a file tag from the configuration file of WithConfigFile,
+// by the name of their environment variable. Values are converted to the format of environment variables: lists to
+// [a,b], mappings to comma separated key=value pairs, and any value of a field with the json or yaml format to JSON.
+func (l *loader) readConfigFileValues(config reflect.Value) error {
+	document, err := readConfigFile(l.configFile)
+	if err != nil {
+		return err
+	}
+	l.configValues = map[string]string{}
+	return iterateStruct(config, "", "", l.tagName, func(f FieldInfo, _ reflect.Value) error {
+		key := f.StructField.Tag.Get(configFileTagName)
+		if f.Name == "" || key == "" {
+			return nil
+		}
+		value, found := configFileValue(document, key)
+		if !found {
+			return nil
+		}
+		str := envValue(reflect.ValueOf(value), nil)
+		if isDocumentFormat(f.Tags["format"]) {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			str = string(encoded)
+		}
+		l.configValues[l.prefix+f.Name] = str
+		return nil
+	})
+}
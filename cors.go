@@ -0,0 +1,93 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CORSConfig is a ready to use configuration block for Cross-Origin Resource Sharing. It is meant to be nested under a
+// prefix, e.g.
+//
+//	type Config struct {
+//	  CORS goloadenv.CORSConfig `envPrefix:"CORS_"`
+//	}
+//
+// which loads CORS_ALLOWED_ORIGINS=[https://example.com,https://*.example.com] and so on. Call Validate after loading
+// to check the origins and methods.
+type CORSConfig struct {
+	// AllowedOrigins holds the allowed origins, "*" for any origin or an origin with a wildcard subdomain like
+	// https://*.example.com.
+	AllowedOrigins []string `env:"ALLOWED_ORIGINS;default:[*]"`
+	// AllowedMethods holds the allowed HTTP methods.
+	AllowedMethods []string `env:"ALLOWED_METHODS;default:[GET,HEAD,POST]"`
+	// AllowedHeaders holds the allowed request headers.
+	AllowedHeaders []string `env:"ALLOWED_HEADERS;optional"`
+	// AllowCredentials allows cookies and authorization headers on cross-origin requests.
+	AllowCredentials bool `env:"ALLOW_CREDENTIALS;default:false"`
+	// MaxAge is how long the result of a preflight request may be cached.
+	MaxAge time.Duration `env:"MAX_AGE;default:10m"`
+}
+
+var corsMethods = map[string]struct{}{
+	"GET": {}, "HEAD": {}, "POST": {}, "PUT": {}, "PATCH": {}, "DELETE": {}, "OPTIONS": {}, "CONNECT": {}, "TRACE": {},
+}
+
+// Validate checks that every origin is "*" or a valid scheme://host[:port] origin with at most a leading wildcard
+// subdomain, that "*" is not combined with credentials, which browsers reject, that the methods are HTTP methods and
+// that the max age is not negative.
+func (c CORSConfig) Validate() error {
+	var errs []error
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				errs = append(errs, errors.New("allowed origin * cannot be combined with credentials"))
+			}
+			continue
+		}
+		err := validateOrigin(origin)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, method := range c.AllowedMethods {
+		if _, found := corsMethods[method]; !found {
+			errs = append(errs, fmt.Errorf("invalid allowed method '%s'", method))
+		}
+	}
+	if c.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("max age %s must not be negative", c.MaxAge))
+	}
+	return errors.Join(errs...)
+}
+
+func validateOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid allowed origin '%s', expected scheme://host[:port]", origin)
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("invalid allowed origin '%s', origins cannot have a path, query or credentials", origin)
+	}
+	host := strings.TrimPrefix(u.Host, "*.")
+	if strings.Contains(host, "*") {
+		return fmt.Errorf("invalid allowed origin '%s', only a leading wildcard subdomain is supported", origin)
+	}
+	return nil
+}
+
+// AllowsOrigin reports whether the given request origin matches one of the allowed origins.
+func (c CORSConfig) AllowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		scheme, host, found := strings.Cut(allowed, "://*.")
+		if found && strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+host) {
+			return true
+		}
+	}
+	return false
+}
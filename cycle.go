@@ -0,0 +1,56 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// CycleError is returned when a config struct type contains itself through embedded struct pointers, which would
+// otherwise be followed forever.
+type CycleError struct {
+	// Type is the struct type that contains itself.
+	Type reflect.Type
+	// Path is the dotted path of the field where the type reappears.
+	Path string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cyclic config struct type %s at %s", e.Type, e.Path)
+}
+
+// checkCycles walks the nested struct types of a config struct type the same way loading does, and returns a
+// CycleError if a struct type is nested in itself. Named pointer fields are not followed when loading, so only
+// embedded struct pointers can close a cycle.
+func checkCycles(typ reflect.Type) error {
+	return checkTypeCycles(typ, "", map[reflect.Type]bool{typ: true})
+}
+
+func checkTypeCycles(typ reflect.Type, path string, visiting map[reflect.Type]bool) error {
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if isSkipped(structField, tagName) {
+			continue
+		}
+		nested := structField.Type
+		if structField.Anonymous && nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		if !isNestedStruct(nested) {
+			continue
+		}
+		if tags, _ := parseTags(structField, tagName); tags["format"] != "" {
+			continue
+		}
+		fieldPath := joinPath(path, structField.Name)
+		if visiting[nested] {
+			return &CycleError{Type: nested, Path: fieldPath}
+		}
+		visiting[nested] = true
+		err := checkTypeCycles(nested, fieldPath, visiting)
+		if err != nil {
+			return err
+		}
+		delete(visiting, nested)
+	}
+	return nil
+}
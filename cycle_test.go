@@ -0,0 +1,53 @@
+package goloadenv
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+type LinkedConfig struct {
+	Name string `env:"NAME"`
+	Next *LinkedConfig
+}
+
+type CyclicConfig struct {
+	Name string `env:"NAME"`
+	*CyclicChild
+}
+
+type CyclicChild struct {
+	*CyclicConfig
+}
+
+func TestCyclicStructs(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("NAME", "head")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	linked := struct {
+		Head LinkedConfig
+	}{}
+	err = LoadEnv(&linked)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if linked.Head.Name != "head" || linked.Head.Next != nil {
+		t.Errorf("Expected only the head to be loaded, got %v", linked.Head)
+	}
+
+	var cyclic CyclicConfig
+	expected := "cyclic config struct type goloadenv.CyclicConfig at CyclicChild.CyclicConfig"
+	var cycleErr *CycleError
+	err = LoadEnv(&cyclic)
+	if !errors.As(err, &cycleErr) || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	_, err = Format(&cyclic, "text")
+	if !errors.As(err, &cycleErr) {
+		t.Errorf("Expected a cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package goloadenv
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// encryptedPrefix marks an encrypted value, followed by the name of its decryptor and the base64 ciphertext.
+const encryptedPrefix = "enc:"
+
+var (
+	decryptorsMu sync.RWMutex
+	// decryptors holds the decryptors registered with RegisterDecryptor by name.
+	decryptors = map[string]func([]byte) ([]byte, error){}
+)
+
+// RegisterDecryptor registers a function decrypting the values of fields with the encrypted flag that name it, e.g.
+// DB_PASSWORD=enc:v1:c2VhbGVk for the decryptor v1, so encrypted values can be committed to .env files and decrypted
+// at load time with e.g. age, a KMS or sops. The decryptor is passed the base64 decoded ciphertext. Registering a name
+// again replaces its decryptor.
+//
+// Example:
+//
+//	goloadenv.RegisterDecryptor("v1", func(ciphertext []byte) ([]byte, error) {
+//	  return kms.Decrypt(ctx, keyID, ciphertext)
+//	})
+//	type Config struct {
+//	  Password string `env:"DB_PASSWORD;encrypted"`
+//	}
+func RegisterDecryptor(name string, decrypt func(ciphertext []byte) ([]byte, error)) {
+	decryptorsMu.Lock()
+	defer decryptorsMu.Unlock()
+	decryptors[name] = decrypt
+}
+
+// DecryptError is returned when the value of a field with the encrypted flag cannot be decrypted.
+type DecryptError struct {
+	// Env is the name of the environment variable of the field.
+	Env string
+	// Decryptor is the name of the decryptor of the value, empty when the value is not encrypted.
+	Decryptor string
+	// Err is the reason the value cannot be decrypted.
+	Err error
+}
+
+func (e *DecryptError) Error() string {
+	return fmt.Sprintf("error decrypting environment variable %s: %s", e.Env, e.Err)
+}
+
+func (e *DecryptError) Unwrap() error {
+	return e.Err
+}
+
+// decryptValue decrypts a value written as enc:NAME:CIPHERTEXT with the decryptor registered under the name.
+func decryptValue(str string, name string) (string, error) {
+	encrypted, found := strings.CutPrefix(str, encryptedPrefix)
+	if !found {
+		return "", &DecryptError{Env: name, Err: fmt.Errorf("value is not encrypted, expected %sNAME:CIPHERTEXT", encryptedPrefix)}
+	}
+	decryptorName, payload, found := strings.Cut(encrypted, ":")
+	if !found {
+		return "", &DecryptError{Env: name, Err: fmt.Errorf("missing decryptor name, expected %sNAME:CIPHERTEXT", encryptedPrefix)}
+	}
+	decryptorsMu.RLock()
+	decrypt, found := decryptors[decryptorName]
+	decryptorsMu.RUnlock()
+	if !found {
+		return "", &DecryptError{Env: name, Decryptor: decryptorName, Err: fmt.Errorf("no decryptor registered as '%s'", decryptorName)}
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", &DecryptError{Env: name, Decryptor: decryptorName, Err: fmt.Errorf("invalid base64: %w", err)}
+	}
+	plaintext, err := decrypt(ciphertext)
+	if err != nil {
+		return "", &DecryptError{Env: name, Decryptor: decryptorName, Err: err}
+	}
+	return string(plaintext), nil
+}
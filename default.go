@@ -0,0 +1,92 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// defaultFuncPrefix marks a default value that is computed by a function registered with RegisterDefaultFunc.
+const defaultFuncPrefix = "fn:"
+
+// templatePrefix marks a default value that is rendered as a text/template from the sibling fields, e.g.
+// default:tmpl:{{.Host}}:8080.
+const templatePrefix = "tmpl:"
+
+var (
+	defaultFuncsMu sync.RWMutex
+	// defaultFuncs holds the functions registered with RegisterDefaultFunc by name.
+	defaultFuncs = map[string]func() (string, error){}
+)
+
+// RegisterDefaultFunc registers a function computing a default value at load time, referenced by name in the default
+// tag option as default:fn:name. The function is called every time a field using it falls back to its default.
+//
+// Example:
+//
+//	goloadenv.RegisterDefaultFunc("hostname", os.Hostname)
+//	type Config struct {
+//	  NodeName string `env:"NODE_NAME;default:fn:hostname"`
+//	}
+func RegisterDefaultFunc(name string, fn func() (string, error)) {
+	defaultFuncsMu.Lock()
+	defer defaultFuncsMu.Unlock()
+	defaultFuncs[name] = fn
+}
+
+// callDefaultFunc computes a default value with the function registered under the given name.
+func callDefaultFunc(name string) (string, error) {
+	defaultFuncsMu.RLock()
+	fn, found := defaultFuncs[name]
+	defaultFuncsMu.RUnlock()
+	if !found {
+		return "", fmt.Errorf("no default function registered as '%s'", name)
+	}
+	return fn()
+}
+
+// templateRef matches the references to fields in a template default value, e.g. {{.Host}}.
+var templateRef = regexp.MustCompile(`\{\{-?\s*\.(\w+)`)
+
+// templateRefs returns the names of the fields referenced by the template default value of a field, if it has one.
+func templateRefs(tags map[string]string) []string {
+	defaultValue := tags["default"]
+	if !isTemplateDefault(defaultValue) {
+		return nil
+	}
+	var names []string
+	for _, match := range templateRef.FindAllStringSubmatch(defaultValue, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// isTemplateDefault reports whether a default value is a template.
+func isTemplateDefault(defaultValue string) bool {
+	return strings.HasPrefix(defaultValue, templatePrefix)
+}
+
+// renderDefault renders a default value that is a text/template, e.g. default:tmpl:{{.Host}}:8080, with the struct of
+// the field as data, so it can reference the sibling fields loaded before it. Referenced fields are loaded first, see
+// loadOrder. It is only called when the field falls back to its default.
+// used internally by LoadEnv.
+func renderDefault(tags map[string]string, val reflect.Value) error {
+	defaultValue, hasDefault := tags["default"]
+	if !hasDefault || !isTemplateDefault(defaultValue) {
+		return nil
+	}
+	tmpl, err := template.New(tags["name"]).Option("missingkey=error").Parse(strings.TrimPrefix(defaultValue, templatePrefix))
+	if err != nil {
+		return &EnvParseError{value: defaultValue, env: tags["name"], err: err}
+	}
+	var rendered strings.Builder
+	err = tmpl.Execute(&rendered, val.Interface())
+	if err != nil {
+		return &EnvParseError{value: defaultValue, env: tags["name"], err: err}
+	}
+	tags["default"] = rendered.String()
+	return nil
+}
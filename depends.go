@@ -0,0 +1,84 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// dependsTagName is the struct tag listing the sibling fields, comma separated, that must be loaded before a field.
+const dependsTagName = "envDependsOn"
+
+// DependencyError is returned when the envDependsOn struct tag of a field names an unknown field or closes a cycle.
+type DependencyError struct {
+	// Path is the dotted path of the field with the tag.
+	Path string
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("invalid dependencies of field '%s': %s", e.Path, e.Reason)
+}
+
+// loadOrder returns the indexes of the fields of a struct type in the order to load them: declaration order, except
+// that a field is moved after the fields named by its envDependsOn struct tag, referenced by its template default and
+// holding the variable of its requiredif condition.
+// The parsed tags of the fields are indexed like the fields, nil for a field whose tag cannot be parsed.
+func loadOrder(typ reflect.Type, tags []map[string]string, path string) ([]int, error) {
+	order := make([]int, 0, typ.NumField())
+	// state is 1 while a field is being ordered and 2 once it is ordered
+	state := make([]int, typ.NumField())
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case 1:
+			return &DependencyError{Path: joinPath(path, typ.Field(i).Name), Reason: "cyclic dependency"}
+		case 2:
+			return nil
+		}
+		state[i] = 1
+		if depends := typ.Field(i).Tag.Get(dependsTagName); depends != "" {
+			for _, name := range strings.Split(depends, ",") {
+				dependency, found := typ.FieldByName(strings.TrimSpace(name))
+				if !found || len(dependency.Index) != 1 {
+					return &DependencyError{Path: joinPath(path, typ.Field(i).Name), Reason: fmt.Sprintf("unknown field '%s'", strings.TrimSpace(name))}
+				}
+				err := visit(dependency.Index[0])
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if condition, hasCondition := tags[i]["requiredif"]; hasCondition {
+			name, _, _ := strings.Cut(condition, "=")
+			for j := range tags {
+				if j != i && tags[j]["name"] != "" && tags[j]["name"] == strings.TrimSpace(name) {
+					err := visit(j)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+		for _, name := range templateRefs(tags[i]) {
+			dependency, found := typ.FieldByName(name)
+			if found && len(dependency.Index) == 1 && dependency.Index[0] != i {
+				err := visit(dependency.Index[0])
+				if err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = 2
+		order = append(order, i)
+		return nil
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		err := visit(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
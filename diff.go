@@ -0,0 +1,96 @@
+package goloadenv
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Change describes a field whose value differs between two versions of a config struct.
+type Change struct {
+	// Path is the dotted path of the field from the root config struct, e.g. "DB.Host".
+	Path string
+	// Env is the name of the environment variable backing the field, empty if the field is not tagged.
+	Env string
+	// Old is the previous value of the field, masked for secret fields.
+	Old interface{}
+	// New is the current value of the field, masked for secret fields.
+	New interface{}
+}
+
+// Changes lists the changed fields of a config struct in declaration order.
+type Changes []Change
+
+// name returns the environment variable of the changed field, or its path if the field is not tagged.
+func (c Change) name() string {
+	if c.Env != "" {
+		return c.Env
+	}
+	return c.Path
+}
+
+// String formats the changes one per line as NAME: old -> new, for logging config changes between deployments.
+func (c Changes) String() string {
+	lines := make([]string, 0, len(c))
+	for _, change := range c {
+		lines = append(lines, change.name()+": "+formatValue(change.Old)+" -> "+formatValue(change.New))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LogValue implements slog.LogValuer, logging the changes as a group with an old and new value per changed variable.
+func (c Changes) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(c))
+	for _, change := range c {
+		attrs = append(attrs, slog.Group(change.name(),
+			slog.String("old", formatValue(change.Old)),
+			slog.String("new", formatValue(change.New))))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Diff compares two versions of a config struct and returns the fields whose values differ. Both configs must have the
+// same struct type, either may be a pointer to it. The values of secret fields are masked in the changes, but are
+// compared unmasked, so a rotated secret is reported as a change.
+func Diff(old interface{}, new interface{}) (Changes, error) {
+	oldType := reflect.TypeOf(old)
+	if oldType != nil && oldType.Kind() == reflect.Ptr {
+		oldType = oldType.Elem()
+	}
+	newType := reflect.TypeOf(new)
+	if newType != nil && newType.Kind() == reflect.Ptr {
+		newType = newType.Elem()
+	}
+	if oldType != newType {
+		return nil, errors.New("configs must have the same struct type")
+	}
+	var oldValues []reflect.Value
+	err := Iterate(old, func(f FieldInfo, v reflect.Value) error {
+		oldValues = append(oldValues, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var changes Changes
+	i := 0
+	err = Iterate(new, func(f FieldInfo, v reflect.Value) error {
+		oldValue := oldValues[i]
+		i++
+		if !f.StructField.IsExported() || reflect.DeepEqual(oldValue.Interface(), v.Interface()) {
+			return nil
+		}
+		change := Change{Path: f.Path, Env: f.Name, Old: oldValue.Interface(), New: v.Interface()}
+		if isSecret(f.Tags, f.Name) {
+			change.Old = maskSecret(oldValue)
+			change.New = maskSecret(v)
+		}
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,196 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadDotEnv reads the given .env files, or ".env" when no paths are given, and sets their variables in the process
+// environment. Variables that are already set in the process environment are not overridden, and for variables set
+// in several files the first file wins.
+//
+// The files consist of KEY=VALUE lines, optionally prefixed with export. Blank lines and lines starting with # are
+// ignored. Values can be single quoted to be taken literally, or double quoted to support the \n, \r, \t, \" and \\
+// escapes, and quoted values may span multiple lines. Unquoted values are trimmed and end at a " #" comment.
+func LoadDotEnv(paths ...string) error {
+	return loadDotEnv(paths, false)
+}
+
+// OverloadDotEnv reads the given .env files like LoadDotEnv, but their variables override the process environment,
+// and for variables set in several files the last file wins.
+func OverloadDotEnv(paths ...string) error {
+	return loadDotEnv(paths, true)
+}
+
+// LoadWithDotenv loads environment variables into the provided config struct like LoadEnvWithOptions, reading variables
+// from the given .env files, or ".env" when no paths are given, as a fallback for the process environment. The files
+// are read as with the WithDotEnv option, so the process environment is not modified. Missing files fail the load
+// unless the WithOptionalDotEnv option is given.
+//
+// Example:
+//
+//	err := goloadenv.LoadWithDotenv([]string{".env", ".env.local"}, &cfg, goloadenv.WithOptionalDotEnv())
+func LoadWithDotenv(paths []string, config interface{}, opts ...Option) error {
+	return LoadEnvWithOptions(config, append([]Option{WithDotEnv(paths...)}, opts...)...)
+}
+
+// UserEnvFile returns the path of the per-user .env file of the given application, env in the application directory
+// within the user configuration directory, e.g. $XDG_CONFIG_HOME/myapp/env or ~/.config/myapp/env on Linux,
+// ~/Library/Application Support/myapp/env on macOS and %AppData%\myapp\env on Windows. See WithUserEnvFile.
+func UserEnvFile(app string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, app, "env"), nil
+}
+
+func loadDotEnv(paths []string, override bool) error {
+	env, err := readDotEnvFiles(paths, override, false)
+	if err != nil {
+		return err
+	}
+	for key, value := range env {
+		if _, found := os.LookupEnv(key); found && !override {
+			continue
+		}
+		err = os.Setenv(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDotEnvFiles reads and merges the given .env files, or ".env" when no paths are given. When override is set later
+// files take precedence over earlier ones, otherwise the first file setting a variable wins. Files that do not exist
+// are skipped when skipMissing is set.
+func readDotEnvFiles(paths []string, override bool, skipMissing bool) (map[string]string, error) {
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+	merged := map[string]string{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if skipMissing && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading env file: %w", err)
+		}
+		env, err := ParseDotEnv(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing env file '%s': %w", path, err)
+		}
+		for key, value := range env {
+			if _, found := merged[key]; found && !override {
+				continue
+			}
+			merged[key] = value
+		}
+	}
+	return merged, nil
+}
+
+// readUserEnvFile reads the per-user .env file of the given application, an unknown user configuration directory or a
+// missing file yield no variables.
+func readUserEnvFile(app string) (map[string]string, error) {
+	path, err := UserEnvFile(app)
+	if err != nil {
+		return map[string]string{}, nil
+	}
+	return readDotEnvFiles([]string{path}, false, true)
+}
+
+// ParseDotEnv parses the content of a .env file into a map of variables, see LoadDotEnv for the format.
+func ParseDotEnv(content string) (map[string]string, error) {
+	env := map[string]string{}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	line := 1
+	for len(content) > 0 {
+		var statement string
+		statement, content, _ = strings.Cut(content, "\n")
+		trimmed := strings.TrimSpace(statement)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			line++
+			continue
+		}
+		trimmed = strings.TrimPrefix(trimmed, "export ")
+		key, rest, found := strings.Cut(trimmed, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		value, remaining, lines, err := parseDotEnvValue(rest, content)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		env[key] = value
+		content = remaining
+		line += lines
+	}
+	return env, nil
+}
+
+// parseDotEnvValue parses the value of a variable starting at rest, the remainder of its line, with content holding the
+// lines after it for quoted values spanning multiple lines. It returns the value, the content left after the value and
+// the number of lines consumed.
+func parseDotEnvValue(rest string, content string) (string, string, int, error) {
+	if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+		if index := strings.Index(rest, " #"); index >= 0 {
+			rest = rest[:index]
+		}
+		return strings.TrimSpace(rest), content, 1, nil
+	}
+	quote := rest[0]
+	find := func(text string) int {
+		if quote == '\'' {
+			return strings.IndexByte(text, quote)
+		}
+		return closingQuote(text)
+	}
+	text := rest[1:]
+	end := find(text)
+	multiline := end < 0
+	if multiline {
+		text += "\n" + content
+		end = find(text)
+	}
+	if end < 0 {
+		return "", "", 0, errors.New("unterminated quoted value")
+	}
+	value := text[:end]
+	lines := strings.Count(value, "\n") + 1
+	remaining := content
+	if multiline {
+		_, remaining, _ = strings.Cut(text[end:], "\n")
+	}
+	if quote == '\'' {
+		return value, remaining, lines, nil
+	}
+	return unescapeDotEnv(value), remaining, lines, nil
+}
+
+// closingQuote returns the index of the first unescaped double quote in text, or -1 if there is none.
+func closingQuote(text string) int {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+var dotEnvEscapes = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+
+func unescapeDotEnv(value string) string {
+	return dotEnvEscapes.Replace(value)
+}
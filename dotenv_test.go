@@ -0,0 +1,158 @@
+package goloadenv
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	content := `# database settings
+DB_HOST=localhost # inline comment
+export DB_PORT=5432
+DB_PASSWORD='p@ss#word'
+GREETING="hello\n\"world\""
+CERT="-----BEGIN CERTIFICATE-----
+MIIB
+-----END CERTIFICATE-----"
+
+EMPTY=
+`
+	expected := map[string]string{
+		"DB_HOST":     "localhost",
+		"DB_PORT":     "5432",
+		"DB_PASSWORD": "p@ss#word",
+		"GREETING":    "hello\n\"world\"",
+		"CERT":        "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----",
+		"EMPTY":       "",
+	}
+
+	env, err := ParseDotEnv(content)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+}
+
+func TestParseDotEnvError(t *testing.T) {
+	_, err := ParseDotEnv("DB_HOST=localhost\nDB_PASSWORD=\"secret\n")
+	expected := "line 2: unterminated quoted value"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestLoadEnvWithDotEnv(t *testing.T) {
+	clearTestEnv()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(path, []byte("HOST=from-file\nPORT=8080\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("HOST", "from-env")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg := TestConfig{}
+	err = LoadEnvWithOptions(&cfg, WithDotEnv(path))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Host != "from-env" || cfg.Port != 8080 {
+		t.Errorf("Expected HOST=from-env and PORT=8080, got %s and %d", cfg.Host, cfg.Port)
+	}
+
+	clearTestEnv()
+	err = os.Setenv("HOST", "from-env")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = LoadEnvWithOptions(&cfg, WithDotEnvOverride(path))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Host != "from-file" {
+		t.Errorf("Expected HOST=from-file, got %s", cfg.Host)
+	}
+}
+
+func TestLoadWithDotenv(t *testing.T) {
+	clearTestEnv()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	err := os.WriteFile(path, []byte("HOST=from-file\nPORT=8080\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	missing := filepath.Join(dir, ".env.local")
+
+	cfg := TestConfig{}
+	err = LoadWithDotenv([]string{missing, path}, &cfg, WithOptionalDotEnv())
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Host != "from-file" || cfg.Port != 8080 {
+		t.Errorf("Expected HOST=from-file and PORT=8080, got %s and %d", cfg.Host, cfg.Port)
+	}
+	if _, found := os.LookupEnv("HOST"); found {
+		t.Errorf("Expected the process environment to be left untouched")
+	}
+
+	err = LoadWithDotenv([]string{missing, path}, &cfg)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
+
+func TestLoadEnvWithUserEnvFile(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "ios" || runtime.GOOS == "plan9" {
+		t.Skip("user configuration directory does not follow XDG_CONFIG_HOME")
+	}
+	clearTestEnv()
+
+	dir := t.TempDir()
+	err := os.Mkdir(filepath.Join(dir, "myapp"), 0o700)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "myapp", "env"), []byte("HOST=from-user\nPORT=9090\nDEFAULT=from-user\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	path := filepath.Join(dir, ".env")
+	err = os.WriteFile(path, []byte("PORT=8080\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("XDG_CONFIG_HOME", dir)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("HOST", "from-env")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg := TestConfig{}
+	err = LoadEnvWithOptions(&cfg, WithDotEnv(path), WithUserEnvFile("myapp"))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Host != "from-env" || cfg.Port != 8080 || cfg.Default != "from-user" {
+		t.Errorf("Expected HOST=from-env, PORT=8080 and DEFAULT=from-user, got %s, %d and %s", cfg.Host, cfg.Port, cfg.Default)
+	}
+
+	err = LoadEnvWithOptions(&cfg, WithDotEnv(path), WithUserEnvFile("otherapp"))
+	if err != nil {
+		t.Errorf("Expected no error for a missing user env file, got %v", err)
+	}
+}
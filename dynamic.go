@@ -0,0 +1,106 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldSpec declares an environment variable loaded by LoadEnvDynamic, the runtime equivalent of a tagged struct
+// field.
+type FieldSpec struct {
+	// Name is the name of the environment variable, and its key in the loaded map.
+	Name string
+	// Type is the type the value is parsed into, defaults to string.
+	Type reflect.Type
+	// Default is the default value, nil if the variable has none.
+	Default *string
+	// Optional is set for variables that may be left unset, they are left out of the loaded map when unset.
+	Optional bool
+	// Secret is set for variables whose values are masked in errors and reports.
+	Secret bool
+	// Options are further tag options separated by semicolons, e.g. "min:1;max:10".
+	Options string
+}
+
+// LoadEnvDynamic loads the environment variables declared by a schema built at runtime, e.g. by plugins and rule
+// engines that cannot declare their variables in struct tags, into a map by variable name. The variables are parsed
+// and validated like the fields of a config struct with the corresponding tags, and the options apply as in
+// LoadEnvWithOptions. Names must be unique. Optional variables that are not set and have no default are left out of the
+// map. A spec with a struct type loads a nested config struct, with the name as the prefix of its variables, e.g.
+// PLUGIN_ for PLUGIN_HOST.
+//
+// Example:
+//
+//	timeout := "5s"
+//	values, err := goloadenv.LoadEnvDynamic([]goloadenv.FieldSpec{
+//	  {Name: "PLUGIN_URL"},
+//	  {Name: "PLUGIN_TIMEOUT", Type: reflect.TypeFor[time.Duration](), Default: &timeout},
+//	  {Name: "PLUGIN_RETRIES", Type: reflect.TypeFor[int](), Optional: true, Options: "min:0;max:10"},
+//	})
+func LoadEnvDynamic(schema []FieldSpec, opts ...Option) (map[string]any, error) {
+	l := newLoader(opts...)
+	fields := make([]reflect.StructField, len(schema))
+	names := make(map[string]struct{}, len(schema))
+	for i, spec := range schema {
+		tag, err := specTag(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, duplicate := names[spec.Name]; duplicate {
+			return nil, fmt.Errorf("duplicate environment variable name '%s' in field spec", spec.Name)
+		}
+		names[spec.Name] = struct{}{}
+		typ := spec.Type
+		if typ == nil {
+			typ = reflect.TypeFor[string]()
+		}
+		fields[i] = reflect.StructField{Name: "Field" + strconv.Itoa(i), Type: typ, Tag: reflect.StructTag(l.tagName + ":" + strconv.Quote(tag))}
+		if isNestedStruct(typ) {
+			fields[i].Tag = reflect.StructTag(prefixTagName + ":" + strconv.Quote(spec.Name))
+		}
+	}
+	config := reflect.New(reflect.StructOf(fields))
+	l.report = &Report{}
+	err := l.load(config.Interface())
+	if err != nil {
+		return nil, err
+	}
+	report := l.report
+	values := make(map[string]any, len(schema))
+	for i, spec := range schema {
+		// the fields of nested structs are reported by their own paths
+		if isNestedStruct(fields[i].Type) {
+			values[spec.Name] = config.Elem().Field(i).Interface()
+		}
+	}
+	for _, field := range report.Fields {
+		i, err := strconv.Atoi(strings.TrimPrefix(field.Path, "Field"))
+		if err == nil && field.Origin != OriginUnset {
+			values[schema[i].Name] = config.Elem().Field(i).Interface()
+		}
+	}
+	return values, nil
+}
+
+// specTag returns the env struct tag of the field declared by a FieldSpec.
+func specTag(spec FieldSpec) (string, error) {
+	if spec.Name == "" || strings.ContainsAny(spec.Name, ";|='\\") {
+		return "", fmt.Errorf("invalid environment variable name '%s' in field spec", spec.Name)
+	}
+	options := []string{spec.Name}
+	if spec.Default != nil {
+		options = append(options, "default:'"+strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(*spec.Default)+"'")
+	}
+	if spec.Optional {
+		options = append(options, "optional")
+	}
+	if spec.Secret {
+		options = append(options, "secret")
+	}
+	if spec.Options != "" {
+		options = append(options, spec.Options)
+	}
+	return strings.Join(options, ";"), nil
+}
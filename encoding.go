@@ -0,0 +1,51 @@
+package goloadenv
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// byteSliceType is the type of fields that can be decoded with the encoding tag option.
+var byteSliceType = reflect.TypeOf([]byte(nil))
+
+// decodeEncoding decodes a binary value into a []byte field according to the encoding tag option: "base64" (standard
+// alphabet), "base64url" (URL safe alphabet) or "hex". Base64 values must be padded, as written by encodeEncoding.
+func decodeEncoding(field reflect.Value, str string, encoding string) error {
+	if field.Type() != byteSliceType {
+		return fmt.Errorf("encoding option requires a []byte field, got %s", field.Type())
+	}
+	if !field.CanSet() {
+		return fmt.Errorf("field cannot be set")
+	}
+	var decoded []byte
+	var err error
+	switch encoding {
+	case "base64":
+		decoded, err = base64.StdEncoding.Strict().DecodeString(str)
+	case "base64url":
+		decoded, err = base64.URLEncoding.Strict().DecodeString(str)
+	case "hex":
+		decoded, err = hex.DecodeString(str)
+	default:
+		return fmt.Errorf("unknown encoding '%s', expected base64, base64url or hex", encoding)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", encoding, err)
+	}
+	field.SetBytes(decoded)
+	return nil
+}
+
+// encodeEncoding encodes a binary value according to the encoding tag option, the reverse of decodeEncoding. Unknown
+// encodings fall back to base64.
+func encodeEncoding(data []byte, encoding string) string {
+	switch encoding {
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(data)
+	case "hex":
+		return hex.EncodeToString(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
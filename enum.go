@@ -0,0 +1,38 @@
+package goloadenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// enumValue maps a value to the value of its member in the enum tag option, e.g. prod to 2 for
+// enum:dev=0,staging=1,prod=2. A member without a value, e.g. prod in enum:dev,staging,prod, maps to itself. It returns
+// an error if the value is not a member.
+func enumValue(str string, enum string) (string, error) {
+	members := strings.Split(enum, ",")
+	names := make([]string, len(members))
+	for i, member := range members {
+		name, value, hasValue := strings.Cut(member, "=")
+		names[i] = strings.TrimSpace(name)
+		if names[i] != str {
+			continue
+		}
+		if !hasValue {
+			return names[i], nil
+		}
+		return strings.TrimSpace(value), nil
+	}
+	return "", fmt.Errorf("must be one of %s", strings.Join(names, ", "))
+}
+
+// enumName maps a value back to the name of its member in the enum tag option, the reverse of enumValue, e.g. 2 to prod
+// for enum:dev=0,staging=1,prod=2. A value that is not the value of a member is returned as is.
+func enumName(str string, enum string) string {
+	for _, member := range strings.Split(enum, ",") {
+		name, value, hasValue := strings.Cut(member, "=")
+		if hasValue && strings.TrimSpace(value) == str {
+			return strings.TrimSpace(name)
+		}
+	}
+	return str
+}
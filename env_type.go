@@ -1,26 +1,317 @@
 package goloadenv
 
 import (
+	"encoding"
+	"fmt"
 	"log/slog"
+	"net"
+	"net/mail"
 	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
 )
 
+// Deprecated: EnvType boxes every parsed value in an interface{}, use TypedEnvType instead.
 type EnvType func(string) (interface{}, error)
 
+// Deprecated: EnvTypeInterface boxes every parsed value in an interface{}, use EnvUnmarshaler instead.
 type EnvTypeInterface interface {
 	UnmarshalEnv(string) (interface{}, error)
 }
 
-var envTypes = map[reflect.Type]EnvType{
-	reflect.TypeFor[slog.Level](): UnmarshalEnvSlogLevel,
+// TypedEnvType parses the string value of an environment variable into a T.
+type TypedEnvType[T any] func(string) (T, error)
+
+// EnvUnmarshaler is implemented by types that can parse themselves from the string value of an environment variable.
+type EnvUnmarshaler[T any] interface {
+	UnmarshalEnv(string) (T, error)
+}
+
+// EnvFormatUnmarshaler is implemented by types that can parse themselves from the string value of an environment
+// variable in the format given by the format tag option, e.g. env:"TTL;format:seconds".
+type EnvFormatUnmarshaler[T any] interface {
+	UnmarshalEnvFormat(str string, format string) (T, error)
+}
+
+// envSetter parses a string and assigns the result to the given addressable field.
+type envSetter func(field reflect.Value, str string) error
+
+// formatSetter parses a string in the given format and assigns the result to the given addressable field.
+type formatSetter func(field reflect.Value, str string, format string) error
+
+// typesMu guards envTypes and formatTypes, so types can be registered while configs are loaded.
+var typesMu sync.RWMutex
+
+// envTypes holds the parsers of the types registered with RegisterTypedEnvType and its variants, and of the supported
+// standard library types.
+var envTypes = map[reflect.Type]envSetter{
+	reflect.TypeFor[slog.Level]():    typedSetter(unmarshalSlogLevel),
+	reflect.TypeFor[time.Duration](): typedSetter(time.ParseDuration),
+	timeType:                         typedSetter(unmarshalTime),
+	urlType:                          typedSetter(parseURL),
+	reflect.TypeFor[net.IPNet]():     typedSetter(parseIPNet),
+	reflect.TypeFor[mail.Address]():  typedSetter(parseMailAddress),
+	reflect.TypeFor[net.TCPAddr]():   typedSetter(parseTCPAddr),
+	locationType:                     typedSetter(time.LoadLocation),
+	regexpType:                       typedSetter(regexp.Compile),
+	templateType:                     typedSetter(parseTemplate),
+}
+
+// formatTypes holds the parsers of the types that support the format tag option, besides the json and yaml formats
+// supported by every type.
+var formatTypes = map[reflect.Type]formatSetter{
+	durationType: typedFormatSetter(parseDurationFormat),
+	timeType:     typedFormatSetter(parseTime),
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// durationUnits maps the units accepted by the format tag option of time.Duration fields to their duration.
+var durationUnits = map[string]time.Duration{
+	"nanoseconds":  time.Nanosecond,
+	"microseconds": time.Microsecond,
+	"milliseconds": time.Millisecond,
+	"seconds":      time.Second,
+	"minutes":      time.Minute,
+	"hours":        time.Hour,
 }
 
+// timeLayouts maps the names of the layout constants of the time package to their layouts, so they can be used in
+// the layout tag option.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// RegisterTypedEnvType registers the UnmarshalEnv method of T as the parser for fields of type T.
+func RegisterTypedEnvType[T EnvUnmarshaler[T]]() {
+	var proto T
+	registerEnvSetter(reflect.TypeFor[T](), typedSetter(proto.UnmarshalEnv))
+}
+
+// RegisterEnvTypeFunc registers the given function as the parser for fields of type T, for types that cannot carry an
+// UnmarshalEnv method because they are defined in another package, e.g.
+// RegisterEnvTypeFunc(uuid.Parse) for uuid.UUID fields.
+func RegisterEnvTypeFunc[T any](unmarshaller func(string) (T, error)) {
+	registerEnvSetter(reflect.TypeFor[T](), typedSetter(unmarshaller))
+}
+
+// RegisterFormattedEnvType registers the UnmarshalEnvFormat method of T as the parser for fields of type T with a
+// format tag option other than json or yaml. Fields without the option are still parsed by a parser registered with
+// RegisterTypedEnvType, or by their encoding.TextUnmarshaler implementation.
+func RegisterFormattedEnvType[T EnvFormatUnmarshaler[T]]() {
+	var proto T
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	formatTypes[reflect.TypeFor[T]()] = typedFormatSetter(proto.UnmarshalEnvFormat)
+}
+
+// Deprecated: RegisterEnvType boxes every parsed value in an interface{}, use RegisterTypedEnvType instead.
 func RegisterEnvType[T EnvTypeInterface]() {
 	var proto T
-	envTypes[reflect.TypeFor[T]()] = proto.UnmarshalEnv
+	unmarshaller := proto.UnmarshalEnv
+	registerEnvSetter(reflect.TypeFor[T](), func(field reflect.Value, str string) error {
+		value, err := unmarshaller(str)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(value))
+		return nil
+	})
+}
+
+// registerEnvSetter registers the parser of a type, replacing any parser registered before.
+func registerEnvSetter(typ reflect.Type, setter envSetter) {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	envTypes[typ] = setter
+}
+
+// envSetterFor returns the parser registered for a type.
+func envSetterFor(typ reflect.Type) (envSetter, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	setter, found := envTypes[typ]
+	return setter, found
 }
 
+// formatSetterFor returns the parser supporting the format tag option registered for a type.
+func formatSetterFor(typ reflect.Type) (formatSetter, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	setter, found := formatTypes[typ]
+	return setter, found
+}
+
+// typedSetter wraps a TypedEnvType in an envSetter that assigns the parsed value through a typed pointer, so the value
+// is never boxed in an interface{}.
+func typedSetter[T any](unmarshaller TypedEnvType[T]) envSetter {
+	return func(field reflect.Value, str string) error {
+		value, err := unmarshaller(str)
+		if err != nil {
+			return err
+		}
+		*field.Addr().Interface().(*T) = value
+		return nil
+	}
+}
+
+// typedFormatSetter wraps a parser taking a format in a formatSetter that assigns the parsed value through a typed
+// pointer.
+func typedFormatSetter[T any](unmarshaller func(string, string) (T, error)) formatSetter {
+	return func(field reflect.Value, str string, format string) error {
+		value, err := unmarshaller(str, format)
+		if err != nil {
+			return err
+		}
+		*field.Addr().Interface().(*T) = value
+		return nil
+	}
+}
+
+// hasFormatParser reports whether values of the given type, or the elements of a pointer, slice or array type, are
+// parsed by a parser that supports the format tag option.
+func hasFormatParser(typ reflect.Type) bool {
+	for {
+		if _, registered := formatSetterFor(typ); registered {
+			return true
+		}
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			typ = typ.Elem()
+		default:
+			return false
+		}
+	}
+}
+
+var (
+	textUnmarshalerType   = reflect.TypeFor[encoding.TextUnmarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// hasCustomParser reports whether values of the given type are parsed by a registered unmarshaller or by their own
+// encoding.TextUnmarshaler or encoding.BinaryUnmarshaler implementation, rather than based on their kind.
+func hasCustomParser(typ reflect.Type) bool {
+	if _, registered := envSetterFor(typ); registered {
+		return true
+	}
+	if _, registered := formatSetterFor(typ); registered {
+		return true
+	}
+	ptr := reflect.PointerTo(typ)
+	return ptr.Implements(textUnmarshalerType) || ptr.Implements(binaryUnmarshalerType)
+}
+
+// unmarshalEncoding parses a string into an addressable field whose type implements encoding.TextUnmarshaler, or
+// encoding.BinaryUnmarshaler as a fallback. It reports whether the type implements either interface.
+func unmarshalEncoding(field reflect.Value, str string) (bool, error) {
+	switch unmarshaler := field.Addr().Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return true, unmarshaler.UnmarshalText([]byte(str))
+	case encoding.BinaryUnmarshaler:
+		return true, unmarshaler.UnmarshalBinary([]byte(str))
+	}
+	return false, nil
+}
+
+// isNestedStruct reports whether fields of the given type are loaded as a nested config struct rather than parsed
+// from a single environment variable.
+func isNestedStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !hasCustomParser(typ)
+}
+
+// Deprecated: UnmarshalEnvSlogLevel boxes the parsed level in an interface{}, slog.Level fields are supported out of
+// the box.
 func UnmarshalEnvSlogLevel(string string) (interface{}, error) {
+	return unmarshalSlogLevel(string)
+}
+
+func unmarshalSlogLevel(string string) (slog.Level, error) {
 	var level slog.Level
 	return level, level.UnmarshalText([]byte(string))
 }
+
+func unmarshalTime(string string) (time.Time, error) {
+	return time.Parse(time.RFC3339, string)
+}
+
+// parseTime parses a time with the given layout, which is either a layout string or the name of one of the layout
+// constants of the time package, e.g. RFC1123 or DateOnly, or unix or unixmilli for a Unix timestamp in seconds or
+// milliseconds.
+func parseTime(string string, layout string) (time.Time, error) {
+	switch layout {
+	case "unix", "unixmilli":
+		timestamp, err := strconv.ParseInt(string, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s timestamp", layout)
+		}
+		if layout == "unixmilli" {
+			return time.UnixMilli(timestamp).UTC(), nil
+		}
+		return time.Unix(timestamp, 0).UTC(), nil
+	}
+	if named, found := timeLayouts[layout]; found {
+		layout = named
+	}
+	value, err := time.Parse(layout, string)
+	if err != nil {
+		return value, fmt.Errorf("invalid time for layout %s: %w", layout, err)
+	}
+	return value, nil
+}
+
+// formatTime formats a time in the given layout the way parseTime parses it, e.g. as a Unix timestamp for unix.
+func formatTime(value time.Time, layout string) string {
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(value.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(value.UnixMilli(), 10)
+	}
+	if named, found := timeLayouts[layout]; found {
+		layout = named
+	}
+	return value.Format(layout)
+}
+
+// formatDuration formats a duration as a plain number of the given unit the way parseDurationFormat parses it, e.g. 30
+// for 30 seconds. Unknown units fall back to the time.ParseDuration syntax.
+func formatDuration(value time.Duration, unit string) string {
+	size, found := durationUnits[unit]
+	if !found {
+		return value.String()
+	}
+	return strconv.FormatFloat(float64(value)/float64(size), 'f', -1, 64)
+}
+
+// parseDurationFormat parses a duration written as a plain number of the given unit, e.g. 30 for seconds, falling back
+// to the time.ParseDuration syntax so 30s is accepted as well.
+func parseDurationFormat(string string, unit string) (time.Duration, error) {
+	size, found := durationUnits[unit]
+	if !found {
+		return 0, fmt.Errorf("unknown duration unit '%s', expected nanoseconds, microseconds, milliseconds, seconds, minutes or hours", unit)
+	}
+	value, err := strconv.ParseFloat(string, 64)
+	if err != nil {
+		return time.ParseDuration(string)
+	}
+	return time.Duration(value * float64(size)), nil
+}
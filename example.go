@@ -0,0 +1,81 @@
+package goloadenv
+
+import (
+	"reflect"
+)
+
+// ExampleConfig fills the provided config struct with example values, without reading the environment, for
+// documentation, API mocks and sample output. Every field is set to the value of its example tag option, e.g.
+// env:"HOST;example:db.internal", or else to its default value, fields with neither are left at their zero value.
+// Secret references are not resolved. The values are parsed and validated as in LoadEnvWithOptions, and PostLoad and
+// Validate hooks are called, so options such as WithPrefix and WithTagName apply, while options selecting sources are
+// ignored.
+//
+// Example:
+//
+//	var cfg Config
+//	err := goloadenv.ExampleConfig(&cfg)
+//	fmt.Println(goloadenv.FormatString(&cfg))
+func ExampleConfig(config interface{}, opts ...Option) error {
+	l := newLoader(opts...)
+	l.examples = true
+	l.lookup = MapSource{}.Lookup
+	l.dotEnv = nil
+	l.userEnvApp = ""
+	l.secretSources = nil
+	l.fileFallback = false
+	l.rejectUnknown = nil
+	return l.load(config)
+}
+
+// ApplyDefaults sets the fields of the provided config struct that have a default value to it, without reading the
+// environment, e.g. to construct baseline configs in tests or to document the effective defaults. Fields without a
+// default value are left untouched. Defaults are computed, parsed and validated as in LoadEnvWithOptions, so options
+// such as WithPrefix and WithTagName apply, while options selecting sources are ignored. Secret references are not
+// resolved and PostLoad and Validate hooks are not called, as the config is usually incomplete.
+//
+// Example:
+//
+//	cfg := Config{Host: "test.internal"}
+//	err := goloadenv.ApplyDefaults(&cfg)
+func ApplyDefaults(config interface{}, opts ...Option) error {
+	l := newLoader(opts...)
+	l.defaultsOnly = true
+	l.lookup = MapSource{}.Lookup
+	l.dotEnv = nil
+	l.userEnvApp = ""
+	l.secretSources = nil
+	l.kvSources = nil
+	l.fileFallback = false
+	l.flags = false
+	l.configFile = ""
+	l.rejectUnknown = nil
+	return l.load(config)
+}
+
+// ResetToDefaults resets the provided config struct to its zero value and then sets the fields that have a default
+// value like ApplyDefaults, e.g. before loading it again into the same struct.
+func ResetToDefaults(config interface{}, opts ...Option) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		v.Elem().SetZero()
+	}
+	return ApplyDefaults(config, opts...)
+}
+
+// exampleTags turns the tags of a field into those used by ExampleConfig, taking the example value as default value.
+func exampleTags(tags map[string]string) {
+	if example, hasExample := tags["example"]; hasExample {
+		tags["default"] = example
+	}
+	delete(tags, "secretref")
+	delete(tags, "file")
+	tags["optional"] = ""
+}
+
+// defaultTags turns the tags of a field into those used by ApplyDefaults, which only sets default values.
+func defaultTags(tags map[string]string) {
+	delete(tags, "secretref")
+	delete(tags, "file")
+	tags["optional"] = ""
+}
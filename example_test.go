@@ -0,0 +1,82 @@
+package goloadenv
+
+import (
+	"testing"
+)
+
+type ExampleDBConfig struct {
+	Host     string `env:"DB_HOST;example:db.internal"`
+	Port     int    `env:"DB_PORT;default:5432;example:6432"`
+	Password string `env:"DB_PASSWORD;secret;secretref:vault://secret/data/db#password;example:hunter2"`
+	Timeout  string `env:"DB_TIMEOUT;default:5s"`
+	Replicas int    `env:"DB_REPLICAS"`
+}
+
+func TestExampleConfig(t *testing.T) {
+	clearTestEnv()
+
+	cfg := ExampleDBConfig{}
+	err := ExampleConfig(&cfg, WithSources(MapSource{"DB_HOST": "ignored"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := ExampleDBConfig{Host: "db.internal", Port: 6432, Password: "hunter2", Timeout: "5s"}
+	if cfg != expected {
+		t.Errorf("Expected %v, got %v", expected, cfg)
+	}
+
+	invalid := struct {
+		Port int `env:"PORT;max:1000;example:8080"`
+	}{}
+	err = ExampleConfig(&invalid)
+	expectedErr := "invalid value '8080' for environment variable PORT: must be at most 1000"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+}
+
+type defaultsConfig struct {
+	Host    string `env:"HOST"`
+	Port    int    `env:"PORT;default:8080"`
+	Addr    string `env:"ADDR;default:tmpl:{{.Host}}:{{.Port}}"`
+	Token   string `env:"TOKEN;secret;secretref:vault://secret/data/app#token"`
+	invalid bool
+}
+
+func (c *defaultsConfig) Validate() error {
+	c.invalid = c.Host == ""
+	return nil
+}
+
+func TestApplyDefaults(t *testing.T) {
+	clearTestEnv()
+
+	cfg := defaultsConfig{Host: "localhost", Port: 1}
+	err := ApplyDefaults(&cfg, WithSources(MapSource{"PORT": "9090"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := defaultsConfig{Host: "localhost", Port: 8080, Addr: "localhost:8080"}
+	if cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+
+	cfg = defaultsConfig{Host: "localhost", Token: "token"}
+	err = ResetToDefaults(&cfg)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected = defaultsConfig{Port: 8080, Addr: ":8080"}
+	if cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+
+	invalid := struct {
+		Port int `env:"PORT;max:1000;default:8080"`
+	}{}
+	err = ResetToDefaults(&invalid)
+	expectedErr := "invalid value '8080' for environment variable PORT: must be at most 1000"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+}
@@ -0,0 +1,122 @@
+package goloadenv
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// expandFuncs are the functions that can be applied to a reference as ${VAR|func} or ${VAR|func:arg}, in order from
+// left to right, e.g. ${HOST|trim|default:localhost}.
+var expandFuncs = map[string]func(value string, arg string) (string, error){
+	"default": func(value string, arg string) (string, error) {
+		if value == "" {
+			return arg, nil
+		}
+		return value, nil
+	},
+	"trim": func(value string, _ string) (string, error) {
+		return strings.TrimSpace(value), nil
+	},
+	"upper": func(value string, _ string) (string, error) {
+		return strings.ToUpper(value), nil
+	},
+	"lower": func(value string, _ string) (string, error) {
+		return strings.ToLower(value), nil
+	},
+	"b64dec": func(value string, _ string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+	},
+	// join joins the elements of a list, written as [a,b] or a,b, with the separator, a comma by default
+	"join": func(value string, arg string) (string, error) {
+		if value == "" {
+			return "", nil
+		}
+		var elements []string
+		var err error
+		if strings.HasPrefix(value, "[") {
+			elements, err = ParseList(value)
+		} else {
+			elements, err = SplitList(value, ",")
+		}
+		if err != nil {
+			return "", err
+		}
+		if arg == "" {
+			arg = ","
+		}
+		return strings.Join(elements, arg), nil
+	},
+}
+
+// expandVars replaces the $VAR and ${VAR} references in a string with the values of the variables found by the lookup,
+// falling back to the pseudo variables. References to unset variables are replaced by the empty string and $$ is
+// replaced by a literal $. The functions of expandFuncs can be applied to braced references, e.g. ${TOKEN|b64dec}.
+func expandVars(str string, lookup func(string) (string, bool)) (string, error) {
+	var expandErr error
+	expanded := os.Expand(str, func(reference string) string {
+		if reference == "$" {
+			return "$"
+		}
+		name, pipeline, _ := strings.Cut(reference, "|")
+		value, err := resolveVar(strings.TrimSpace(name), lookup)
+		if err == nil && pipeline != "" {
+			value, err = applyExpandFuncs(value, pipeline)
+			if err != nil {
+				err = fmt.Errorf("error expanding ${%s}: %w", reference, err)
+			}
+		}
+		if err != nil && expandErr == nil {
+			expandErr = err
+		}
+		return value
+	})
+	return expanded, expandErr
+}
+
+// hasVarReference reports whether a string holds a $VAR or ${VAR} reference that expandVars would replace, unlike a
+// literal $$.
+func hasVarReference(str string) bool {
+	found := false
+	os.Expand(str, func(reference string) string {
+		if reference != "$" {
+			found = true
+		}
+		return ""
+	})
+	return found
+}
+
+// resolveVar returns the value of a referenced variable, or of the pseudo variable of that name when it is not set.
+func resolveVar(name string, lookup func(string) (string, bool)) (string, error) {
+	if value, found := lookup(name); found {
+		return value, nil
+	}
+	if resolve, found := pseudoVars[name]; found {
+		return resolve()
+	}
+	return "", nil
+}
+
+// applyExpandFuncs applies the |-separated functions of a reference to its value.
+func applyExpandFuncs(value string, pipeline string) (string, error) {
+	for _, call := range strings.Split(pipeline, "|") {
+		name, arg, _ := strings.Cut(call, ":")
+		name = strings.TrimSpace(name)
+		fn, found := expandFuncs[name]
+		if !found {
+			return "", fmt.Errorf("unknown function '%s'", name)
+		}
+		var err error
+		value, err = fn(value, arg)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return value, nil
+}
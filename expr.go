@@ -0,0 +1,217 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// exprPrefix marks a default value that is computed from an arithmetic expression, e.g. default:expr:4*1024*1024.
+const exprPrefix = "expr:"
+
+// exprFuncs are the functions that can be called from default expressions.
+var exprFuncs = map[string]func(args []float64) (float64, error){
+	"runtime.NumCPU": func(args []float64) (float64, error) {
+		if len(args) != 0 {
+			return 0, errors.New("runtime.NumCPU takes no arguments")
+		}
+		return float64(runtime.NumCPU()), nil
+	},
+	"runtime.GOMAXPROCS": func(args []float64) (float64, error) {
+		if len(args) != 0 {
+			return 0, errors.New("runtime.GOMAXPROCS takes no arguments")
+		}
+		return float64(runtime.GOMAXPROCS(0)), nil
+	},
+	"min": func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, errors.New("min takes at least one argument")
+		}
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Min(result, arg)
+		}
+		return result, nil
+	},
+	"max": func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, errors.New("max takes at least one argument")
+		}
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Max(result, arg)
+		}
+		return result, nil
+	},
+}
+
+// evalExpr evaluates an arithmetic expression of numbers, the + - * / % operators, parentheses and calls to the
+// functions in exprFuncs, and formats the result so it can be parsed into a numeric field.
+func evalExpr(expr string) (string, error) {
+	p := &exprParser{input: expr}
+	value, err := p.parseSum()
+	if err != nil {
+		return "", fmt.Errorf("invalid expression '%s': %w", expr, err)
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return "", fmt.Errorf("invalid expression '%s': unexpected '%c' at position %d", expr, p.input[p.pos], p.pos)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return "", fmt.Errorf("invalid expression '%s': result is not a finite number", expr)
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), nil
+}
+
+// exprParser is a recursive descent parser evaluating an expression while it is parsed.
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// peek skips spaces and returns the next byte, or 0 at the end of the input.
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *exprParser) parseSum() (float64, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseProduct() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for op := p.peek(); op == '*' || op == '/' || op == '%'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			left *= right
+		case '/':
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			left /= right
+		case '%':
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			left = math.Mod(left, right)
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	if p.peek() == '-' {
+		p.pos++
+		value, err := p.parseUnary()
+		return -value, err
+	}
+	return p.parseOperand()
+}
+
+func (p *exprParser) parseOperand() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		value, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return value, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		return strconv.ParseFloat(p.input[start:p.pos], 64)
+	case unicode.IsLetter(rune(c)):
+		return p.parseCall()
+	case c == 0:
+		return 0, errors.New("unexpected end of expression")
+	}
+	return 0, fmt.Errorf("unexpected '%c' at position %d", c, p.pos)
+}
+
+func (p *exprParser) parseCall() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	fn, found := exprFuncs[name]
+	if !found {
+		return 0, fmt.Errorf("unknown function %s", name)
+	}
+	if p.peek() != '(' {
+		return 0, fmt.Errorf("expected '(' after %s", name)
+	}
+	p.pos++
+	var args []float64
+	if p.peek() == ')' {
+		p.pos++
+		return fn(args)
+	}
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, arg)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return fn(args)
+		default:
+			return 0, fmt.Errorf("expected ',' or ')' in call to %s", name)
+		}
+	}
+}
+
+// isExprDefault reports whether a default value is an expression.
+func isExprDefault(defaultValue string) bool {
+	return strings.HasPrefix(defaultValue, exprPrefix)
+}
@@ -0,0 +1,175 @@
+package goloadenv
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestEvalExpr(t *testing.T) {
+	tests := map[string]string{
+		"4*1024*1024":                  "4194304",
+		"(1 + 2) * -3":                 "-9",
+		"10 % 4 + 7 / 2":               "5.5",
+		"max(2, runtime.NumCPU() * 2)": strconv.Itoa(max(2, runtime.NumCPU()*2)),
+	}
+	for expr, expected := range tests {
+		got, err := evalExpr(expr)
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", expr, err)
+		}
+		if got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, expr, got)
+		}
+	}
+}
+
+func TestEvalExprError(t *testing.T) {
+	tests := map[string]string{
+		"1/0":         "invalid expression '1/0': division by zero",
+		"os.Exit(1)":  "invalid expression 'os.Exit(1)': unknown function os.Exit",
+		"(1 + 2":      "invalid expression '(1 + 2': missing closing parenthesis",
+		"2 * 3 extra": "invalid expression '2 * 3 extra': unexpected 'e' at position 6",
+	}
+	for expr, expected := range tests {
+		_, err := evalExpr(expr)
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	}
+}
+
+func TestExprDefault(t *testing.T) {
+	clearTestEnv()
+
+	someStruct := struct {
+		Buffer  int `env:"BUFFER;default:expr:4*1024*1024"`
+		Workers int `env:"WORKERS;default:expr:runtime.NumCPU()*2"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Buffer != 4*1024*1024 {
+		t.Errorf("Expected BUFFER=4194304, got %d", someStruct.Buffer)
+	}
+	if someStruct.Workers != runtime.NumCPU()*2 {
+		t.Errorf("Expected WORKERS=%d, got %d", runtime.NumCPU()*2, someStruct.Workers)
+	}
+}
+
+func TestPseudoVarDefaults(t *testing.T) {
+	clearTestEnv()
+
+	someStruct := struct {
+		Workers int    `env:"WORKERS;default:expr:$NUMCPU*2"`
+		Name    string `env:"NAME;default:worker-${PID}"`
+		Literal string `env:"LITERAL;default:$UNKNOWN"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Workers != runtime.NumCPU()*2 {
+		t.Errorf("Expected WORKERS=%d, got %d", runtime.NumCPU()*2, someStruct.Workers)
+	}
+	if expected := "worker-" + strconv.Itoa(os.Getpid()); someStruct.Name != expected {
+		t.Errorf("Expected NAME=%s, got %s", expected, someStruct.Name)
+	}
+	if someStruct.Literal != "$UNKNOWN" {
+		t.Errorf("Expected LITERAL=$UNKNOWN, got %s", someStruct.Literal)
+	}
+
+	pid := strconv.Itoa(os.Getpid())
+	tests := map[string]string{
+		"/run/$PID_FILE":    "/run/$PID_FILE",
+		"/run/${PID}_FILE":  "/run/" + pid + "_FILE",
+		"a$$PID":            "a$PID",
+		"${PID":             "${PID",
+		"$PID/$PIDS/${PID}": pid + "/$PIDS/" + pid,
+	}
+	for value, expected := range tests {
+		got, err := expandPseudoVars(value)
+		if err != nil || got != expected {
+			t.Errorf("Expected %s to expand to %s, got %s, %v", value, expected, got, err)
+		}
+	}
+	if hasPseudoVarReference("a$$PID") || hasPseudoVarReference("/run/$PID_FILE") || !hasPseudoVarReference("${PID}") {
+		t.Errorf("Expected only complete pseudo variable references to be reported")
+	}
+}
+
+func TestExpandVars(t *testing.T) {
+	clearTestEnv()
+
+	env := map[string]string{
+		"HOME": "/home/app",
+		"HOST": "localhost",
+		"PORT": "8080",
+		"URL":  "http://${HOST}:$PORT/",
+		"COST": "$$5",
+	}
+	for key, value := range env {
+		err := os.Setenv(key, value)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	someStruct := struct {
+		DataDir string `env:"DATA_DIR;expand;default:${HOME}/data"`
+		URL     string `env:"URL;expand"`
+		Cost    string `env:"COST;expand"`
+		Raw     string `env:"RAW_URL;expand;default:${MISSING}/x"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.DataDir != "/home/app/data" {
+		t.Errorf("Expected DATA_DIR=/home/app/data, got %s", someStruct.DataDir)
+	}
+	if someStruct.URL != "http://localhost:8080/" {
+		t.Errorf("Expected URL=http://localhost:8080/, got %s", someStruct.URL)
+	}
+	if someStruct.Cost != "$5" {
+		t.Errorf("Expected COST=$5, got %s", someStruct.Cost)
+	}
+	if someStruct.Raw != "/x" {
+		t.Errorf("Expected RAW_URL=/x, got %s", someStruct.Raw)
+	}
+}
+
+func TestExpandFuncs(t *testing.T) {
+	someStruct := struct {
+		Region string `env:"REGION;expand;default:${AWS_REGION|trim|default:eu-west-1|upper}"`
+		Token  string `env:"TOKEN;expand"`
+		Hosts  string `env:"HOSTS_FLAT;expand;default:${HOSTS|join: }"`
+		Lower  string `env:"LOWER;expand;default:${NAME | lower}"`
+	}{}
+	env := MapSource{"AWS_REGION": "  ", "TOKEN": "Bearer ${TOKEN_B64|b64dec}", "TOKEN_B64": "aHVudGVyMg==", "HOSTS": "[a,b,c]", "NAME": "App"}
+	err := LoadEnvWithOptions(&someStruct, WithSources(env))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Region != "EU-WEST-1" || someStruct.Token != "Bearer hunter2" || someStruct.Hosts != "a b c" || someStruct.Lower != "app" {
+		t.Errorf("Expected REGION=EU-WEST-1, TOKEN=Bearer hunter2, HOSTS_FLAT=a b c and LOWER=app, got %v", someStruct)
+	}
+
+	env["TOKEN_B64"] = "%%%"
+	err = LoadEnvWithOptions(&someStruct, WithSources(env))
+	expected := "error parsing 'Bearer ${TOKEN_B64|b64dec}' as environment variable TOKEN: error expanding ${TOKEN_B64|b64dec}: b64dec: illegal base64 data at input byte 0"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(MapSource{"TOKEN": "${TOKEN_B64|rot13}"}))
+	expected = "error parsing '${TOKEN_B64|rot13}' as environment variable TOKEN: error expanding ${TOKEN_B64|rot13}: unknown function 'rot13'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
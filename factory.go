@@ -0,0 +1,75 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// factories holds the factories registered with RegisterFactory by interface type and name, guarded by typesMu.
+var factories = map[reflect.Type]map[string]func() interface{}{}
+
+// RegisterFactory registers a factory constructing an implementation of the interface I under the given name, so
+// fields of type I are populated with the implementation named by their variable, e.g. STORAGE_BACKEND=s3 for
+// env:"STORAGE_BACKEND". When the implementation is a pointer to a struct, its fields are loaded as a nested config
+// struct, prefixed by the envPrefix struct tag of the field. Registering a name again replaces its factory. It panics
+// when I is not an interface type.
+//
+// Example:
+//
+//	goloadenv.RegisterFactory[Backend]("disk", func() Backend { return &DiskBackend{} })
+//	goloadenv.RegisterFactory[Backend]("s3", func() Backend { return &S3Backend{} })
+//	type Config struct {
+//	  Storage Backend `env:"STORAGE_BACKEND" envPrefix:"STORAGE_"`
+//	}
+func RegisterFactory[I any](name string, factory func() I) {
+	typ := reflect.TypeFor[I]()
+	if typ.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("goloadenv: RegisterFactory requires an interface type, got %s", typ))
+	}
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	if factories[typ] == nil {
+		factories[typ] = map[string]func() interface{}{}
+	}
+	factories[typ][name] = func() interface{} { return factory() }
+	envTypes[typ] = factorySetter(typ)
+}
+
+// factorySetter returns the parser of an interface type, constructing the implementation named by the value with its
+// registered factory.
+func factorySetter(typ reflect.Type) envSetter {
+	return func(field reflect.Value, str string) error {
+		typesMu.RLock()
+		factory, found := factories[typ][str]
+		names := make([]string, 0, len(factories[typ]))
+		for name := range factories[typ] {
+			names = append(names, name)
+		}
+		typesMu.RUnlock()
+		if !found {
+			slices.Sort(names)
+			return fmt.Errorf("unknown implementation '%s' of %s, expected one of %s", str, typ, strings.Join(names, ", "))
+		}
+		value := reflect.ValueOf(factory())
+		if !value.IsValid() {
+			return fmt.Errorf("factory '%s' of %s returned nil", str, typ)
+		}
+		field.Set(value)
+		return nil
+	}
+}
+
+// factoryStruct returns the struct an interface field constructed by a factory points to, whose fields are loaded as
+// a nested config struct.
+func factoryStruct(field reflect.Value) (reflect.Value, bool) {
+	if field.Kind() != reflect.Interface || field.IsNil() {
+		return reflect.Value{}, false
+	}
+	impl := field.Elem()
+	if impl.Kind() != reflect.Ptr || impl.IsNil() || !isNestedStruct(impl.Type().Elem()) {
+		return reflect.Value{}, false
+	}
+	return impl.Elem(), true
+}
@@ -0,0 +1,55 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldHook transforms the raw value of a field before it is parsed, for cross-cutting processing such as trimming,
+// lowercasing, templating or looking up secrets, see WithFieldHook. It receives the field and its raw value and
+// returns the value to parse. The tags of the field must not be modified.
+type FieldHook func(field FieldInfo, raw string) (string, error)
+
+// WithFieldHook registers a hook transforming the raw value of every field that is set, from the environment or its
+// default value, after decryption and before the value is parsed and validated. It can be given multiple times, the
+// hooks are called in registration order, each with the value returned by the previous hook. An error returned by a
+// hook fails the field. Hooks can be driven by struct tags of their own, read from the StructField of the field, as
+// unknown options in the env tag fail the load in strict mode.
+//
+// Example:
+//
+//	type Config struct {
+//	  Region string `env:"REGION" case:"lower"`
+//	}
+//
+//	lowercase := goloadenv.WithFieldHook(func(f goloadenv.FieldInfo, raw string) (string, error) {
+//	  if f.StructField.Tag.Get("case") == "lower" {
+//	    return strings.ToLower(raw), nil
+//	  }
+//	  return raw, nil
+//	})
+func WithFieldHook(hook FieldHook) Option {
+	return func(l *loader) {
+		l.fieldHooks = append(l.fieldHooks, hook)
+	}
+}
+
+// runFieldHooks passes the raw value of a field through the field hooks of the loader.
+// used internally by LoadEnv.
+func (l *loader) runFieldHooks(structField reflect.StructField, tags map[string]string, path string, str string) (string, error) {
+	info := FieldInfo{
+		Path:        path,
+		Name:        tags["name"],
+		Type:        structField.Type,
+		Tags:        tags,
+		StructField: structField,
+	}
+	for _, hook := range l.fieldHooks {
+		var err error
+		str, err = hook(info, str)
+		if err != nil {
+			return "", fmt.Errorf("field hook failed for environment variable %s: %w", tags["name"], err)
+		}
+	}
+	return str, nil
+}
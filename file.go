@@ -0,0 +1,54 @@
+package goloadenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// fileSuffix is appended to the name of a variable to get the variable holding the path of the file to read its value
+// from, e.g. DB_PASSWORD_FILE for DB_PASSWORD.
+const fileSuffix = "_FILE"
+
+// EnvFileError is returned when the file named by the *_FILE variable of a field cannot be read.
+type EnvFileError struct {
+	// Env is the name of the environment variable of the field.
+	Env string
+	// Path is the path of the file.
+	Path string
+	// Err is the error reading the file.
+	Err error
+}
+
+func (e *EnvFileError) Error() string {
+	return fmt.Sprintf("error reading file %s for environment variable %s: %s", e.Path, e.Env, e.Err)
+}
+
+func (e *EnvFileError) Unwrap() error {
+	return e.Err
+}
+
+// fileLookup returns a lookup that reads the value of a variable from the file named by its *_FILE variable when the
+// variable is not found by the given lookup, reporting whether the file was used. Trailing newlines of the file are
+// removed, as most editors and tools such as echo add one. The file is read eagerly so errors surface here.
+// used internally by LoadEnv.
+func fileLookup(lookup func(string) (string, bool), name string) (func(string) (string, bool), bool, error) {
+	if _, found := lookup(name); found {
+		return lookup, false, nil
+	}
+	path, found := lookup(name + fileSuffix)
+	if !found || path == "" {
+		return lookup, false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, &EnvFileError{Env: name, Path: path, Err: err}
+	}
+	value := strings.TrimRight(string(content), "\r\n")
+	return func(key string) (string, bool) {
+		if key == name {
+			return value, true
+		}
+		return lookup(key)
+	}, true, nil
+}
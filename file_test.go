@@ -0,0 +1,67 @@
+package goloadenv
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileVariable(t *testing.T) {
+	clearTestEnv()
+
+	path := filepath.Join(t.TempDir(), "db_password")
+	err := os.WriteFile(path, []byte("hunter2\n"), 0o600)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("DB_PASSWORD_FILE", path)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("API_KEY", "from-env")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("API_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Password string `env:"DB_PASSWORD;secret;file"`
+		APIKey   string `env:"API_KEY;file"`
+		Token    string `env:"TOKEN;file;default:none"`
+	}{}
+	report, err := LoadEnvReport(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Password != "hunter2" || someStruct.APIKey != "from-env" || someStruct.Token != "none" {
+		t.Errorf("Expected DB_PASSWORD=hunter2, API_KEY=from-env and TOKEN=none, got %v", someStruct)
+	}
+	if report.Fields[0].Origin != OriginFile || report.Fields[1].Origin != OriginEnv {
+		t.Errorf("Expected origins file and env, got %s and %s", report.Fields[0].Origin, report.Fields[1].Origin)
+	}
+
+	otherStruct := struct {
+		Password string `env:"DB_PASSWORD"`
+	}{}
+	err = LoadEnv(&otherStruct)
+	var notFoundErr *EnvNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Errorf("Expected EnvNotFoundError without the file flag, got %v", err)
+	}
+	err = LoadEnvWithOptions(&otherStruct, WithFileFallback())
+	if err != nil || otherStruct.Password != "hunter2" {
+		t.Errorf("Expected DB_PASSWORD=hunter2, got %v, %v", otherStruct.Password, err)
+	}
+
+	os.Unsetenv("API_KEY")
+	err = LoadEnv(&someStruct)
+	var fileErr *EnvFileError
+	if !errors.As(err, &fileErr) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected EnvFileError for a missing file, got %v", err)
+	}
+}
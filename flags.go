@@ -0,0 +1,93 @@
+package goloadenv
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const flagTagName = "flag"
+
+// flagValue is the flag.Value of a field bound to a command-line flag, holding the raw string to be parsed like the
+// value of its environment variable.
+type flagValue struct {
+	value  string
+	isBool bool
+	// env is the environment variable of the field the flag is bound to.
+	env string
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value, e.g. -verbose for -verbose=true.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// flagName returns the command-line flag of a field, the flag tag or else the name of its environment variable in
+// lower case with dashes, e.g. db-host for DB_HOST. It returns an empty string for fields tagged flag:"-".
+func flagName(f FieldInfo) string {
+	name, hasTag := f.StructField.Tag.Lookup(flagTagName)
+	if !hasTag {
+		return strings.ToLower(strings.ReplaceAll(f.Name, "_", "-"))
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// parseFlags defines a flag on the flag set for every tagged field of the config struct and parses the arguments,
+// keeping the values of the flags that were given by the name of their environment variable.
+func (l *loader) parseFlags(config reflect.Value) error {
+	flags := l.flagSet
+	if flags == nil {
+		flags = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	}
+	// envs maps the flags to the environment variables of their fields
+	envs := map[string]string{}
+	err := iterateStruct(config, "", "", l.tagName, func(f FieldInfo, _ reflect.Value) error {
+		name := flagName(f)
+		if f.Name == "" || name == "" {
+			return nil
+		}
+		env := l.prefix + f.Name
+		envs[name] = env
+		// a flag set given to a reloaded config already holds the flags defined by the previous load
+		if defined := flags.Lookup(name); defined != nil {
+			if value, isField := defined.Value.(*flagValue); isField && value.env == env {
+				return nil
+			}
+			return fmt.Errorf("flag -%s of environment variable %s is already defined", name, env)
+		}
+		value := &flagValue{value: f.Tags["default"], isBool: f.Type.Kind() == reflect.Bool, env: env}
+		flags.Var(value, name, f.StructField.Tag.Get(descTagName))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = flags.Parse(l.flagArgs)
+	if err != nil {
+		return err
+	}
+	l.flagValues = map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		if env, isField := envs[f.Name]; isField {
+			l.flagValues[env] = f.Value.String()
+		}
+	})
+	return nil
+}
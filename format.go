@@ -0,0 +1,270 @@
+package goloadenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isDocumentFormat reports whether the format tag option names a structured document format, which is decoded by
+// decodeFormat for any type, rather than a format passed to the parser of the type.
+func isDocumentFormat(format string) bool {
+	return format == "json" || format == "yaml"
+}
+
+// decodeFormat decodes a structured document into the field according to the format tag option, "json" or "yaml".
+// The field is only replaced when the whole document decodes.
+func decodeFormat(field reflect.Value, str string, format string) error {
+	if !field.CanSet() {
+		return fmt.Errorf("field cannot be set")
+	}
+	document := []byte(str)
+	switch format {
+	case "json":
+	case "yaml":
+		value, err := parseYAML(str)
+		if err != nil {
+			return err
+		}
+		document, err = json.Marshal(value)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format '%s', expected json or yaml", format)
+	}
+	decoded := reflect.New(field.Type())
+	err := json.Unmarshal(document, decoded.Interface())
+	if err != nil {
+		return err
+	}
+	field.Set(decoded.Elem())
+	return nil
+}
+
+// yamlLine is a non-empty line of a YAML document with its comment stripped.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML parses the subset of YAML used for configuration values into JSON compatible values: block mappings and
+// sequences, plain and quoted scalars, flow collections written as JSON and # comments. Anchors, tags, multi-line
+// scalars and multiple documents are not supported.
+func parseYAML(str string) (interface{}, error) {
+	var lines []yamlLine
+	for i, text := range strings.Split(strings.ReplaceAll(str, "\r\n", "\n"), "\n") {
+		text = stripYAMLComment(text)
+		trimmed := strings.TrimLeft(text, " ")
+		if strings.TrimSpace(trimmed) == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(text) - len(trimmed), text: strings.TrimRight(trimmed, " \t")})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("yaml line %d: unexpected indentation", lines[next].number)
+	}
+	return value, nil
+}
+
+// parseYAMLBlock parses the block starting at lines[i] with the given indentation, returning its value and the index
+// of the first line after the block.
+func parseYAMLBlock(lines []yamlLine, i int, indent int) (interface{}, int, error) {
+	if isYAMLSequenceItem(lines[i].text) {
+		return parseYAMLSequence(lines, i, indent)
+	}
+	if _, _, isEntry := cutYAMLKey(lines[i].text); isEntry {
+		return parseYAMLMapping(lines, i, indent)
+	}
+	if i+1 < len(lines) && lines[i+1].indent >= indent {
+		return nil, 0, fmt.Errorf("yaml line %d: multi-line scalars are not supported", lines[i+1].number)
+	}
+	value, err := parseYAMLScalar(lines[i].text)
+	if err != nil {
+		return nil, 0, fmt.Errorf("yaml line %d: %w", lines[i].number, err)
+	}
+	return value, i + 1, nil
+}
+
+func parseYAMLSequence(lines []yamlLine, i int, indent int) (interface{}, int, error) {
+	items := []interface{}{}
+	for i < len(lines) && lines[i].indent == indent && isYAMLSequenceItem(lines[i].text) {
+		rest := strings.TrimLeft(strings.TrimPrefix(lines[i].text, "-"), " ")
+		if rest == "" {
+			value, next, err := parseYAMLNested(lines, i, indent)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, value)
+			i = next
+			continue
+		}
+		// the item starts on the same line, parse it as a block indented to where it starts
+		lines[i] = yamlLine{number: lines[i].number, indent: indent + len(lines[i].text) - len(rest), text: rest}
+		value, next, err := parseYAMLBlock(lines, i, lines[i].indent)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, value)
+		i = next
+	}
+	return items, i, nil
+}
+
+func parseYAMLMapping(lines []yamlLine, i int, indent int) (interface{}, int, error) {
+	mapping := map[string]interface{}{}
+	for i < len(lines) && lines[i].indent == indent {
+		key, rest, isEntry := cutYAMLKey(lines[i].text)
+		if !isEntry {
+			return nil, 0, fmt.Errorf("yaml line %d: expected key: value", lines[i].number)
+		}
+		if _, duplicate := mapping[key]; duplicate {
+			return nil, 0, fmt.Errorf("yaml line %d: duplicate key '%s'", lines[i].number, key)
+		}
+		if rest != "" {
+			value, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, 0, fmt.Errorf("yaml line %d: %w", lines[i].number, err)
+			}
+			mapping[key] = value
+			i++
+			continue
+		}
+		// a sequence may be indented at the same level as its key
+		if i+1 < len(lines) && lines[i+1].indent == indent && isYAMLSequenceItem(lines[i+1].text) {
+			value, next, err := parseYAMLSequence(lines, i+1, indent)
+			if err != nil {
+				return nil, 0, err
+			}
+			mapping[key] = value
+			i = next
+			continue
+		}
+		value, next, err := parseYAMLNested(lines, i, indent)
+		if err != nil {
+			return nil, 0, err
+		}
+		mapping[key] = value
+		i = next
+	}
+	if i < len(lines) && lines[i].indent > indent {
+		return nil, 0, fmt.Errorf("yaml line %d: unexpected indentation", lines[i].number)
+	}
+	return mapping, i, nil
+}
+
+// parseYAMLNested parses the block nested below lines[i], which is null when the next line is not indented deeper.
+func parseYAMLNested(lines []yamlLine, i int, indent int) (interface{}, int, error) {
+	if i+1 >= len(lines) || lines[i+1].indent <= indent {
+		return nil, i + 1, nil
+	}
+	return parseYAMLBlock(lines, i+1, lines[i+1].indent)
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// cutYAMLKey splits a mapping entry into its key and the rest of the line.
+func cutYAMLKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		end := strings.IndexByte(text[1:], '\'')
+		if text[0] == '"' {
+			end = closingQuote(text[1:])
+		}
+		if end < 0 {
+			return "", "", false
+		}
+		rest, isEntry := strings.CutPrefix(text[end+2:], ":")
+		if !isEntry || (rest != "" && rest[0] != ' ') {
+			return "", "", false
+		}
+		key, err := parseYAMLScalar(text[:end+2])
+		if err != nil {
+			return "", "", false
+		}
+		return fmt.Sprint(key), strings.TrimSpace(rest), true
+	}
+	if key, rest, found := strings.Cut(text, ": "); found {
+		return key, strings.TrimSpace(rest), true
+	}
+	if key, found := strings.CutSuffix(text, ":"); found {
+		return key, "", true
+	}
+	return "", "", false
+}
+
+// parseYAMLScalar parses a scalar or a flow collection written as JSON.
+func parseYAMLScalar(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{"):
+		var value interface{}
+		err := json.Unmarshal([]byte(text), &value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flow collection '%s': %w", text, err)
+		}
+		return value, nil
+	case strings.HasPrefix(text, `"`):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid double quoted string %s", text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("invalid single quoted string %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	switch text {
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return integer, nil
+	}
+	if float, err := strconv.ParseFloat(text, 64); err == nil {
+		return float, nil
+	}
+	return text, nil
+}
+
+// stripYAMLComment removes a # comment that starts the line or follows whitespace outside of quotes.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == '\\' && quote == '"' {
+				i++
+			} else if text[i] == quote {
+				quote = 0
+			}
+		case text[i] == '"' || text[i] == '\'':
+			quote = text[i]
+		case text[i] == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
@@ -0,0 +1,79 @@
+package goloadenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func FuzzParseTag(f *testing.F) {
+	for _, seed := range []string{"PORT", "PORT;default:8080;optional", "WORKERS;default:expr:runtime.NumCPU()*2", "A;default", "A;min:1;min:2", ";;:", "URL;default:https://example.com:8080/path", `A;default:'a;b'`, `A;default:a\;b`, "name=PORT,default=8080,optional", "name=A,default='a,b'", "name=A,=b"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, tag string) {
+		tags, err := ParseTag(tag)
+		if err != nil {
+			return
+		}
+		for option := range tags {
+			if option == "" || strings.Contains(option, ":") {
+				t.Errorf("Expected a valid option name, got '%s'", option)
+			}
+		}
+	})
+}
+
+func FuzzParseList(f *testing.F) {
+	for _, seed := range []string{"[a,b,c]", "[]", "[", "]", "[,]", "a,b", `["a,b", 'c']`, `[a\,b,[c,d]]`, `["unterminated]`} {
+		f.Add(seed)
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	f.Fuzz(func(t *testing.T, str string) {
+		values, err := ParseList(str)
+		if err != nil {
+			return
+		}
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = `"` + escaper.Replace(value) + `"`
+		}
+		reparsed, err := ParseList("[" + strings.Join(quoted, ",") + "]")
+		if err != nil || !reflect.DeepEqual(values, reparsed) {
+			t.Errorf("Expected %q, got %q (%v)", values, reparsed, err)
+		}
+	})
+}
+
+func FuzzParseMap(f *testing.F) {
+	for _, seed := range []string{"a=1,b=2", "a=", "=1", "a=1=2", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, str string) {
+		m, err := ParseMap(str)
+		if err != nil {
+			return
+		}
+		for key := range m {
+			if key == "" || strings.ContainsAny(key, ",=") {
+				t.Errorf("Expected a valid key, got '%s'", key)
+			}
+		}
+	})
+}
+
+func FuzzParseDotEnv(f *testing.F) {
+	for _, seed := range []string{"A=1\nB=2", "export A='x'", "A=\"multi\nline\"", "A=\"unterminated", "# comment\n\nA=1 # c", "A=\"\\\""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, content string) {
+		env, err := ParseDotEnv(content)
+		if err != nil {
+			return
+		}
+		for key := range env {
+			if key == "" || strings.ContainsAny(key, " \t\n=") {
+				t.Errorf("Expected a valid key, got '%s'", key)
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Get looks up a single environment variable and parses it into a T like a field of a config struct, for programs that
+// do not need a full config struct. The tag is the value of an env struct tag, so it can carry the same options, e.g.
+// Get[int]("PORT;default:8080;min:1") or Get[[]string]("HOSTS;sep:,"). The options customize the lookup like they do
+// for LoadEnvWithOptions.
+func Get[T any](tag string, opts ...Option) (T, error) {
+	value, _, err := getValue[T](tag, opts)
+	return value, err
+}
+
+// GetOr looks up a single environment variable like Get, but returns the fallback when the variable is not set.
+//
+// Example:
+//
+//	port, err := goloadenv.GetOr("PORT", 8080)
+func GetOr[T any](tag string, fallback T, opts ...Option) (T, error) {
+	value, origin, err := getValue[T](tag+";optional", opts)
+	if err != nil || origin != OriginUnset {
+		return value, err
+	}
+	return fallback, nil
+}
+
+// MustGet looks up a single environment variable like Get and panics if it is missing or cannot be parsed.
+func MustGet[T any](tag string, opts ...Option) T {
+	value, err := Get[T](tag, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// getValue loads a single value by wrapping it in a struct with one field tagged with the given tag, returning the
+// value and where it came from.
+func getValue[T any](tag string, opts []Option) (T, Origin, error) {
+	var zero T
+	l := newLoader(opts...)
+	l.report = &Report{}
+	structType := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeFor[T](),
+		Tag:  reflect.StructTag(fmt.Sprintf("%s:%q", l.tagName, tag)),
+	}})
+	config := reflect.New(structType)
+	err := l.load(config.Interface())
+	if err != nil {
+		return zero, OriginUnset, err
+	}
+	origin := OriginUnset
+	if len(l.report.Fields) > 0 {
+		origin = l.report.Fields[0].Origin
+	}
+	return config.Elem().Field(0).Interface().(T), origin, nil
+}
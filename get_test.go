@@ -0,0 +1,49 @@
+package goloadenv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	env := WithSources(MapSource{"PORT": "9090", "HOSTS": "a,b", "TIMEOUT": "5s", "INVALID": "abc"})
+
+	port, err := Get[int]("PORT", env)
+	if err != nil || port != 9090 {
+		t.Errorf("Expected 9090, got %v, %v", port, err)
+	}
+	hosts, err := Get[[]string]("HOSTS;sep:,", env)
+	if err != nil || !reflect.DeepEqual(hosts, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v, %v", hosts, err)
+	}
+	timeout := MustGet[time.Duration]("TIMEOUT", env)
+	if timeout != 5*time.Second {
+		t.Errorf("Expected 5s, got %v", timeout)
+	}
+	workers, err := Get[int]("WORKERS;default:4", env)
+	if err != nil || workers != 4 {
+		t.Errorf("Expected 4, got %v, %v", workers, err)
+	}
+
+	_, err = Get[int]("MISSING", env)
+	var notFoundErr *EnvNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Errorf("Expected EnvNotFoundError, got %v", err)
+	}
+	_, err = Get[int]("INVALID", env)
+	var parseErr *EnvParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("Expected EnvParseError, got %v", err)
+	}
+
+	fallback, err := GetOr("MISSING", 8080, env)
+	if err != nil || fallback != 8080 {
+		t.Errorf("Expected 8080, got %v, %v", fallback, err)
+	}
+	port, err = GetOr("PORT", 8080, env)
+	if err != nil || port != 9090 {
+		t.Errorf("Expected 9090, got %v, %v", port, err)
+	}
+}
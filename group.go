@@ -0,0 +1,78 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// LoadEnvGroup loads only the fields with the group tag option set to the given group, e.g.
+// env:"DB_HOST;group:database", into the provided config struct like LoadEnvWithOptions, leaving every other field
+// untouched. Large configs with expensive remote lookups can load the part they need first, or refresh a single part
+// of a loaded config. It is shorthand for LoadEnvWithOptions with WithGroups.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvGroup(&cfg, "database")
+func LoadEnvGroup(config interface{}, group string, opts ...Option) error {
+	return LoadEnvWithOptions(config, append(append([]Option{}, opts...), WithGroups(group))...)
+}
+
+// GroupError is returned when a load restricted to groups matched no field, which usually means a group name is
+// misspelled.
+type GroupError struct {
+	Groups []string
+}
+
+func (e *GroupError) Error() string {
+	return fmt.Sprintf("no fields in group %s", strings.Join(e.Groups, ", "))
+}
+
+// inGroups reports whether a field is loaded by a load restricted to groups, which loads every field when it is not
+// restricted.
+func (l *loader) inGroups(tags map[string]string) bool {
+	if len(l.groups) == 0 {
+		return true
+	}
+	group, hasGroup := tags["group"]
+	return hasGroup && slices.Contains(l.groups, group)
+}
+
+// ReloadGroup reloads only the fields in the given group like Reload, keeping the other fields of the current config,
+// so a single part of a large config can be refreshed without looking up every other field again.
+func (w *Watcher[T]) ReloadGroup(group string) (Changes, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	config := cloneConfig(w.current.Load())
+	report, err := LoadEnvReport(config, append(append([]Option{}, w.opts...), WithGroups(group))...)
+	if err != nil {
+		w.record(report, nil, err)
+		return nil, err
+	}
+	return w.swap(config, report), nil
+}
+
+// cloneConfig copies a config struct to load into, also copying the structs embedded by pointer, which the loader
+// fills in place, so the copy can be loaded without modifying the original.
+func cloneConfig[T any](config *T) *T {
+	clone := new(T)
+	cloneStruct(reflect.ValueOf(clone).Elem(), reflect.ValueOf(config).Elem())
+	return clone
+}
+
+func cloneStruct(dst reflect.Value, src reflect.Value) {
+	dst.Set(src)
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		switch {
+		case !field.CanSet():
+		case isNestedStruct(field.Type()):
+			cloneStruct(field, src.Field(i))
+		case dst.Type().Field(i).Anonymous && field.Kind() == reflect.Ptr && !field.IsNil() && isNestedStruct(field.Type().Elem()):
+			ptr := reflect.New(field.Type().Elem())
+			cloneStruct(ptr.Elem(), src.Field(i).Elem())
+			field.Set(ptr)
+		}
+	}
+}
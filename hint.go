@@ -0,0 +1,55 @@
+package goloadenv
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// coercionHint inspects a value that failed to parse into the given type and returns a suggestion for the most
+// common mismatches between the shape of the value and the type of the field, or an empty string if no hint applies.
+// used internally by setField.
+func coercionHint(str string, typ reflect.Type) string {
+	kind := typ.Kind()
+	trimmed := strings.TrimSpace(str)
+	switch {
+	case isNumericKind(kind) && looksLikeDuration(trimmed):
+		return "value looks like a duration; change the field to time.Duration or add the duration unmarshaller"
+	case looksLikeJSON(trimmed) && (kind == reflect.Map || kind == reflect.Struct || kind == reflect.Slice):
+		return "value looks like a JSON document; add the format:json tag option to decode it"
+	case looksLikeJSON(trimmed) && kind != reflect.String:
+		return "value looks like a JSON document; change the field to a string or register an unmarshaller for " + typ.String()
+	case isScalarKind(kind) && strings.Contains(trimmed, ","):
+		return "value looks like a comma separated list; change the field to a slice and wrap the value in brackets, e.g. [a,b]"
+	case kind == reflect.Bool:
+		return "boolean values must be written as true or false, yes or no, on or off, 1 or 0, or enabled or disabled"
+	}
+	return ""
+}
+
+func looksLikeDuration(str string) bool {
+	if _, err := strconv.ParseFloat(str, 64); err == nil {
+		return false
+	}
+	_, err := time.ParseDuration(str)
+	return err == nil
+}
+
+func looksLikeJSON(str string) bool {
+	return len(str) >= 2 && (str[0] == '{' && str[len(str)-1] == '}')
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isScalarKind(kind reflect.Kind) bool {
+	return isNumericKind(kind) || kind == reflect.Bool || kind == reflect.String
+}
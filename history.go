@@ -0,0 +1,72 @@
+package goloadenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// historySize is the number of loads kept in the history of a Watcher.
+const historySize = 32
+
+// LoadRecord describes a load of a Watcher, for operators to see when and how the configuration of a long-running
+// instance last changed.
+type LoadRecord struct {
+	// Time is when the load finished.
+	Time time.Time `json:"time"`
+	// Fingerprint is the fingerprint of the loaded configuration, see WriteReportFile. It is empty when the load failed.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// SecretFingerprint is the fingerprint of the secrets of the current configuration after the load, see
+	// SecretFingerprint, keyed by a random key of the watcher, so a rotated secret shows in the history. It is empty
+	// when the load failed.
+	SecretFingerprint string `json:"secretFingerprint,omitempty"`
+	// Changed holds the dotted paths of the fields changed by the load. It is empty for the initial load.
+	Changed []string `json:"changed,omitempty"`
+	// Warnings is the number of warnings raised by the load.
+	Warnings int `json:"warnings"`
+	// Error is the error of a failed load, in which the values of secret fields are masked.
+	Error string `json:"error,omitempty"`
+}
+
+// History returns the last loads of the watcher, oldest first, starting with the initial load until it is pushed out.
+// At most 32 loads are kept.
+func (w *Watcher[T]) History() []LoadRecord {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]LoadRecord{}, w.history...)
+}
+
+// HistoryHandler returns an HTTP handler serving the history of the watcher as a JSON array, to be mounted on a debug
+// endpoint. The records hold fingerprints instead of values, but the errors of failed loads quote the invalid values of
+// fields that are not secret, as do the errors returned by Validator and PostLoader implementations, so the endpoint
+// should not be exposed publicly.
+//
+// Example:
+//
+//	http.Handle("/debug/config/history", w.HistoryHandler())
+func (w *Watcher[T]) HistoryHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(w.History())
+	})
+}
+
+// record adds the outcome of a load to the history, dropping the oldest load when it is full. The caller must hold mu.
+func (w *Watcher[T]) record(report *Report, changes Changes, err error) {
+	entry := LoadRecord{Time: time.Now(), Warnings: len(report.Warnings)}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		// the whole current config is fingerprinted, as the report of ReloadGroup only covers the reloaded group, and it
+		// was loaded with the options of the watcher, so the fingerprints cannot fail
+		entry.Fingerprint, _ = Fingerprint(w.current.Load(), w.opts...)
+		entry.SecretFingerprint, _ = SecretFingerprint(w.current.Load(), w.secretKey, w.opts...)
+	}
+	for _, change := range changes {
+		entry.Changed = append(entry.Changed, change.Path)
+	}
+	if len(w.history) == historySize {
+		w.history = append(w.history[:0], w.history[1:]...)
+	}
+	w.history = append(w.history, entry)
+}
@@ -0,0 +1,79 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// PostLoader is implemented by config structs that derive values from their loaded fields, e.g. building a DSN from a
+// host, port and user. LoadEnv calls PostLoad after all fields of the struct, including its nested structs, are loaded.
+type PostLoader interface {
+	PostLoad() error
+}
+
+// Validator is implemented by config structs that validate their loaded fields together, e.g. requiring a TLS key
+// when a TLS certificate is set. LoadEnv calls Validate after PostLoad.
+type Validator interface {
+	Validate() error
+}
+
+// SecretResolverProvider is implemented by config structs that configure secret resolvers for the fields loaded after
+// them, e.g. a struct holding the address and token of Vault providing a VaultResolver. LoadEnv registers the
+// resolvers by scheme after Validate, overriding resolvers given with WithSecretResolver. Fields that depend on the
+// resolvers must be loaded later, by declaring them later or with the envDependsOn struct tag.
+type SecretResolverProvider interface {
+	SecretResolvers() map[string]SecretResolver
+}
+
+// HookError is returned when the PostLoad or Validate method of a config struct fails.
+type HookError struct {
+	// Path is the dotted path of the struct from the root config struct, empty for the root config struct itself.
+	Path string
+	// Hook is the name of the failed method, PostLoad or Validate.
+	Hook string
+	// Err is the error returned by the method.
+	Err error
+}
+
+func (e *HookError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s of config failed: %s", e.Hook, e.Err)
+	}
+	return fmt.Sprintf("%s of config '%s' failed: %s", e.Hook, e.Path, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// runHooks calls the PostLoad and Validate methods of a loaded struct, if it implements them, and registers the
+// resolvers of a SecretResolverProvider. The hooks are skipped once errors have been collected, as they would run on
+// an incomplete config.
+// used internally by LoadEnv.
+func (l *loader) runHooks(val reflect.Value, path string) error {
+	if len(l.errs) > 0 || !val.CanAddr() || l.defaultsOnly {
+		return nil
+	}
+	config := val.Addr().Interface()
+	if postLoader, ok := config.(PostLoader); ok {
+		err := postLoader.PostLoad()
+		if err != nil {
+			return l.fail(&HookError{Path: path, Hook: "PostLoad", Err: err})
+		}
+	}
+	if validator, ok := config.(Validator); ok {
+		err := validator.Validate()
+		if err != nil {
+			return l.fail(&HookError{Path: path, Hook: "Validate", Err: err})
+		}
+	}
+	if provider, ok := config.(SecretResolverProvider); ok {
+		for scheme, resolver := range provider.SecretResolvers() {
+			if l.resolvers == nil {
+				l.resolvers = map[string]SecretResolver{}
+			}
+			l.resolvers[scheme] = resolver
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type HookDBConfig struct {
+	Host string `env:"DB_HOST"`
+	Port int    `env:"DB_PORT;default:5432"`
+	DSN  string
+}
+
+func (c *HookDBConfig) PostLoad() error {
+	c.DSN = fmt.Sprintf("postgres://%s:%d", c.Host, c.Port)
+	return nil
+}
+
+type HookConfig struct {
+	DB       HookDBConfig
+	TLSCert  string `env:"TLS_CERT;optional"`
+	TLSKey   string `env:"TLS_KEY;optional"`
+	Replicas int    `env:"REPLICAS;default:1"`
+}
+
+func (c HookConfig) Validate() error {
+	if c.TLSCert != "" && c.TLSKey == "" {
+		return errors.New("TLS_KEY is required when TLS_CERT is set")
+	}
+	return nil
+}
+
+func TestPostLoadHooks(t *testing.T) {
+	cfg := HookConfig{}
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"DB_HOST": "db"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.DB.DSN != "postgres://db:5432" {
+		t.Errorf("Expected DSN=postgres://db:5432, got %s", cfg.DB.DSN)
+	}
+
+	err = LoadEnvWithOptions(&cfg, WithSources(MapSource{"DB_HOST": "db", "TLS_CERT": "cert.pem"}))
+	expected := "Validate of config failed: TLS_KEY is required when TLS_CERT is set"
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = LoadEnvWithOptions(&cfg, WithSources(MapSource{"TLS_CERT": "cert.pem"}), WithAllErrors())
+	expected = "error loading field 'DB.Host': environment variable not found: DB_HOST"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected the hooks to be skipped after %s, got %v", expected, err)
+	}
+}
+
+type HookVaultConfig struct {
+	Token string `env:"VAULT_TOKEN;secret"`
+}
+
+func (c *HookVaultConfig) SecretResolvers() map[string]SecretResolver {
+	return map[string]SecretResolver{"vault": SecretResolverFunc(func(ref string) (string, error) {
+		return c.Token + ":" + ref, nil
+	})}
+}
+
+func TestSecretResolverDependencies(t *testing.T) {
+	cfg := struct {
+		DB struct {
+			Password string `env:"DB_PASSWORD;secret;secretref:vault://db"`
+		} `envDependsOn:"Vault"`
+		Vault HookVaultConfig
+	}{}
+	report, err := LoadEnvReport(&cfg, WithSources(MapSource{"VAULT_TOKEN": "token"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.DB.Password != "token:db" || report.Fields[0].Env != "DB_PASSWORD" || report.Fields[1].Env != "VAULT_TOKEN" {
+		t.Errorf("Expected DB_PASSWORD=token:db and the report in declaration order, got %v", report.Fields)
+	}
+
+	cyclic := struct {
+		A HookVaultConfig `envDependsOn:"B"`
+		B HookDBConfig    `envDependsOn:"A"`
+	}{}
+	err = LoadEnvWithOptions(&cyclic, WithSources(MapSource{}))
+	expected := "invalid dependencies of field 'A': cyclic dependency"
+	var dependencyErr *DependencyError
+	if !errors.As(err, &dependencyErr) || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,101 @@
+package goloadenv
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// HostPort is a network address of a host and a port, e.g. BIND_ADDR=0.0.0.0:9090, [::1]:9090 or :9090 for all
+// interfaces, validated at load time rather than when it is passed to net.Listen or net.Dial. The host is not resolved,
+// unlike for a net.TCPAddr, so it can be a host name that only resolves where it is used.
+type HostPort struct {
+	// Host is the host name or IP address, without brackets for IPv6 addresses, empty for all interfaces.
+	Host string
+	// Port is the port number.
+	Port int
+}
+
+// UnmarshalText parses an address like 0.0.0.0:9090, [::1]:9090 or :9090.
+func (h *HostPort) UnmarshalText(text []byte) error {
+	hostPort, err := parseHostPort(string(text))
+	if err != nil {
+		return err
+	}
+	*h = hostPort
+	return nil
+}
+
+// MarshalText formats the address like String, so it can be parsed again.
+func (h HostPort) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// String formats the address, e.g. 0.0.0.0:9090 or [::1]:9090, for net.Listen and net.Dial.
+func (h HostPort) String() string {
+	return net.JoinHostPort(h.Host, strconv.Itoa(h.Port))
+}
+
+// hostPortType is the type of fields that hold an address parsed by parseHostPort.
+var hostPortType = reflect.TypeOf(HostPort{})
+
+// parseHostPort parses a host:port address with an IP address, a host name or nothing as host and a numeric port from
+// 0 to 65535.
+func parseHostPort(str string) (HostPort, error) {
+	host, port, err := net.SplitHostPort(str)
+	if err != nil {
+		return HostPort{}, fmt.Errorf("invalid address '%s', expected host:port", str)
+	}
+	if host != "" && !isIPAddress(host) && !isHostName(host) {
+		return HostPort{}, fmt.Errorf("invalid host '%s', expected an IP address or host name", host)
+	}
+	number, err := strconv.Atoi(port)
+	if err != nil || number < 0 || number > 65535 {
+		return HostPort{}, fmt.Errorf("invalid port '%s', expected 0 to 65535", port)
+	}
+	return HostPort{Host: host, Port: number}, nil
+}
+
+// validateHostPort enforces the hostport option on a string field, which must hold a host:port address as parsed into a
+// HostPort. A HostPort field is validated when it is parsed, so the option is redundant but allowed.
+func validateHostPort(field reflect.Value, tags map[string]string) error {
+	if field.Type() == hostPortType {
+		return nil
+	}
+	if field.Kind() != reflect.String {
+		return &EnvParseError{value: fmt.Sprint(field.Interface()), env: tags["name"], err: fmt.Errorf("hostport only applies to string and HostPort fields, not %s", field.Type())}
+	}
+	if _, err := parseHostPort(field.String()); err != nil {
+		return &ValidationError{Env: tags["name"], Rule: "hostport", Value: field.String(), Reason: "must be a host:port address"}
+	}
+	return nil
+}
+
+// isIPAddress reports whether the host is an IPv4 or IPv6 address, the latter optionally with a zone like fe80::1%eth0.
+func isIPAddress(host string) bool {
+	_, err := netip.ParseAddr(host)
+	return err == nil
+}
+
+// isHostName reports whether the host is a DNS name of at most 253 characters, with an optional trailing dot, made of
+// labels of 1 to 63 letters, digits, hyphens and underscores that do not start or end with a hyphen.
+func isHostName(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	indexTagName = "envIndex"
+	// indexPlaceholder is replaced by the index of an element in the pattern of the envIndex tag.
+	indexPlaceholder = "{i}"
+	// maxIndexedElements caps the elements of an indexed slice field, as a lookup that finds every name would never
+	// reach a gap.
+	maxIndexedElements = 10000
+)
+
+// indexedSlice reports whether a field is a slice of structs, or of pointers to structs, loaded from indexed variables
+// such as UPSTREAM_0_HOST and UPSTREAM_1_HOST, and returns the struct type of its elements. Such a field is not tagged
+// with a variable name itself but with an envPrefix or envIndex tag.
+func indexedSlice(structField reflect.StructField, tags map[string]string) (reflect.Type, bool) {
+	if tags["name"] != "" || tags["format"] != "" || structField.Type.Kind() != reflect.Slice {
+		return nil, false
+	}
+	_, hasPrefix := structField.Tag.Lookup(prefixTagName)
+	_, hasIndex := structField.Tag.Lookup(indexTagName)
+	if !hasPrefix && !hasIndex {
+		return nil, false
+	}
+	elem := structField.Type.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if !isNestedStruct(elem) {
+		return nil, false
+	}
+	return elem, true
+}
+
+// indexPattern returns the prefix pattern of the elements of an indexed slice field, the envIndex tag, e.g.
+// UPSTREAM_{i}_, or the envPrefix tag followed by {i}_.
+func indexPattern(structField reflect.StructField) (string, error) {
+	pattern, hasIndex := structField.Tag.Lookup(indexTagName)
+	if !hasIndex {
+		return structField.Tag.Get(prefixTagName) + indexPlaceholder + "_", nil
+	}
+	if !strings.Contains(pattern, indexPlaceholder) {
+		return "", fmt.Errorf("%s tag of field '%s' must contain %s", indexTagName, structField.Name, indexPlaceholder)
+	}
+	return pattern, nil
+}
+
+// loadIndexed loads the elements of an indexed slice field, starting at index 0 and stopping at the first index for
+// which none of the variables of the element struct are set. The field is left untouched when no element is set, and
+// more than maxIndexedElements elements fail with a LimitError.
+func (l *loader) loadIndexed(field reflect.Value, structField reflect.StructField, elemType reflect.Type, prefix string, path string) error {
+	pattern, err := indexPattern(structField)
+	if err != nil {
+		return err
+	}
+	err = checkCycles(elemType)
+	if err != nil {
+		return err
+	}
+	names, err := structEnvNames(elemType, l.tagName)
+	if err != nil {
+		return err
+	}
+	elements := reflect.MakeSlice(field.Type(), 0, 0)
+	for index := 0; ; index++ {
+		elemPrefix := prefix + strings.ReplaceAll(pattern, indexPlaceholder, strconv.Itoa(index))
+		if !l.anySet(elemPrefix, names) {
+			break
+		}
+		if index == maxIndexedElements {
+			return &LimitError{Limit: "elements", Max: maxIndexedElements, Where: path}
+		}
+		elemPath := fmt.Sprintf("%s[%d]", path, index)
+		err := l.limits.enterStruct(elemPath)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(elemType)
+		err = l.loadStruct(elem.Elem(), elemPrefix, elemPath)
+		if err != nil {
+			return err
+		}
+		l.limits.leaveStruct()
+		if field.Type().Elem().Kind() == reflect.Ptr {
+			elements = reflect.Append(elements, elem)
+		} else {
+			elements = reflect.Append(elements, elem.Elem())
+		}
+	}
+	if elements.Len() > 0 {
+		field.Set(elements)
+	}
+	return nil
+}
+
+// anySet reports whether any of the given variable names, or the *_FILE variable of one, is set with the given prefix.
+func (l *loader) anySet(prefix string, names []string) bool {
+	for _, name := range names {
+		if _, found := l.lookup(prefix + name); found {
+			return true
+		}
+		if _, found := l.lookup(prefix + name + fileSuffix); found {
+			return true
+		}
+	}
+	return false
+}
+
+// structEnvNames returns the unprefixed variable names of the tagged fields of a struct type, including those of its
+// nested structs.
+func structEnvNames(typ reflect.Type, tagName string) ([]string, error) {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if isSkipped(structField, tagName) {
+			continue
+		}
+		tags, err := parseTags(structField, tagName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting tags for field: '%s': %w", structField.Name, err)
+		}
+		nested := structField.Type
+		if structField.Anonymous && nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		if isNestedStruct(nested) && tags["format"] == "" {
+			nestedNames, err := structEnvNames(nested, tagName)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range nestedNames {
+				names = append(names, structField.Tag.Get(prefixTagName)+name)
+			}
+			continue
+		}
+		if tags["name"] != "" {
+			names = append(names, tags["name"])
+		}
+	}
+	return names, nil
+}
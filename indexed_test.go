@@ -0,0 +1,58 @@
+package goloadenv
+
+import (
+	"testing"
+)
+
+type IndexedUpstream struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT;default:80"`
+}
+
+type IndexedConfig struct {
+	Upstreams []IndexedUpstream  `envPrefix:"UPSTREAM_"`
+	Replicas  []*IndexedUpstream `envIndex:"REPLICA{i}__"`
+}
+
+func TestIndexedStructSlices(t *testing.T) {
+	cfg := IndexedConfig{}
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{
+		"UPSTREAM_0_HOST": "a",
+		"UPSTREAM_0_PORT": "8080",
+		"UPSTREAM_1_HOST": "b",
+		"UPSTREAM_3_HOST": "d",
+		"REPLICA0__HOST":  "r",
+	}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(cfg.Upstreams) != 2 || cfg.Upstreams[0] != (IndexedUpstream{"a", 8080}) || cfg.Upstreams[1] != (IndexedUpstream{"b", 80}) {
+		t.Errorf("Expected upstreams [{a 8080} {b 80}], got %v", cfg.Upstreams)
+	}
+	if len(cfg.Replicas) != 1 || *cfg.Replicas[0] != (IndexedUpstream{"r", 80}) {
+		t.Errorf("Expected replicas [{r 80}], got %v", cfg.Replicas)
+	}
+
+	cfg = IndexedConfig{}
+	err = LoadEnvWithOptions(&cfg, WithSources(MapSource{"UPSTREAM_0_PORT": "8080"}))
+	expected := "error loading field 'Upstreams[0].Host': environment variable not found: UPSTREAM_0_HOST"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	type invalidConfig struct {
+		Upstreams []IndexedUpstream `envIndex:"UPSTREAM_"`
+	}
+	err = LoadEnvWithOptions(&invalidConfig{}, WithSources(MapSource{}))
+	expected = "envIndex tag of field 'Upstreams' must contain {i}"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	// a lookup that finds every name never reaches a gap
+	err = LoadEnvWithOptions(&IndexedConfig{}, WithLookupFunc(func(string) (string, bool) { return "1", true }))
+	expected = "limit exceeded: elements of Upstreams exceeds the maximum of 10000"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,183 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FieldInfo describes a configuration field together with its resolved tag metadata.
+type FieldInfo struct {
+	// Path is the dotted path of the field from the root config struct, e.g. "DB.Host".
+	Path string
+	// Name is the name of the environment variable backing the field, empty if the field is not tagged.
+	Name string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Tags holds the parsed tag options of the field, keyed by option name.
+	Tags map[string]string
+	// StructField is the underlying struct field.
+	StructField reflect.StructField
+}
+
+// Iterate walks the fields of a config struct in declaration order and calls fn for every field that is not a nested
+// struct, nested structs are descended into instead. Fields tagged env:"-" are skipped. Fields of embedded structs are
+// promoted, so their path does not include the embedded type, and a nil embedded struct pointer is walked as its zero
+// value. The config may be a struct or a pointer to a struct.
+// The walk stops at the first error returned by fn, which is returned as is.
+//
+// Example:
+//
+//	err := goloadenv.Iterate(&cfg, func(f goloadenv.FieldInfo, v reflect.Value) error {
+//	  fmt.Printf("%s=%v\n", f.Name, v.Interface())
+//	  return nil
+//	})
+func Iterate(config interface{}, fn func(f FieldInfo, v reflect.Value) error) error {
+	val := reflect.ValueOf(config)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return errors.New("config must be a struct or a pointer to a struct")
+	}
+	err := checkCycles(val.Type())
+	if err != nil {
+		return err
+	}
+	return iterateStruct(val, "", "", tagName, fn)
+}
+
+func iterateStruct(val reflect.Value, path string, prefix string, tagName string, fn func(f FieldInfo, v reflect.Value) error) error {
+	for i := 0; i < val.NumField(); i++ {
+		structField := val.Type().Field(i)
+		if isSkipped(structField, tagName) {
+			continue
+		}
+		fieldPath := joinPath(path, structField.Name)
+		tags, err := parseTags(structField, tagName)
+		if err != nil {
+			return fmt.Errorf("error getting tags for field: '%s': %w", fieldPath, err)
+		}
+		if nested, nestedPath, ok := nestedStruct(val, i, path, false); ok && tags["format"] == "" {
+			err := iterateStruct(nested, nestedPath, prefix+structField.Tag.Get(prefixTagName), tagName, fn)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if tags["name"] != "" {
+			tags["name"] = prefix + tags["name"]
+		}
+		info := FieldInfo{
+			Path:        fieldPath,
+			Name:        tags["name"],
+			Type:        structField.Type,
+			Tags:        tags,
+			StructField: structField,
+		}
+		err = fn(info, val.Field(i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterate walks the fields of a config struct like Iterate, but resolves the tags of the fields like a load with the
+// options of the loader, so the variable names have the prefix of WithPrefix, the tag name of WithTagName and the names
+// derived by WithDerivedNames, and fields marked by the redaction policy have the secret flag.
+func (l *loader) iterate(config interface{}, fn func(f FieldInfo, v reflect.Value) error) error {
+	val := reflect.ValueOf(config)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return errors.New("config must be a struct or a pointer to a struct")
+	}
+	err := checkCycles(val.Type())
+	if err != nil {
+		return err
+	}
+	return l.iterateStruct(val, "", l.prefix, fn)
+}
+
+func (l *loader) iterateStruct(val reflect.Value, path string, prefix string, fn func(f FieldInfo, v reflect.Value) error) error {
+	for i := 0; i < val.NumField(); i++ {
+		structField := val.Type().Field(i)
+		if isSkipped(structField, l.tagName) {
+			continue
+		}
+		fieldPath := joinPath(path, structField.Name)
+		tags, err := parseTags(structField, l.tagName)
+		if err != nil {
+			return fmt.Errorf("error getting tags for field: '%s': %w", fieldPath, err)
+		}
+		if nested, nestedPath, ok := nestedStruct(val, i, path, false); ok && tags["format"] == "" {
+			err := l.iterateStruct(nested, nestedPath, prefix+structField.Tag.Get(prefixTagName), fn)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		tags, err = l.getTags(structField, tags, prefix)
+		if err != nil {
+			return fmt.Errorf("error getting tags for field: '%s': %w", fieldPath, err)
+		}
+		info := FieldInfo{
+			Path:        fieldPath,
+			Name:        tags["name"],
+			Type:        structField.Type,
+			Tags:        tags,
+			StructField: structField,
+		}
+		err = fn(info, val.Field(i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSkipped reports whether a field is excluded from loading, printing and every other walk of a config struct by the
+// tag value "-", e.g. env:"-", which also excludes nested structs.
+func isSkipped(structField reflect.StructField, tagName string) bool {
+	return structField.Tag.Get(tagName) == "-"
+}
+
+// joinPath appends the name of a field to the dotted path of its parent struct.
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// nestedStruct returns the nested config struct held by the i-th field of val together with its dotted path. Fields of
+// anonymous embedded structs are promoted, so they keep the path of the embedding struct. Embedded pointers to structs
+// are followed, a nil pointer is allocated when alloc is set and replaced by a read-only zero value otherwise. It
+// returns false if the field does not hold a nested struct, or holds a nil pointer that cannot be allocated.
+func nestedStruct(val reflect.Value, i int, path string, alloc bool) (reflect.Value, string, bool) {
+	structField := val.Type().Field(i)
+	field := val.Field(i)
+	nestedPath := joinPath(path, structField.Name)
+	if structField.Anonymous {
+		nestedPath = path
+	}
+	if isNestedStruct(field.Type()) {
+		return field, nestedPath, true
+	}
+	if !structField.Anonymous || field.Kind() != reflect.Ptr || !isNestedStruct(field.Type().Elem()) {
+		return reflect.Value{}, "", false
+	}
+	if !field.IsNil() {
+		return field.Elem(), nestedPath, true
+	}
+	if !alloc {
+		return reflect.Zero(field.Type().Elem()), nestedPath, true
+	}
+	if !field.CanSet() {
+		return reflect.Value{}, "", false
+	}
+	field.Set(reflect.New(field.Type().Elem()))
+	return field.Elem(), nestedPath, true
+}
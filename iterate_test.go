@@ -0,0 +1,36 @@
+package goloadenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIterate(t *testing.T) {
+	clearTestEnv()
+
+	cfg := TestConfig{Host: "localhost", Struct: EmbbededStruct{Host: "db"}}
+	var paths []string
+	err := Iterate(&cfg, func(f FieldInfo, v reflect.Value) error {
+		paths = append(paths, f.Path+"="+f.Name)
+		if f.Path == "Struct.Host" && v.String() != "db" {
+			t.Errorf("Expected Struct.Host=db, got %s", v.String())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"Host=HOST",
+		"Port=PORT",
+		"Optional=OPTIONAL",
+		"Default=DEFAULT",
+		"Struct.Host=DB_HOST",
+		"StructParseErr.ParseErr=PARSE_EMBEDDED_ERR",
+		"ParseErr=PARSE_ERR",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
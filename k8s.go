@@ -0,0 +1,179 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultK8sSecretName is the name of the Secret the secret fields are referenced from, unless set with
+// WithK8sSecretName.
+const defaultK8sSecretName = "app-secrets"
+
+// K8sOption customizes the Kubernetes manifest sections generated by GenerateK8sEnv.
+type K8sOption func(*k8sOptions)
+
+type k8sOptions struct {
+	secretName    string
+	configMapName string
+	placeholders  bool
+}
+
+// WithK8sSecretName references the secret fields from the Secret with the given name instead of app-secrets.
+func WithK8sSecretName(name string) K8sOption {
+	return func(o *k8sOptions) {
+		o.secretName = name
+	}
+}
+
+// WithK8sConfigMap moves the variables of the fields that are not secret to a ConfigMap with the given name, which is
+// loaded with envFrom, and appends a skeleton of the ConfigMap as a separate YAML document.
+func WithK8sConfigMap(name string) K8sOption {
+	return func(o *k8sOptions) {
+		o.configMapName = name
+	}
+}
+
+// WithK8sPlaceholders writes the variables that are required and have no default value as commented out placeholders
+// to fill in, instead of failing.
+func WithK8sPlaceholders() K8sOption {
+	return func(o *k8sOptions) {
+		o.placeholders = true
+	}
+}
+
+// GenerateK8sEnv generates the env section of a Kubernetes container spec for a config struct, so the struct is the
+// single source of truth for deployment manifests. Variables are listed with their default value. Required variables
+// without a default have no value to list and fail the generation, unless WithK8sPlaceholders writes them as commented
+// out placeholders. Optional variables without a default and variables with a computed default are left to the
+// application. Secret fields are referenced with a secretKeyRef instead, keyed by their variable name, and
+// the variables of KubeMetadata with a fieldRef to the downward API. The desc struct tag is added as a comment.
+//
+// Example:
+//
+//	type Config struct {
+//	  Port     int    `env:"PORT;default:8080" desc:"Port the server listens on"`
+//	  Password string `env:"DB_PASSWORD;secret"`
+//	}
+//
+// generates
+//
+//	env:
+//	  # Port the server listens on
+//	  - name: PORT
+//	    value: "8080"
+//	  - name: DB_PASSWORD
+//	    valueFrom:
+//	      secretKeyRef:
+//	        name: app-secrets
+//	        key: DB_PASSWORD
+func GenerateK8sEnv(config interface{}, opts ...K8sOption) (string, error) {
+	options := k8sOptions{secretName: defaultK8sSecretName}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	var env, data, missing []string
+	err := Iterate(config, func(f FieldInfo, _ reflect.Value) error {
+		if f.Name == "" {
+			return nil
+		}
+		entry, isSet := k8sEnvEntry(f, options)
+		if !isSet {
+			return nil
+		}
+		_, isDownward := downwardAPIFieldPath(f)
+		inConfigMap := options.configMapName != "" && !isSecret(f.Tags, f.Name) && !isDownward
+		if inConfigMap {
+			entry = fmt.Sprintf("  %s: %s", f.Name, entry)
+		}
+		if isK8sPlaceholder(f) {
+			missing = append(missing, f.Name)
+			entry = "  # " + f.Name + " is required and has no default, fill in its value\n" +
+				"  # " + strings.ReplaceAll(strings.TrimPrefix(entry, "  "), "\n  ", "\n  # ")
+		}
+		if inConfigMap {
+			data = append(data, k8sComment(f, "  ")+entry)
+			return nil
+		}
+		env = append(env, k8sComment(f, "  ")+entry)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(missing) > 0 && !options.placeholders {
+		return "", fmt.Errorf("required variables without a default value cannot be written to a manifest: %s", strings.Join(missing, ", "))
+	}
+	var builder strings.Builder
+	if options.configMapName != "" {
+		fmt.Fprintf(&builder, "envFrom:\n  - configMapRef:\n      name: %s\n", options.configMapName)
+	}
+	if len(env) > 0 || options.configMapName == "" {
+		builder.WriteString("env:")
+		if len(env) == 0 {
+			builder.WriteString(" []")
+		}
+		builder.WriteString("\n" + strings.Join(append(env, ""), "\n"))
+	}
+	if options.configMapName != "" {
+		fmt.Fprintf(&builder, "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\ndata:", options.configMapName)
+		if len(data) == 0 {
+			builder.WriteString(" {}")
+		}
+		builder.WriteString("\n" + strings.Join(append(data, ""), "\n"))
+	}
+	return builder.String(), nil
+}
+
+// k8sEnvEntry formats the env entry of a field, or only its quoted value when it goes to the ConfigMap, and reports
+// whether the field is listed at all.
+func k8sEnvEntry(f FieldInfo, options k8sOptions) (string, bool) {
+	defaultValue, hasDefault := f.Tags["default"]
+	_, isOptional := f.Tags["optional"]
+	if fieldPath, isDownward := downwardAPIFieldPath(f); isDownward {
+		return fmt.Sprintf("  - name: %s\n    valueFrom:\n      fieldRef:\n        fieldPath: %s", f.Name, fieldPath), true
+	}
+	if isSecret(f.Tags, f.Name) {
+		entry := fmt.Sprintf("  - name: %s\n    valueFrom:\n      secretKeyRef:\n        name: %s\n        key: %s", f.Name, options.secretName, f.Name)
+		if hasDefault || isOptional {
+			entry += "\n        optional: true"
+		}
+		return entry, true
+	}
+	if hasDefault && isComputedDefault(f.Tags) || !hasDefault && isOptional {
+		return "", false
+	}
+	if options.configMapName != "" {
+		return strconv.Quote(defaultValue), true
+	}
+	return fmt.Sprintf("  - name: %s\n    value: %s", f.Name, strconv.Quote(defaultValue)), true
+}
+
+// isK8sPlaceholder reports whether a field is listed without a value, as it is required and has no default.
+func isK8sPlaceholder(f FieldInfo) bool {
+	_, hasDefault := f.Tags["default"]
+	_, isOptional := f.Tags["optional"]
+	_, isDownward := downwardAPIFieldPath(f)
+	return !hasDefault && !isOptional && !isDownward && !isSecret(f.Tags, f.Name)
+}
+
+// k8sComment returns the desc struct tag of a field as a YAML comment line at the given indentation, if set.
+func k8sComment(f FieldInfo, indentation string) string {
+	desc := f.StructField.Tag.Get(descTagName)
+	if desc == "" {
+		return ""
+	}
+	return indentation + "# " + desc + "\n"
+}
+
+// isComputedDefault reports whether the default value of a field is computed at load time, by a default function, an
+// expression, a template, pseudo variables or, with the expand flag, variable references, so it cannot be written to a
+// manifest as is. A $$ is a literal $, not a reference.
+func isComputedDefault(tags map[string]string) bool {
+	defaultValue := tags["default"]
+	_, expand := tags["expand"]
+	return strings.HasPrefix(defaultValue, defaultFuncPrefix) || isExprDefault(defaultValue) ||
+		isTemplateDefault(defaultValue) || hasPseudoVarReference(defaultValue) ||
+		expand && hasVarReference(defaultValue)
+}
@@ -0,0 +1,188 @@
+package goloadenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateK8sEnv(t *testing.T) {
+	cfg := struct {
+		Host     string `env:"HOST" desc:"Hostname the server binds to"`
+		Port     int    `env:"PORT;default:8080"`
+		LogLevel string `env:"LOG_LEVEL;optional"`
+		DataDir  string `env:"DATA_DIR;expand;default:${HOME}/data"`
+		DB       struct {
+			Password string `env:"PASSWORD;secret"`
+			Token    string `env:"TOKEN;secret;optional"`
+		} `envPrefix:"DB_"`
+	}{}
+
+	expected := `env:
+  # Hostname the server binds to
+  # HOST is required and has no default, fill in its value
+  # - name: HOST
+  #   value: ""
+  - name: PORT
+    value: "8080"
+  - name: DB_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: app-secrets
+        key: DB_PASSWORD
+  - name: DB_TOKEN
+    valueFrom:
+      secretKeyRef:
+        name: app-secrets
+        key: DB_TOKEN
+        optional: true
+`
+	got, err := GenerateK8sEnv(&cfg, WithK8sPlaceholders())
+	if err != nil || got != expected {
+		t.Errorf("Expected %s, got %s, %v", expected, got, err)
+	}
+
+	expected = `envFrom:
+  - configMapRef:
+      name: app-config
+env:
+  - name: DB_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: db
+        key: DB_PASSWORD
+  - name: DB_TOKEN
+    valueFrom:
+      secretKeyRef:
+        name: db
+        key: DB_TOKEN
+        optional: true
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  # Hostname the server binds to
+  # HOST is required and has no default, fill in its value
+  # HOST: ""
+  PORT: "8080"
+`
+	got, err = GenerateK8sEnv(&cfg, WithK8sConfigMap("app-config"), WithK8sSecretName("db"), WithK8sPlaceholders())
+	if err != nil || got != expected {
+		t.Errorf("Expected %s, got %s, %v", expected, got, err)
+	}
+
+	_, err = GenerateK8sEnv(&cfg)
+	expectedErr := "required variables without a default value cannot be written to a manifest: HOST"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+
+	literal := struct {
+		Price  string `env:"PRICE;default:$$5"`
+		Prompt string `env:"PROMPT;default:$ "`
+	}{}
+	got, err = GenerateK8sEnv(&literal)
+	expected = "env:\n  - name: PRICE\n    value: \"$$5\"\n  - name: PROMPT\n    value: \"$ \"\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %s, got %s, %v", expected, got, err)
+	}
+
+	_, err = GenerateK8sEnv(42)
+	if err == nil {
+		t.Errorf("Expected an error for a config that is not a struct")
+	}
+}
+
+func TestKubeMetadata(t *testing.T) {
+	type Config struct {
+		Kube KubeMetadata
+		Port int `env:"PORT;default:8080"`
+	}
+
+	var cfg Config
+	err := LoadEnvFromMap(&cfg, map[string]string{"HOSTNAME": "laptop"})
+	if err != nil {
+		t.Errorf("Expected no error outside the cluster, got %v", err)
+	}
+	if cfg.Kube.InCluster() || cfg.Kube.PodName != "laptop" {
+		t.Errorf("Expected to be outside the cluster with pod name laptop, got %+v", cfg.Kube)
+	}
+
+	namespaceFile := filepath.Join(t.TempDir(), "namespace")
+	cfg.Kube.namespaceFile = namespaceFile
+	env := map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1", "HOSTNAME": "api-7d9f", "NODE_NAME": "node-1"}
+	err = LoadEnvFromMap(&cfg, env)
+	expected := "Validate of config 'Kube' failed: running in Kubernetes without POD_NAMESPACE from the downward API"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = os.WriteFile(namespaceFile, []byte("payments\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = LoadEnvFromMap(&cfg, env)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !cfg.Kube.InCluster() || cfg.Kube.PodName != "api-7d9f" || cfg.Kube.PodNamespace != "payments" || cfg.Kube.NodeName != "node-1" {
+		t.Errorf("Expected the pod metadata, got %+v", cfg.Kube)
+	}
+
+	manifest, err := GenerateK8sEnv(&Config{}, WithK8sConfigMap("app-config"))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected = `envFrom:
+  - configMapRef:
+      name: app-config
+env:
+  - name: POD_NAME
+    valueFrom:
+      fieldRef:
+        fieldPath: metadata.name
+  - name: POD_NAMESPACE
+    valueFrom:
+      fieldRef:
+        fieldPath: metadata.namespace
+  - name: POD_IP
+    valueFrom:
+      fieldRef:
+        fieldPath: status.podIP
+  - name: NODE_NAME
+    valueFrom:
+      fieldRef:
+        fieldPath: spec.nodeName
+  - name: POD_SERVICE_ACCOUNT
+    valueFrom:
+      fieldRef:
+        fieldPath: spec.serviceAccountName
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  PORT: "8080"
+`
+	if manifest != expected {
+		t.Errorf("Expected %s, got %s", expected, manifest)
+	}
+
+	prefixed := struct {
+		Kube    KubeMetadata `envPrefix:"APP_"`
+		PodName string       `env:"POD_NAME;default:local"`
+	}{}
+	manifest, err = GenerateK8sEnv(&prefixed)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	for _, entry := range []string{"  - name: APP_POD_NAME\n    valueFrom:\n      fieldRef:\n        fieldPath: metadata.name\n", "  - name: POD_NAME\n    value: \"local\"\n"} {
+		if !strings.Contains(manifest, entry) {
+			t.Errorf("Expected the manifest to contain %s, got %s", entry, manifest)
+		}
+	}
+}
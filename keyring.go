@@ -0,0 +1,44 @@
+package goloadenv
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// runCommand runs a command and returns its standard output, it is replaced in tests.
+var runCommand = defaultRunCommand
+
+func defaultRunCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// KeychainSource returns an EnvSource reading variables from the generic passwords of the given service in the macOS
+// Keychain, with the name of the variable as the account, through the security command line tool. Such a password is
+// stored with e.g. security add-generic-password -s myapp -a API_TOKEN -w. A variable that is not in the Keychain, or
+// that cannot be read, is not set. Use it with WithSecretSources so only secret fields are looked up in the Keychain.
+func KeychainSource(service string) EnvSource {
+	return EnvSourceFunc(func(key string) (string, bool) {
+		return commandSecret("security", "find-generic-password", "-s", service, "-a", key, "-w")
+	})
+}
+
+// SecretServiceSource returns an EnvSource reading variables from the Secret Service of the Linux desktop, such as
+// GNOME Keyring or KWallet, through the secret-tool command line tool. Secrets are looked up by the service and
+// username attributes, with the name of the variable as the username, e.g. as stored by
+// secret-tool store --label=myapp service myapp username API_TOKEN. A variable that is not in the Secret Service, or
+// that cannot be read, is not set. Use it with WithSecretSources so only secret fields are looked up in the Secret
+// Service.
+func SecretServiceSource(service string) EnvSource {
+	return EnvSourceFunc(func(key string) (string, bool) {
+		return commandSecret("secret-tool", "lookup", "service", service, "username", key)
+	})
+}
+
+// commandSecret runs a command printing a secret, removing the trailing newline, and reports whether it succeeded.
+func commandSecret(name string, args ...string) (string, bool) {
+	output, err := runCommand(name, args...)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(output), "\n"), "\r"), true
+}
@@ -0,0 +1,52 @@
+package goloadenv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeyringSources(t *testing.T) {
+	var commands []string
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		if args[len(args)-1] == "API_TOKEN" || args[len(args)-2] == "API_TOKEN" {
+			return []byte("s3cr3t\n"), nil
+		}
+		return nil, errors.New("exit status 44")
+	}
+	defer func() {
+		runCommand = defaultRunCommand
+	}()
+
+	someStruct := struct {
+		Token    string `env:"API_TOKEN;secret"`
+		Password string `env:"PASSWORD;secret;optional"`
+		User     string `env:"USER;default:admin"`
+	}{}
+	report, err := LoadEnvReport(&someStruct, WithSources(MapSource{}), WithSecretSources(KeychainSource("myapp")))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Token != "s3cr3t" || someStruct.Password != "" || someStruct.User != "admin" {
+		t.Errorf("Expected API_TOKEN=s3cr3t, PASSWORD unset and USER=admin, got %v", someStruct)
+	}
+	if report.Fields[0].Origin != OriginSecretSource || report.Fields[1].Origin != OriginUnset {
+		t.Errorf("Expected origins secretsource and unset, got %s and %s", report.Fields[0].Origin, report.Fields[1].Origin)
+	}
+	expected := []string{
+		"security find-generic-password -s myapp -a API_TOKEN -w",
+		"security find-generic-password -s myapp -a PASSWORD -w",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Expected only secret fields to be looked up with %v, got %v", expected, commands)
+	}
+
+	if value, found := SecretServiceSource("myapp").Lookup("API_TOKEN"); !found || value != "s3cr3t" {
+		t.Errorf("Expected API_TOKEN=s3cr3t, got %s", value)
+	}
+	if commands[len(commands)-1] != "secret-tool lookup service myapp username API_TOKEN" {
+		t.Errorf("Expected a secret-tool lookup, got %s", commands[len(commands)-1])
+	}
+}
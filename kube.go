@@ -0,0 +1,106 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// kubeNamespaceFile is the namespace file of the service account mounted into every pod.
+const kubeNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+var kubeMetadataType = reflect.TypeFor[KubeMetadata]()
+
+// downwardAPIFields maps the fields of KubeMetadata to the pod fields exposing their variables through the downward
+// API.
+var downwardAPIFields = map[string]string{
+	"PodName":        "metadata.name",
+	"PodNamespace":   "metadata.namespace",
+	"PodIP":          "status.podIP",
+	"NodeName":       "spec.nodeName",
+	"ServiceAccount": "spec.serviceAccountName",
+}
+
+// downwardAPIFieldPath returns the pod field exposing the variable of a field of KubeMetadata through the downward API,
+// reporting whether the field is one, whatever the prefix of its variable.
+func downwardAPIFieldPath(f FieldInfo) (string, bool) {
+	fieldPath, isDownward := downwardAPIFields[f.StructField.Name]
+	if !isDownward {
+		return "", false
+	}
+	kubeField, _ := kubeMetadataType.FieldByName(f.StructField.Name)
+	return fieldPath, reflect.DeepEqual(f.StructField, kubeField)
+}
+
+// KubeMetadata holds the metadata of the Kubernetes pod a service runs in, from the variables conventionally exposed
+// through the downward API. It can be used as a nested struct of a config struct, without prefix, and is filled by
+// LoadEnv like any other nested struct. Outside a cluster every field is optional. In a cluster, detected by the
+// KUBERNETES_SERVICE_HOST variable Kubernetes sets in every container, the pod name falls back to HOSTNAME and the
+// namespace to the namespace of the mounted service account, and the load fails when either is still unknown.
+// GenerateK8sEnv references these variables from the downward API.
+//
+// Example:
+//
+//	type Config struct {
+//	  Kube goloadenv.KubeMetadata
+//	  Port int `env:"PORT;default:8080"`
+//	}
+type KubeMetadata struct {
+	// PodName is the name of the pod, from POD_NAME or else HOSTNAME.
+	PodName string `env:"POD_NAME;fallback:HOSTNAME;optional"`
+	// PodNamespace is the namespace of the pod, from POD_NAMESPACE or else the service account namespace file.
+	PodNamespace string `env:"POD_NAMESPACE;optional"`
+	// PodIP is the IP address of the pod, from POD_IP.
+	PodIP string `env:"POD_IP;optional"`
+	// NodeName is the name of the node the pod is scheduled on, from NODE_NAME.
+	NodeName string `env:"NODE_NAME;optional"`
+	// ServiceAccount is the name of the service account of the pod, from POD_SERVICE_ACCOUNT.
+	ServiceAccount string `env:"POD_SERVICE_ACCOUNT;optional"`
+	// ServiceHost is the address of the Kubernetes API server, from KUBERNETES_SERVICE_HOST, only set in a cluster.
+	ServiceHost string `env:"KUBERNETES_SERVICE_HOST;optional"`
+
+	// namespaceFile is the namespace file of the service account, kubeNamespaceFile unless set.
+	namespaceFile string
+}
+
+// InCluster reports whether the service runs in a Kubernetes cluster.
+func (m *KubeMetadata) InCluster() bool {
+	return m.ServiceHost != ""
+}
+
+// PostLoad reads the namespace of the mounted service account when the namespace is not set in a cluster.
+func (m *KubeMetadata) PostLoad() error {
+	if !m.InCluster() || m.PodNamespace != "" {
+		return nil
+	}
+	namespaceFile := m.namespaceFile
+	if namespaceFile == "" {
+		namespaceFile = kubeNamespaceFile
+	}
+	namespace, err := os.ReadFile(namespaceFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading the namespace of the service account: %w", err)
+	}
+	m.PodNamespace = strings.TrimSpace(string(namespace))
+	return nil
+}
+
+// Validate requires the pod name and namespace in a cluster.
+func (m *KubeMetadata) Validate() error {
+	if !m.InCluster() {
+		return nil
+	}
+	var missing []string
+	if m.PodName == "" {
+		missing = append(missing, "POD_NAME")
+	}
+	if m.PodNamespace == "" {
+		missing = append(missing, "POD_NAMESPACE")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("running in Kubernetes without %s from the downward API", strings.Join(missing, " and "))
+	}
+	return nil
+}
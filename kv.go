@@ -0,0 +1,361 @@
+package goloadenv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KVFetcher reads all variables from a key/value store, such as Consul, etcd or an HTTP endpoint serving JSON.
+type KVFetcher interface {
+	// FetchKV returns the variables of the store by name.
+	FetchKV(ctx context.Context) (map[string]string, error)
+}
+
+// KVFetcherFunc is an adapter to allow the use of ordinary functions as a KVFetcher.
+type KVFetcherFunc func(ctx context.Context) (map[string]string, error)
+
+// FetchKV calls f(ctx).
+func (f KVFetcherFunc) FetchKV(ctx context.Context) (map[string]string, error) {
+	return f(ctx)
+}
+
+// KVSource is an EnvSource reading variables from a key/value store, for platforms that keep configuration that is
+// not secret in a store rather than in environment variables. The variables are fetched at once and cached for the
+// TTL of the source. LoadEnv refreshes an expired cache of a source given with WithSources or WithSecretSources before
+// loading, with the context of LoadEnvContext, and fails the load when the store cannot be read. A lookup only reads
+// the cache, so a lookup outside a load sees the variables of the last refresh.
+//
+// Example:
+//
+//	kv := goloadenv.NewKVSource(&goloadenv.ConsulKV{Prefix: "myapp/"}, time.Minute)
+//	w, err := goloadenv.NewWatcher[Config](goloadenv.WithSources(goloadenv.ProcessEnv, kv))
+//	if err != nil {
+//	  return err
+//	}
+//	go kv.Watch(ctx, 30*time.Second, func() {
+//	  _, _ = w.Reload()
+//	}, nil)
+type KVSource struct {
+	fetcher KVFetcher
+	ttl     time.Duration
+	// mu guards the cache.
+	mu        sync.Mutex
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// NewKVSource returns a KVSource reading variables with the given fetcher, caching them for the TTL. A TTL of zero
+// caches the variables until Refresh is called.
+func NewKVSource(fetcher KVFetcher, ttl time.Duration) *KVSource {
+	return &KVSource{fetcher: fetcher, ttl: ttl}
+}
+
+// Lookup returns the value of the variable in the store.
+func (s *KVSource) Lookup(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, found := s.values[key]
+	return value, found
+}
+
+// Refresh fetches the variables from the store, keeping the cached variables when the store cannot be read, and
+// reports whether any variable changed.
+func (s *KVSource) Refresh(ctx context.Context) (bool, error) {
+	values, err := s.fetcher.FetchKV(ctx)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.fetchedAt.IsZero() || !maps.Equal(s.values, values)
+	s.values, s.fetchedAt = values, time.Now()
+	return changed, nil
+}
+
+// Watch refreshes the variables at the given interval until the context is done, calling onChange after every
+// refresh that changed a variable, e.g. to reload a Watcher, and onError, if not nil, with the error of every failed
+// refresh. It returns the error of the context.
+func (s *KVSource) Watch(ctx context.Context, interval time.Duration, onChange func(), onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		changed, err := s.Refresh(ctx)
+		switch {
+		case err != nil && onError != nil:
+			onError(err)
+		case changed && onChange != nil:
+			onChange()
+		}
+	}
+}
+
+// expired reports whether the variables were never fetched or their TTL expired.
+func (s *KVSource) expired() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetchedAt.IsZero() || s.ttl > 0 && time.Since(s.fetchedAt) >= s.ttl
+}
+
+// name returns the name of the source in metrics and traces, the type of its fetcher, e.g. ConsulKV.
+func (s *KVSource) name() string {
+	return strings.TrimPrefix(strings.TrimPrefix(fmt.Sprintf("%T", s.fetcher), "*"), "goloadenv.")
+}
+
+// keys returns the cached variable names, the candidates for case-insensitive matching.
+func (s *KVSource) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return mapKeys(s.values)
+}
+
+// kvSources returns the KVSources among the given sources, including those wrapped in a NamespacedSource.
+func kvSources(sources []EnvSource) []*KVSource {
+	var kv []*KVSource
+	for _, source := range sources {
+		if namespaced, isNamespaced := source.(namespacedSource); isNamespaced {
+			source = namespaced.source
+		}
+		if s, isKV := source.(*KVSource); isKV {
+			kv = append(kv, s)
+		}
+	}
+	return kv
+}
+
+// addKVSources adds the KVSources to the sources refreshed before loading, once each, as the same source can be given
+// with both WithSources and WithSecretSources.
+func (l *loader) addKVSources(sources []*KVSource) {
+	for _, source := range sources {
+		if !slices.Contains(l.kvSources, source) {
+			l.kvSources = append(l.kvSources, source)
+		}
+	}
+}
+
+// refreshKVSources refreshes the expired caches of the KVSources of the load, adding their variable names to the
+// candidates for case-insensitive matching.
+// used internally by LoadEnv.
+func (l *loader) refreshKVSources() error {
+	for _, source := range l.kvSources {
+		if source.expired() {
+			ctx, end := l.traceSource(source.name())
+			_, err := source.Refresh(ctx)
+			end(err)
+			if err != nil {
+				return fmt.Errorf("error reading key/value source: %w", err)
+			}
+		}
+		l.sourceKeys = append(l.sourceKeys, source.keys())
+	}
+	return nil
+}
+
+// kvKey derives the variable name of a key in a store from the part after the prefix, with slashes replaced by
+// underscores, so myapp/db/HOST with the prefix myapp/ is the variable db_HOST.
+func kvKey(key string, prefix string) (string, bool) {
+	name, found := strings.CutPrefix(key, prefix)
+	if !found || name == "" || strings.HasSuffix(name, "/") {
+		return "", false
+	}
+	return strings.ReplaceAll(name, "/", "_"), true
+}
+
+// escapeKVPath escapes every segment of a key path for use in a URL path, keeping the slashes between them.
+func escapeKVPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// ConsulKV is a KVFetcher reading the keys under a prefix from the key/value store of HashiCorp Consul.
+type ConsulKV struct {
+	// Address is the address of the Consul agent, defaults to the CONSUL_HTTP_ADDR environment variable.
+	Address string
+	// Token is the ACL token, defaults to the CONSUL_HTTP_TOKEN environment variable.
+	Token string
+	// Prefix is the key prefix of the variables, e.g. myapp/, which is removed from their names.
+	Prefix string
+	// Client is the HTTP client used for requests, defaults to a client with a 10 second timeout.
+	Client *http.Client
+}
+
+// FetchKV reads the keys under the prefix.
+func (c *ConsulKV) FetchKV(ctx context.Context) (map[string]string, error) {
+	address := c.Address
+	if address == "" {
+		address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	token := c.Token
+	if token == "" {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(address, "/")+"/v1/kv/"+escapeKVPath(c.Prefix)+"?recurse=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	var entries []struct {
+		Key   string
+		Value []byte
+	}
+	found, err := doKVRequest(kvClient(c.Client), req, "consul", true, &entries)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return map[string]string{}, nil
+	}
+	values := map[string]string{}
+	for _, entry := range entries {
+		if name, isVariable := kvKey(entry.Key, c.Prefix); isVariable {
+			values[name] = string(entry.Value)
+		}
+	}
+	return values, nil
+}
+
+// EtcdKV is a KVFetcher reading the keys under a prefix from etcd, through the JSON gateway of its v3 API.
+type EtcdKV struct {
+	// Address is the address of an etcd member, e.g. http://localhost:2379.
+	Address string
+	// Token is the authentication token, if authentication is enabled.
+	Token string
+	// Prefix is the key prefix of the variables, e.g. /myapp/, which is removed from their names.
+	Prefix string
+	// Client is the HTTP client used for requests, defaults to a client with a 10 second timeout.
+	Client *http.Client
+}
+
+// FetchKV reads the keys under the prefix.
+func (e *EtcdKV) FetchKV(ctx context.Context) (map[string]string, error) {
+	body, err := json.Marshal(map[string][]byte{"key": []byte(e.Prefix), "range_end": prefixRangeEnd(e.Prefix)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(e.Address, "/")+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.Token != "" {
+		req.Header.Set("Authorization", e.Token)
+	}
+	var response struct {
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	_, err = doKVRequest(kvClient(e.Client), req, "etcd", false, &response)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, kv := range response.Kvs {
+		if name, isVariable := kvKey(string(kv.Key), e.Prefix); isVariable {
+			values[name] = string(kv.Value)
+		}
+	}
+	return values, nil
+}
+
+// prefixRangeEnd returns the end of the etcd key range of all keys with the given prefix, the prefix with its last
+// byte incremented, or the zero byte for all keys when the prefix is empty.
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
+
+// HTTPKV is a KVFetcher reading variables from an HTTP endpoint serving a JSON object, e.g. {"PORT": 8080}. Values
+// that are not strings are used as their JSON encoding.
+type HTTPKV struct {
+	// URL is the URL of the endpoint.
+	URL string
+	// Header is added to every request, e.g. for an Authorization header.
+	Header http.Header
+	// Client is the HTTP client used for requests, defaults to a client with a 10 second timeout.
+	Client *http.Client
+}
+
+// FetchKV reads the variables from the endpoint.
+func (h *HTTPKV) FetchKV(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range h.Header {
+		req.Header[key] = values
+	}
+	var object map[string]json.RawMessage
+	_, err = doKVRequest(kvClient(h.Client), req, "http", false, &object)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for key, raw := range object {
+		var str string
+		if json.Unmarshal(raw, &str) == nil {
+			values[key] = str
+			continue
+		}
+		values[key] = string(raw)
+	}
+	return values, nil
+}
+
+// kvClient returns the given HTTP client, or a client with a 10 second timeout if nil.
+func kvClient(client *http.Client) *http.Client {
+	if client == nil {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	return client
+}
+
+// doKVRequest sends a request to a key/value store and decodes the JSON response. It reports false without error for
+// a not found status when notFoundEmpty is set, for stores that answer it when there are no keys at the path.
+func doKVRequest(client *http.Client, req *http.Request, store string, notFoundEmpty bool, response interface{}) (bool, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && notFoundEmpty {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%s returned status %d for %s", store, resp.StatusCode, req.URL.Path)
+	}
+	err = json.NewDecoder(resp.Body).Decode(response)
+	if err != nil {
+		return false, fmt.Errorf("error decoding %s response: %w", store, err)
+	}
+	return true, nil
+}
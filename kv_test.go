@@ -0,0 +1,132 @@
+package goloadenv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKVSources(t *testing.T) {
+	clearTestEnv()
+
+	var consulRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v1/kv/myapp/":
+			consulRequests.Add(1)
+			if r.URL.Query().Get("recurse") != "true" || r.Header.Get("X-Consul-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"Key":"myapp/","Value":null},{"Key":"myapp/PORT","Value":"ODA4MA=="},{"Key":"myapp/DB/HOST","Value":"ZGI="}]`))
+		case "/v1/kv/my%20app/":
+			_, _ = w.Write([]byte(`[{"Key":"my app/HOST","Value":"ZXNjYXBlZA=="}]`))
+		case "/v1/kv/empty/":
+			w.WriteHeader(http.StatusNotFound)
+		case "/v3/kv/range":
+			var body struct {
+				Key      []byte `json:"key"`
+				RangeEnd []byte `json:"range_end"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if string(body.Key) != "/myapp/" || string(body.RangeEnd) != "/myapp0" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"kvs":[{"key":"L215YXBwL0xFVkVM","value":"ZGVidWc="}]}`))
+		case "/config.json":
+			_, _ = w.Write([]byte(`{"WORKERS": 4, "NAME": "api"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	consul := NewKVSource(&ConsulKV{Address: server.URL, Token: "token", Prefix: "myapp/"}, time.Hour)
+	someStruct := struct {
+		Port    int    `env:"PORT"`
+		DBHost  string `env:"DB_HOST"`
+		Level   string `env:"LEVEL"`
+		Workers int    `env:"WORKERS"`
+		Name    string `env:"NAME"`
+	}{}
+	opts := WithSources(consul, NewKVSource(&EtcdKV{Address: server.URL, Prefix: "/myapp/"}, 0), NewKVSource(&HTTPKV{URL: server.URL + "/config.json"}, 0))
+	err := LoadEnvWithOptions(&someStruct, opts)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Port != 8080 || someStruct.DBHost != "db" || someStruct.Level != "debug" || someStruct.Workers != 4 || someStruct.Name != "api" {
+		t.Errorf("Expected PORT=8080, DB_HOST=db, LEVEL=debug, WORKERS=4 and NAME=api, got %+v", someStruct)
+	}
+	err = LoadEnvWithOptions(&someStruct, opts)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if consulRequests.Load() != 1 {
+		t.Errorf("Expected the cached variables to be used, got %d requests", consulRequests.Load())
+	}
+
+	if kv := newLoader(WithSources(consul), WithSecretSources(consul)).kvSources; len(kv) != 1 {
+		t.Errorf("Expected a source given twice to be refreshed once, got %d sources", len(kv))
+	}
+
+	escaped, err := (&ConsulKV{Address: server.URL, Prefix: "my app/"}).FetchKV(context.Background())
+	if err != nil || len(escaped) != 1 || escaped["HOST"] != "escaped" {
+		t.Errorf("Expected HOST=escaped, got %v and %v", escaped, err)
+	}
+
+	empty, err := (&ConsulKV{Address: server.URL, Prefix: "empty/"}).FetchKV(context.Background())
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Expected no variables, got %v and %v", empty, err)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(NewKVSource(&HTTPKV{URL: server.URL + "/missing"}, 0)))
+	expected := "error reading key/value source: http returned status 500 for /missing"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestKVSourceWatch(t *testing.T) {
+	var value atomic.Value
+	value.Store("1")
+	source := NewKVSource(KVFetcherFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"VERSION": value.Load().(string)}, nil
+	}), 0)
+	if _, found := source.Lookup("VERSION"); found {
+		t.Errorf("Expected no variables before the first refresh")
+	}
+	_, err := source.Refresh(context.Background())
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if version, _ := source.Lookup("VERSION"); version != "1" {
+		t.Errorf("Expected VERSION=1, got %s", version)
+	}
+
+	value.Store("2")
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{})
+	go func() {
+		_ = source.Watch(ctx, time.Millisecond, func() {
+			cancel()
+			close(changed)
+		}, nil)
+	}()
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a change, got none")
+	}
+	if keys := source.keys(); !reflect.DeepEqual(keys, []string{"VERSION"}) {
+		t.Errorf("Expected [VERSION], got %v", keys)
+	}
+	if version, _ := source.Lookup("VERSION"); version != "2" {
+		t.Errorf("Expected VERSION=2, got %s", version)
+	}
+}
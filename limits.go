@@ -0,0 +1,62 @@
+package goloadenv
+
+import (
+	"fmt"
+)
+
+// LimitError is returned when a config struct or a value exceeds one of the limits set with WithMaxDepth,
+// WithMaxFields or WithMaxValueSize, or an indexed slice field has more elements than can be loaded.
+type LimitError struct {
+	// Limit is the name of the exceeded limit: "depth", "fields", "value size" or "elements".
+	Limit string
+	// Max is the configured maximum.
+	Max int
+	// Where is the path of the struct or the name of the environment variable that exceeded the limit.
+	Where string
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("limit exceeded: %s of %s exceeds the maximum of %d", e.Limit, e.Where, e.Max)
+}
+
+// limits holds the caps of a load, a zero value means unlimited.
+type limits struct {
+	maxDepth     int
+	maxFields    int
+	maxValueSize int
+	// depth is the nesting depth of the struct being loaded.
+	depth int
+	// fields is the number of tagged fields loaded so far.
+	fields int
+}
+
+// enterStruct descends into a nested struct at the given path, checking the depth limit.
+func (c *limits) enterStruct(path string) error {
+	c.depth++
+	if c.maxDepth > 0 && c.depth > c.maxDepth {
+		return &LimitError{Limit: "depth", Max: c.maxDepth, Where: path}
+	}
+	return nil
+}
+
+// leaveStruct returns from a nested struct.
+func (c *limits) leaveStruct() {
+	c.depth--
+}
+
+// countField counts a tagged field, checking the field count limit.
+func (c *limits) countField(env string) error {
+	c.fields++
+	if c.maxFields > 0 && c.fields > c.maxFields {
+		return &LimitError{Limit: "fields", Max: c.maxFields, Where: env}
+	}
+	return nil
+}
+
+// checkValue checks the size of the raw value of a variable against the value size limit.
+func (c *limits) checkValue(env string, value string) error {
+	if c.maxValueSize > 0 && len(value) > c.maxValueSize {
+		return &LimitError{Limit: "value size", Max: c.maxValueSize, Where: env}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package goloadenv
+
+import (
+	"errors"
+	"strings"
+)
+
+// ParseList parses a list written as comma separated elements in brackets, e.g. [a,b,c], into its elements. The empty
+// list [] has no elements. See SplitList for quoting and escaping elements.
+func ParseList(str string) ([]string, error) {
+	return parseList(str, false)
+}
+
+// parseList parses a list in brackets like ParseList, trimming the whitespace around unquoted elements if trim is set.
+func parseList(str string, trim bool) ([]string, error) {
+	if len(str) < 2 || str[0] != '[' || str[len(str)-1] != ']' {
+		return nil, errors.New("invalid array format")
+	}
+	str = str[1 : len(str)-1]
+	if str == "" {
+		return []string{}, nil
+	}
+	return splitList(str, ",", trim)
+}
+
+// SplitList splits a list on the given separator. An element can be double quoted, e.g. "a,b", or single quoted to
+// contain the separator, with whitespace around the quotes ignored. Within double quotes \" and \\ are escapes. Outside
+// of quotes a backslash escapes the separator, a backslash or a quote, and separators within nested brackets or braces,
+// e.g. [a,[b,c]] or [{"a":1,"b":2}], do not split the element. An empty string has no elements.
+func SplitList(str string, sep string) ([]string, error) {
+	return splitList(str, sep, false)
+}
+
+// splitList splits a list like SplitList, trimming the whitespace around unquoted elements if trim is set.
+func splitList(str string, sep string, trim bool) ([]string, error) {
+	if sep == "" {
+		return nil, errors.New("empty list separator")
+	}
+	if str == "" {
+		return nil, nil
+	}
+	var elements []string
+	var element strings.Builder
+	appendElement := func(quoted bool) {
+		if trim && !quoted {
+			elements = append(elements, strings.TrimSpace(element.String()))
+			return
+		}
+		elements = append(elements, element.String())
+	}
+	// quoted is set after a quoted element, only whitespace may follow until the next separator
+	quoted := false
+	depth := 0
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case depth == 0 && strings.HasPrefix(str[i:], sep):
+			appendElement(quoted)
+			element.Reset()
+			quoted = false
+			i += len(sep)
+		case quoted:
+			if c != ' ' && c != '\t' {
+				return nil, errors.New("unexpected characters after quoted list element")
+			}
+			i++
+		case c == '\\' && i+1 < len(str) && strings.HasPrefix(str[i+1:], sep):
+			element.WriteString(sep)
+			i += 1 + len(sep)
+		case c == '\\' && i+1 < len(str) && strings.IndexByte(`\"'`, str[i+1]) >= 0:
+			element.WriteByte(str[i+1])
+			i += 2
+		case (c == '"' || c == '\'') && depth == 0 && strings.TrimSpace(element.String()) == "":
+			value, end, err := quotedListElement(str[i:])
+			if err != nil {
+				return nil, err
+			}
+			element.Reset()
+			element.WriteString(value)
+			quoted = true
+			i += end
+		case c == '[' || c == '{':
+			depth++
+			element.WriteByte(c)
+			i++
+		case (c == ']' || c == '}') && depth > 0:
+			depth--
+			element.WriteByte(c)
+			i++
+		default:
+			element.WriteByte(c)
+			i++
+		}
+	}
+	if depth > 0 {
+		return nil, errors.New("unbalanced brackets in list element")
+	}
+	appendElement(quoted)
+	return elements, nil
+}
+
+// quotedListElement parses the quoted list element at the start of str, returning its value and the index after the
+// closing quote.
+func quotedListElement(str string) (string, int, error) {
+	quote := str[0]
+	var value strings.Builder
+	for i := 1; i < len(str); i++ {
+		switch {
+		case str[i] == quote:
+			return value.String(), i + 1, nil
+		case quote == '"' && str[i] == '\\' && i+1 < len(str) && (str[i+1] == '"' || str[i+1] == '\\'):
+			value.WriteByte(str[i+1])
+			i++
+		default:
+			value.WriteByte(str[i])
+		}
+	}
+	return "", 0, errors.New("unterminated quoted list element")
+}
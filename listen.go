@@ -0,0 +1,69 @@
+package goloadenv
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// unixScheme is the prefix of a ListenAddr for a unix socket.
+const unixScheme = "unix://"
+
+// ListenAddr is an address to listen on, parsed from values like LISTEN=:8080, LISTEN=0.0.0.0:0 or
+// LISTEN=unix:///var/run/app.sock. TCP addresses need a port in the range 0-65535, where port 0 picks a free port.
+// Pass Network() and Address() to net.Listen.
+type ListenAddr struct {
+	// Net is the network, "tcp" or "unix".
+	Net string
+	// Addr is host:port for tcp, or the socket path for unix.
+	Addr string
+}
+
+// UnmarshalText parses a listen address.
+func (a *ListenAddr) UnmarshalText(text []byte) error {
+	str := string(text)
+	if path, isUnix := strings.CutPrefix(str, unixScheme); isUnix {
+		if path == "" {
+			return fmt.Errorf("invalid listen address '%s', missing socket path", str)
+		}
+		*a = ListenAddr{Net: "unix", Addr: path}
+		return nil
+	}
+	host, port, err := net.SplitHostPort(str)
+	if err != nil {
+		return fmt.Errorf("invalid listen address '%s', expected [host]:port or unix://path", str)
+	}
+	number, err := strconv.Atoi(port)
+	if err != nil || number < 0 || number > 65535 {
+		return fmt.Errorf("invalid listen address port '%s', expected 0-65535", port)
+	}
+	if strings.ContainsAny(host, " /") {
+		return fmt.Errorf("invalid listen address host '%s'", host)
+	}
+	*a = ListenAddr{Net: "tcp", Addr: net.JoinHostPort(host, strconv.Itoa(number))}
+	return nil
+}
+
+// Network returns the network to pass to net.Listen.
+func (a ListenAddr) Network() string {
+	return a.Net
+}
+
+// Address returns the address to pass to net.Listen.
+func (a ListenAddr) Address() string {
+	return a.Addr
+}
+
+// String formats the listen address back into its specification.
+func (a ListenAddr) String() string {
+	if a.Net == "unix" {
+		return unixScheme + a.Addr
+	}
+	return a.Addr
+}
+
+// MarshalText formats the listen address for printing.
+func (a ListenAddr) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
@@ -1,40 +1,293 @@
 package goloadenv
 
 import (
+	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"reflect"
 	"strings"
+	"time"
 )
 
 const (
-	tagName = "env"
+	tagName       = "env"
+	docsTagName   = "docs"
+	prefixTagName = "envPrefix"
 )
 
 // EnvNotFoundError represents an error when an expected environment variable is not found.
 type EnvNotFoundError struct {
 	Env string
+	// Path is the dotted path of the struct field of the variable, e.g. DB.Host.
+	Path string
+	// Type is the Go type of the field.
+	Type reflect.Type
+	// Docs links to the documentation of the variable, taken from the docs struct tag.
+	Docs string
+	// Condition is the requiredif condition that made the variable required, if any.
+	Condition string
+	// ConditionValue is the value of the variable of the condition, masked when that variable is secret.
+	ConditionValue string
 }
 
 // Error returns a string representation of the EnvNotFoundError.
 func (e *EnvNotFoundError) Error() string {
-	return fmt.Sprintf("environment variable not found: %s", e.Env)
+	message := fmt.Sprintf("environment variable not found: %s", e.Env)
+	if e.Condition != "" {
+		name, _, _ := strings.Cut(e.Condition, "=")
+		message += fmt.Sprintf(", required when %s, got %s='%s'", e.Condition, name, e.ConditionValue)
+	}
+	if e.Docs != "" {
+		message += fmt.Sprintf(" (see %s)", e.Docs)
+	}
+	return message
+}
+
+// Is reports whether the target is an EnvNotFoundError for the same variable, so errors.Is(err,
+// &goloadenv.EnvNotFoundError{Env: "HOST"}) checks whether HOST is missing. A target without Env matches any variable.
+func (e *EnvNotFoundError) Is(target error) bool {
+	t, ok := target.(*EnvNotFoundError)
+	return ok && (t.Env == "" || t.Env == e.Env)
+}
+
+// ShadowMismatchError represents a conflict between the new and old name of a migrating environment variable, both
+// being set with different values.
+type ShadowMismatchError struct {
+	Env    string
+	Shadow string
+}
+
+// Error returns a string representation of the ShadowMismatchError.
+func (e *ShadowMismatchError) Error() string {
+	return fmt.Sprintf("environment variables %s and %s are both set with different values", e.Env, e.Shadow)
+}
+
+// WarningBudgetError is returned when a load raises more warnings than allowed by WithMaxWarnings.
+type WarningBudgetError struct {
+	// Max is the configured maximum number of warnings.
+	Max int
+	// Warnings are the warnings raised by the load.
+	Warnings []error
+}
+
+// Error returns a string representation of the WarningBudgetError.
+func (e *WarningBudgetError) Error() string {
+	return fmt.Sprintf("load raised %d warnings, more than the maximum of %d: %s", len(e.Warnings), e.Max, errors.Join(e.Warnings...))
+}
+
+func (e *WarningBudgetError) Unwrap() []error {
+	return e.Warnings
 }
 
 type EnvParseError struct {
+	// Path is the dotted path of the struct field of the variable, e.g. DB.Port.
+	Path string
+	// Type is the Go type the value was parsed as.
+	Type  reflect.Type
 	env   string
 	err   error
 	value string
+	hint  string
+	docs  string
+}
+
+// Env returns the name of the environment variable that failed to parse.
+func (e *EnvParseError) Env() string {
+	return e.env
 }
 
 func (e *EnvParseError) Error() string {
-	return fmt.Sprintf("error parsing '%s' as environment variable %s: %s", e.value, e.env, e.err.Error())
+	msg := fmt.Sprintf("error parsing '%s' as environment variable %s: %s", e.value, e.env, e.err.Error())
+	if e.hint != "" {
+		msg += fmt.Sprintf(" (hint: %s)", e.hint)
+	}
+	if e.docs != "" {
+		msg += fmt.Sprintf(" (see %s)", e.docs)
+	}
+	return msg
+}
+
+func (e *EnvParseError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the target is an EnvParseError of the same field, so errors.Is(err,
+// &goloadenv.EnvParseError{Path: "DB.Port"}) checks whether DB.Port failed to parse. A target without Path matches any
+// field.
+func (e *EnvParseError) Is(target error) bool {
+	t, ok := target.(*EnvParseError)
+	return ok && (t.Path == "" || t.Path == e.Path)
+}
+
+// MissingVars returns the names of the missing environment variables reported by an error returned by LoadEnv, in the
+// order they were found, including those of nested structs and of errors joined by WithAllErrors.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithAllErrors())
+//	if missing := goloadenv.MissingVars(err); len(missing) > 0 {
+//	  log.Fatalf("set %s", strings.Join(missing, ", "))
+//	}
+func MissingVars(err error) []string {
+	var missing []string
+	switch e := err.(type) {
+	case nil:
+	case *EnvNotFoundError:
+		missing = append(missing, e.Env)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			missing = append(missing, MissingVars(err)...)
+		}
+	default:
+		missing = MissingVars(errors.Unwrap(err))
+	}
+	return missing
+}
+
+// withField attaches the dotted path and the Go type of a field to the errors that support it.
+// used internally by LoadEnv.
+func withField(err error, path string, typ reflect.Type) error {
+	var notFoundErr *EnvNotFoundError
+	if errors.As(err, &notFoundErr) {
+		notFoundErr.Path, notFoundErr.Type = path, typ
+	}
+	var parseErr *EnvParseError
+	if errors.As(err, &parseErr) {
+		parseErr.Path, parseErr.Type = path, typ
+	}
+	return err
+}
+
+// withDocs attaches the documentation link of a field to the errors that support it.
+// used internally by LoadEnv.
+func withDocs(err error, docs string) error {
+	if docs == "" {
+		return err
+	}
+	var notFoundErr *EnvNotFoundError
+	if errors.As(err, &notFoundErr) {
+		notFoundErr.Docs = docs
+	}
+	var parseErr *EnvParseError
+	if errors.As(err, &parseErr) {
+		parseErr.docs = docs
+	}
+	return err
 }
 
 // LoadEnv loads environment variables into the provided config struct.
 // It uses the "env" struct tag to determine which environment variable corresponds to each field.
 // If an environment variable is not found, and it does not have a default value provided in the tag, it returns an error.
+// Tag options are separated by semicolons and an option value follows the first colon, so values may contain colons,
+// e.g. env:"URL;default:https://example.com:8080/path". A value containing a semicolon is single quoted, e.g.
+// default:'a;b', or escaped with a backslash.
+// Tags can also be written as comma separated key=value pairs, starting with the variable name, e.g.
+// env:"name=DB_URL,default='postgres://u:p@h:5432/db',optional". A value containing a comma is single quoted. Both
+// syntaxes support the same options.
+// The required flag marks a field as required explicitly, which matters when the WithAllOptional option makes fields
+// optional by default. The requiredif option makes a field required only when another variable has a given value,
+// e.g. env:"TLS_CERT_FILE;requiredif:TLS_ENABLED=true", or is set at all, e.g. requiredif:TLS_ENABLED, and optional
+// otherwise. The variable of the condition takes the prefix of the field, and is read from the field loading it when
+// that field is loaded earlier, including its default value. Boolean values match regardless of how they are written.
+// The error of a missing conditional variable includes the value of the variable of the condition, masked when it is
+// secret, e.g. "required when TLS_ENABLED=true, got TLS_ENABLED='yes'".
+// An environment variable that is set to the empty string, like SOMETHING=, is found and sets the field to its zero
+// value, which is validated like any other value, so PORT= fails min:1. The allowempty flag treats such a variable as
+// unset instead, so its default value applies.
+// A nested struct field can carry an envPrefix struct tag, e.g. envPrefix:"DB_", which is prepended to the environment
+// variable names of all its fields, so the same struct type can be reused under different prefixes. Embedded structs
+// are loaded the same way, and a nil embedded pointer to a struct is allocated before its fields are loaded.
+// A default value can be computed from an arithmetic expression, e.g. env:"BUFFER;default:expr:4*1024*1024" or
+// env:"WORKERS;default:expr:runtime.NumCPU()*2".
+// Slice and array fields are written in brackets, e.g. HOSTS=[a.example.com,b.example.com], or split on the separator
+// of the sep option without brackets, e.g. env:"HOSTS;sep:," reads HOSTS=a.example.com,b.example.com. Elements can be
+// quoted or escaped to contain the separator, see SplitList. The trim flag removes the whitespace around unquoted
+// elements, e.g. env:"CORS_ORIGINS;sep:|;trim" reads CORS_ORIGINS=https://a.com | https://b.com as two clean URLs.
+// An array field given fewer elements than its length leaves the remaining elements untouched, unless the pad option
+// rejects the value with pad:error, sets them to their zero value with pad:zero, or repeats the last element with
+// pad:repeat, e.g. env:"WEIGHTS;default:[1];pad:repeat" for a [4]int reads WEIGHTS=[3,2] as [3,2,2,2].
+// A map, struct or slice field can be decoded from a JSON or YAML document with the format option, e.g.
+// env:"FEATURES;format:json" reads FEATURES={"a":true,"b":false}. Other formats are passed to the parser of the type,
+// e.g. env:"START_AT;format:2006-01-02" for a time.Time or env:"TTL;format:seconds" for a time.Duration, see
+// RegisterFormattedEnvType. YAML documents are limited to block mappings and sequences, scalars, flow collections
+// written as JSON and comments.
+// A []byte field can be decoded from a padded base64 or base64url value, or a hex value, with the encoding option, e.g.
+// env:"HMAC_KEY;secret;encoding:base64". Without it a []byte is parsed as a list of numbers like any other slice.
+// A field with the file flag reads its value from the file named by the variable with the _FILE suffix when the
+// variable itself is not set, the Docker and Kubernetes convention for secrets mounted as files, e.g.
+// env:"DB_PASSWORD;secret;file" reads DB_PASSWORD_FILE=/run/secrets/db_password. Trailing newlines are removed. The
+// WithFileFallback option enables this for all fields.
+// The secretref option resolves a field from an external secret store when its variable is not set, e.g.
+// env:"DB_PASSWORD;secret;secretref:vault://secret/data/db#password", with the resolver registered for the scheme
+// using the WithSecretResolver option. Secret fields can also be read from a desktop keyring, see WithSecretSources.
+// The value of a field with the encrypted flag is written as enc:NAME:CIPHERTEXT, e.g. DB_PASSWORD=enc:v1:c2VhbGVk, and
+// decrypted with the decryptor registered under the name, see RegisterDecryptor. Encrypted fields are secret.
+// Booleans accept true/false, yes/no, on/off, 1/0 and enabled/disabled case-insensitively. The strictbool flag, or the
+// WithStrictBool option for all fields, restricts them to true and false.
+// A TimeWindow field reads a daily window like MAINTENANCE_WINDOW=02:00-04:00, optionally restricted to days, e.g.
+// Mon-Fri 09:00-17:00, in the time zone named after it or by the tz option, e.g.
+// env:"MAINTENANCE_WINDOW;tz:Europe/Paris".
+// Integers are decimal unless written with a 0x, 0o or 0b prefix, e.g. FLAGS_MASK=0xFF. The base option parses them in
+// another base, with or without its prefix, e.g. env:"FLAGS_MASK;base:16" reads both FLAGS_MASK=FF and 0xFF.
+// Parsed values can be validated with the min and max options for numbers, e.g. env:"PORT;min:1;max:65535", and
+// durations, e.g. env:"TIMEOUT;min:1s;max:10m", where a bare number is in nanoseconds, the after and before options for
+// times, with RFC 3339 bounds, e.g. env:"CUTOFF;after:2024-01-01T00:00:00Z", the oneof option for a fixed set of
+// values, e.g. env:"LOG_LEVEL;oneof:debug,info,warn,error", and the regex option for strings, e.g.
+// env:"EMAIL;regex:^.+@.+$". The email flag requires a string, or every string of a slice, to be an email address and
+// canonicalizes it, e.g. env:"ALERT_RECIPIENTS;email". Likewise the iso3166, iso4217 and bcp47 flags require a country
+// code, a currency code or a language tag, e.g. env:"CURRENCY;iso4217", and the hostport flag a host:port address, e.g.
+// env:"BIND_ADDR;hostport", which a HostPort field also decomposes. The minlen and maxlen options bound the length of
+// strings, in characters, and of slices and maps, e.g. env:"HOSTS;minlen:1;maxlen:5", and the notempty flag rejects
+// empty values, also variables set to the empty string. Violations are reported as a ValidationError.
+// The tier option governs how strictly a field is enforced: a field with tier:critical fails the load when it is
+// missing or invalid, as any field without a tier, while tier:important falls back to its default value, or its zero
+// value, with a warning, and tier:nice does so silently.
+// Fields of interface types are populated by the factory registered with RegisterFactory under the name given by their
+// variable, and the fields of the implementation are loaded as a nested struct.
+// Fields tagged env:"-", including nested structs, are skipped by loading, printing and every other function walking
+// the config struct, so a config struct can hold runtime state.
+// The group option assigns a field to a named group, e.g. env:"DB_HOST;group:database", so LoadEnvGroup and
+// Watcher.ReloadGroup can load only that part of the config. Groups do not affect LoadEnv, which loads every field.
+// The prefixmap flag collects every variable starting with the name of a map field into the map, keyed by the rest of
+// the name, e.g. env:"FEATURE_;prefixmap" loads FEATURE_DARK_MODE=on into a map[string]string as DARK_MODE: on.
+// The values of a prefix map can also be structs, loaded per key like nested structs, e.g. env:"TENANT_;prefixmap" on a
+// map[string]TenantConfig loads TENANT_ACME_HOST and TENANT_ACME_PORT into the entry ACME.
+// Fields of enum types can map names to their typed constants with the enum option, e.g.
+// env:"MODE;enum:dev=0,staging=1,prod=2" loads prod as Mode(2), and env:"MODE;enum:dev,staging,prod" restricts a
+// string-backed type to its members. Values that are not a member fail the load.
+// Secret material in string and []byte fields can be required to have a minimum length in bytes and a minimum
+// estimated entropy in bits, e.g. env:"JWT_SECRET;secret;minBytes:32;minEntropy:128". A []byte field with the encoding
+// option is measured after decoding, e.g. env:"HMAC_KEY;secret;encoding:base64;minBytes:32" needs 32 decoded bytes.
+// Values and default values of fields with the expand flag, or of all fields with the WithExpand option, can reference
+// other environment variables as $VAR or ${VAR}, e.g. env:"DATA_DIR;expand;default:${HOME}/data", with $$ for a
+// literal $. Default values can always reference the built-in pseudo variables $NUMCPU, $GOMAXPROCS, $HOSTNAME and
+// $PID, also in expressions, e.g. env:"WORKERS;default:expr:$NUMCPU*2", with $$ for a literal $, other references
+// are kept as is without the expand flag. Braced references can pipe their value through the functions default, trim,
+// upper, lower, b64dec and join, e.g. ${REGION|default:eu-west-1|upper} or ${HOSTS|join: }.
+// The path flag marks a string field, or every string of a slice, as a filesystem path: variable references are
+// expanded, a leading ~ is replaced by the home directory of the user and the path is cleaned, with / converted to the
+// separator of the platform, e.g. env:"DATA_DIR;path;default:~/data". The mustexist flag also requires the path to
+// exist.
+// Defaults can be computed at load time by a function registered with RegisterDefaultFunc, e.g.
+// env:"NODE_NAME;default:fn:hostname", or rendered as a text/template from the sibling fields, which are loaded first,
+// e.g. env:"ADDR;default:tmpl:{{.Host}}:8080". Templates are only rendered when the field falls back to its default.
+// A field that is migrating to a new variable name can declare its old name, e.g. env:"DB_HOST;shadow:DATABASE_HOST",
+// to fall back to the old name when the new one is not set. Both being set with different values is logged as a
+// warning, or fails the load in strict mode.
+// A field can be read from a chain of variable names, e.g. env:"DATABASE_URL|DB_URL|POSTGRES_URL", where the first name
+// that is set wins, for platforms that each set a differently named variable for the same thing. The fallback option
+// lists the names after the first one the same way, e.g. env:"DATABASE_URL;fallback:DB_URL,POSTGRES_URL".
+// A renamed variable can also keep any number of old names with the alias option, e.g.
+// env:"DB_HOST;alias:DATABASE_HOST,PGHOST", which are read in order when the new name is not set and raise a
+// DeprecationWarning when used. The deprecated option adds a message to the warning, e.g. deprecated:'use DB_HOST',
+// or deprecates the variable of a field without aliases itself.
+// A config struct, or any of its nested structs, can implement PostLoader to derive values once its fields are loaded,
+// and Validator to validate its fields together.
+// A field can link to its documentation with a docs struct tag, e.g. docs:"https://wiki/runbooks/db", which is included
+// in the errors for that field.
 //
 // Example:
 //
@@ -46,9 +299,10 @@ func (e *EnvParseError) Error() string {
 //	}
 //
 //	type Config struct {
-//	  Port     float64 `env:"PORT;default:8080"`
-//	  LogLevel string  `env:"LOG_LEVEL;optional"`
+//	  Port     float64  `env:"PORT;default:8080"`
+//	  LogLevel string   `env:"LOG_LEVEL;optional"`
 //	  DB       DBConfig
+//	  Replica  DBConfig `envPrefix:"REPLICA_"`
 //	}
 //
 //	func LoadConfig() error {
@@ -62,9 +316,10 @@ func (e *EnvParseError) Error() string {
 //	}
 //
 //	func main() {
-//	  err := godotenv.Load(".env")
+//	  err := config.LoadDotEnv(".env")
 //	  if err != nil {
-//	    	return cfg, fmt.Errorf("could not load environment files: %w", err)
+//	    fmt.Printf("Error loading environment files: %v\n", err)
+//	    return
 //	  }
 //	  err := LoadConfig()
 //	  if err != nil {
@@ -73,22 +328,497 @@ func (e *EnvParseError) Error() string {
 //	  }
 //	}
 //
-// TODO: allow for format string defaults, function return defaults?
+// LoadWithDotenv combines both steps without modifying the process environment.
+//
+// LoadEnv and the other load functions are safe for concurrent use, also with the registration functions such as
+// RegisterTypedEnvType, RegisterRenderer and RegisterDefaultFunc, as long as each call loads into its own config
+// struct.
 func LoadEnv(config interface{}) error {
+	return LoadEnvWithOptions(config)
+}
+
+// LoadEnvWithOptions loads environment variables into the provided config struct like LoadEnv, with its behavior
+// customized by the given options.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithPrefix("APP_"), goloadenv.WithStrictMode())
+func LoadEnvWithOptions(config interface{}, opts ...Option) error {
+	return newLoader(opts...).load(config)
+}
+
+// Load allocates a config struct of type T, loads environment variables into it like LoadEnv and returns it by value,
+// so the config needs neither a variable declared upfront nor a pointer. The metadata derived from the struct tags of
+// T is parsed on its first load and cached for later loads. On error the zero value of T is returned.
+//
+// Example:
+//
+//	cfg, err := goloadenv.Load[Config]()
+func Load[T any]() (T, error) {
+	return LoadWith[T]()
+}
+
+// LoadWith loads a config struct of type T like Load, with its behavior customized by the given options as in
+// LoadEnvWithOptions.
+//
+// Example:
+//
+//	cfg, err := goloadenv.LoadWith[Config](goloadenv.WithPrefix("APP_"))
+func LoadWith[T any](opts ...Option) (T, error) {
+	var config T
+	if typ := reflect.TypeFor[T](); typ.Kind() != reflect.Struct {
+		return config, fmt.Errorf("config type %s must be a struct", typ)
+	}
+	err := LoadEnvWithOptions(&config, opts...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return config, nil
+}
+
+// LoadEnvFromMap loads the variables of the given map into the provided config struct like LoadEnvWithOptions, instead
+// of the process environment, which is neither read nor modified. It makes table-driven tests independent of the
+// environment of the test process.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvFromMap(&cfg, map[string]string{"PORT": "8080"})
+func LoadEnvFromMap(config interface{}, env map[string]string, opts ...Option) error {
+	return LoadEnvWithOptions(config, append([]Option{WithSources(MapSource(env))}, opts...)...)
+}
+
+// LoadEnvContext loads environment variables into the provided config struct like LoadEnvWithOptions, bounded by the
+// given context: the load fails with the error of the context once it is cancelled or its deadline passes, which is
+// checked before every field, and the context is passed to secret resolvers implementing ContextSecretResolver, such
+// as VaultResolver, so slow remote stores are interrupted. WithSoftDeadline returns a partial config before the
+// deadline instead.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	err := goloadenv.LoadEnvContext(ctx, &cfg, goloadenv.WithSecretResolver("vault", &goloadenv.VaultResolver{}))
+func LoadEnvContext(ctx context.Context, config interface{}, opts ...Option) error {
+	l := newLoader(opts...)
+	l.ctx = ctx
+	return l.load(config)
+}
+
+// LoadEnvLayered loads environment variables into the provided config struct like LoadEnvWithOptions, from the layered
+// environments of the given prefixes, see WithLayerPrefixes.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvLayered([]string{"", "STAGING_"}, &cfg)
+func LoadEnvLayered(prefixes []string, config interface{}, opts ...Option) error {
+	return LoadEnvWithOptions(config, append([]Option{WithLayerPrefixes(prefixes...)}, opts...)...)
+}
+
+// LoadEnvAll loads environment variables into the provided config struct like LoadEnv, but does not stop at the first
+// missing or unparseable variable. Every problem is collected and returned as a single joined error, so all missing
+// configuration can be fixed at once.
+func LoadEnvAll(config interface{}) error {
+	return LoadEnvWithOptions(config, WithAllErrors())
+}
+
+// LoadEnvReport loads environment variables into the provided config struct like LoadEnvWithOptions, and returns a
+// report of every tagged field: the variable consulted, whether the value came from the environment or a default or
+// was left unset, and the final value with secrets masked. The report is also returned when the load fails, covering
+// the fields loaded up to the failure.
+func LoadEnvReport(config interface{}, opts ...Option) (*Report, error) {
+	l := newLoader(opts...)
+	l.report = &Report{}
+	err := l.load(config)
+	l.report.Warnings = l.warnings
+	return l.report, err
+}
+
+// LoadEnvLenient loads environment variables into the provided config struct like LoadEnv, but a value that cannot be
+// parsed into an optional field does not abort the load. The field is set to its default value instead, or left at
+// its zero value when it has none, and the parse error is returned as a warning, so non-critical tunables cannot take
+// a service down. Parse errors on required fields and missing required environment variables still fail the load. It
+// is shorthand for a load with WithLenient that returns the warnings.
+func LoadEnvLenient(config interface{}) ([]error, error) {
+	l := newLoader(WithLenient(nil))
+	err := l.load(config)
+	return l.warnings, err
+}
+
+// requirement is the project wide policy for whether fields are required.
+type requirement int
+
+const (
+	// implicitRequired requires fields unless they have the optional flag.
+	implicitRequired requirement = iota
+	// allRequired requires every field, ignoring the optional flag.
+	allRequired
+	// allOptional only requires fields with the required flag.
+	allOptional
+)
+
+// loader holds the settings and state of a single load.
+type loader struct {
+	// tagName is the struct tag holding the field options.
+	tagName string
+	// prefix is prepended to every environment variable name.
+	prefix string
+	// lookup looks up the value of an environment variable.
+	lookup func(string) (string, bool)
+	// access records the looked up variable names, if set.
+	access *AccessLog
+	// dotEnv holds the .env files to read variables from, if set.
+	dotEnv []string
+	// dotEnvOverride gives the .env files precedence over the lookup.
+	dotEnvOverride bool
+	// dotEnvOptional skips .env files that do not exist.
+	dotEnvOptional bool
+	// userEnvApp is the application whose per-user .env file is read at the lowest precedence, if set.
+	userEnvApp string
+	// strict rejects unknown tag options.
+	strict bool
+	// expand expands variable references in every value.
+	expand bool
+	// strictBool only accepts true and false for every boolean.
+	strictBool bool
+	// requirement overrides whether fields are required.
+	requirement requirement
+	// lenient downgrades parse errors on optional fields to warnings.
+	lenient bool
+	// logger logs the warnings, if set.
+	logger *slog.Logger
+	// names holds the environment variable names used so far, to detect duplicates.
+	names map[string]struct{}
+	// collect collects all errors instead of stopping at the first one.
+	collect bool
+	// report records the outcome of every field, if set.
+	report *Report
+	// warnings collects the problems that did not abort the load.
+	warnings []error
+	// errs collects the errors that failed the load when collect is set.
+	errs []error
+	// fileFallback reads every field from the file named by its *_FILE variable when it is not set.
+	fileFallback bool
+	// secretSources are the sources consulted for secret fields that are not found by the lookup, if set.
+	secretSources EnvSource
+	// resolvers resolve the secretref option by scheme.
+	resolvers map[string]SecretResolver
+	// resolverTimeouts bounds the resolvers by scheme.
+	resolverTimeouts map[string]*resolverTimeout
+	// softDeadline is the margin before the deadline of the context from which secret references are left unresolved.
+	softDeadline time.Duration
+	// unresolved holds the secret references left unresolved by the soft deadline.
+	unresolved []UnresolvedField
+	// limits caps the size of the config struct and its values.
+	limits limits
+	// derivedNames derives the variable name of untagged fields from their field name.
+	derivedNames bool
+	// caseInsensitive matches variable names regardless of case.
+	caseInsensitive bool
+	// maxWarnings fails the load when it raises more warnings, -1 tolerates any number.
+	maxWarnings int
+	// flags binds the fields to command-line flags.
+	flags bool
+	// flagSet is the flag set to define the flags on, a new flag set is used if nil.
+	flagSet *flag.FlagSet
+	// flagArgs are the command-line arguments to parse the flags from.
+	flagArgs []string
+	// flagValues holds the values of the flags given on the command line by environment variable name.
+	flagValues map[string]string
+	// configFile is the JSON or YAML file to read the fields with a file tag from, if set.
+	configFile string
+	// configValues holds the values read from the config file by environment variable name.
+	configValues map[string]string
+	// fromConfigFile holds the environment variable names whose value was read from the config file.
+	fromConfigFile map[string]bool
+	// reportFile is the path to write the JSON report of every load to, if set.
+	reportFile string
+	// ctx bounds the load, it is checked before every field and passed to resolvers supporting it.
+	ctx context.Context
+	// examples loads the example values of the fields instead of the environment.
+	examples bool
+	// defaultsOnly only sets the default values of the fields, without hooks.
+	defaultsOnly bool
+	// layerPrefixes are the prefixes of the layered environments, from the base to the most specific, if set.
+	layerPrefixes []string
+	// hooks receive the events of the load.
+	hooks []Hook
+	// sourceKeys holds the variable names of the sources, the candidates for case-insensitive matching.
+	sourceKeys [][]string
+	// listProcessEnv lists the names of the process environment as variables of the load, unless the sources of
+	// WithSources exclude it.
+	listProcessEnv bool
+	// groups restricts the load to the fields in these groups, if set.
+	groups []string
+	// groupFields counts the fields loaded by a load restricted to groups.
+	groupFields int
+	// ignoreUnsupported skips fields whose type cannot be parsed instead of failing.
+	ignoreUnsupported bool
+	// kvSources are the key/value store sources of the load, refreshed before loading.
+	kvSources []*KVSource
+	// rejectUnknown are the prefixes under which variables that do not map to a field fail the load, if set.
+	rejectUnknown []string
+	// tracer traces the load and its calls to remote sources, if set.
+	tracer Tracer
+	// metrics records the statistics of the load and the latency of its calls to remote sources, if set.
+	metrics Metrics
+	// fieldHooks transform the raw value of every field before it is parsed, in registration order.
+	fieldHooks []FieldHook
+	// conditionValues holds the values of the loaded fields by variable name, for the requiredif conditions of later
+	// fields.
+	conditionValues map[string]string
+	// conditionSecrets holds the variable names of the loaded secret fields, whose values are masked in the errors of
+	// the requiredif conditions of later fields.
+	conditionSecrets map[string]struct{}
+	// lookedUp holds the variable names looked up by the load when rejectUnknown is set.
+	lookedUp map[string]struct{}
+	// redactionPolicy marks the fields of the load as secret by name, the default policy unless set by an option.
+	redactionPolicy *RedactionPolicy
+}
+
+func newLoader(opts ...Option) *loader {
+	l := &loader{
+		tagName:          tagName,
+		ctx:              context.Background(),
+		lookup:           os.LookupEnv,
+		names:            map[string]struct{}{},
+		conditionValues:  map[string]string{},
+		conditionSecrets: map[string]struct{}{},
+		maxWarnings:      -1,
+		listProcessEnv:   true,
+		redactionPolicy:  defaultRedactionPolicy(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// prepareLookup layers the .env files, the per-user .env file and the access log around the configured lookup.
+func (l *loader) prepareLookup() error {
+	err := l.refreshKVSources()
+	if err != nil {
+		return err
+	}
+	if l.dotEnv != nil {
+		env, err := readDotEnvFiles(l.dotEnv, l.dotEnvOverride, l.dotEnvOptional)
+		if err != nil {
+			return err
+		}
+		l.sourceKeys = append(l.sourceKeys, mapKeys(env))
+		lookup := l.lookup
+		l.lookup = func(key string) (string, bool) {
+			value, found := env[key]
+			if l.dotEnvOverride && found {
+				return value, true
+			}
+			if processValue, processFound := lookup(key); processFound || !found {
+				return processValue, processFound
+			}
+			return value, true
+		}
+	}
+	if l.userEnvApp != "" {
+		env, err := readUserEnvFile(l.userEnvApp)
+		if err != nil {
+			return err
+		}
+		l.sourceKeys = append(l.sourceKeys, mapKeys(env))
+		lookup := l.lookup
+		l.lookup = func(key string) (string, bool) {
+			if value, found := lookup(key); found {
+				return value, true
+			}
+			value, found := env[key]
+			return value, found
+		}
+	}
+	if len(l.configValues) > 0 {
+		l.fromConfigFile = map[string]bool{}
+		lookup := l.lookup
+		l.lookup = func(key string) (string, bool) {
+			if value, found := lookup(key); found {
+				return value, true
+			}
+			value, found := l.configValues[key]
+			if found {
+				l.fromConfigFile[key] = true
+			}
+			return value, found
+		}
+	}
+	l.recordLookups()
+	if l.caseInsensitive {
+		l.lookup = caseInsensitiveLookup(l.lookup, l.sourceKeys)
+	}
+	if l.layerPrefixes != nil {
+		l.lookup = layeredLookup(l.lookup, l.layerPrefixes)
+	}
+	if len(l.flagValues) > 0 {
+		lookup := l.lookup
+		l.lookup = func(key string) (string, bool) {
+			if value, found := l.flagValues[key]; found {
+				return value, true
+			}
+			return lookup(key)
+		}
+	}
+	if l.access != nil {
+		lookup := l.lookup
+		l.lookup = func(key string) (string, bool) {
+			l.access.record(key)
+			return lookup(key)
+		}
+	}
+	return nil
+}
+
+// warn records a problem that does not abort the load and logs it if a logger is configured.
+func (l *loader) warn(err error) {
+	l.warnings = append(l.warnings, err)
+	if l.logger != nil {
+		l.logger.Warn("goloadenv: " + err.Error())
+	}
+}
+
+func (l *loader) load(config interface{}) error {
+	if l.reportFile == "" && len(l.hooks) == 0 && l.tracer == nil && l.metrics == nil {
+		return l.loadConfig(config)
+	}
+	if l.report == nil {
+		l.report = &Report{}
+	}
+	start := time.Now()
+	var span Span
+	if l.tracer != nil {
+		l.ctx, span = l.tracer.Start(l.ctx, "goloadenv.load")
+	}
+	err := l.loadConfig(config)
+	l.report.Warnings = l.warnings
+	if l.reportFile != "" {
+		err = errors.Join(err, WriteReportFile(l.reportFile, l.report, err))
+	}
+	l.notifyLoad(err)
+	l.observeLoad(start, span, err)
+	return err
+}
+
+func (l *loader) loadConfig(config interface{}) error {
 	if reflect.ValueOf(config).Kind() != reflect.Ptr || reflect.ValueOf(config).Elem().Kind() != reflect.Struct {
 		return errors.New("config must be a pointer to a struct")
 	}
-	val := reflect.ValueOf(config).Elem()
-	for i := 0; i < val.NumField(); i++ {
-		tags, err := getTags(val.Type().Field(i))
+	err := checkCycles(reflect.TypeOf(config).Elem())
+	if err != nil {
+		return err
+	}
+	if l.flags {
+		err = l.parseFlags(reflect.ValueOf(config).Elem())
+		if err != nil {
+			return err
+		}
+	}
+	if l.configFile != "" {
+		err = l.readConfigFileValues(reflect.ValueOf(config).Elem())
+		if err != nil {
+			return err
+		}
+	}
+	err = l.prepareLookup()
+	if err != nil {
+		return err
+	}
+	err = l.loadStruct(reflect.ValueOf(config).Elem(), l.prefix, "")
+	if err != nil {
+		return err
+	}
+	if len(l.groups) > 0 && l.groupFields == 0 {
+		return &GroupError{Groups: l.groups}
+	}
+	err = l.unknownVariables()
+	if err != nil {
+		err = l.fail(err)
+		if err != nil {
+			return err
+		}
+	}
+	if len(l.errs) > 0 {
+		return errors.Join(l.errs...)
+	}
+	if len(l.unresolved) > 0 {
+		return &PartialLoadError{Unresolved: l.unresolved}
+	}
+	if l.maxWarnings >= 0 && len(l.warnings) > l.maxWarnings {
+		return &WarningBudgetError{Max: l.maxWarnings, Warnings: l.warnings}
+	}
+	return nil
+}
+
+// fail records an error that fails the load. It returns the error when the load should stop right away, and nil when
+// all errors are collected instead.
+func (l *loader) fail(err error) error {
+	if !l.collect {
+		return err
+	}
+	l.errs = append(l.errs, err)
+	return nil
+}
+
+// loadStruct loads the fields of a struct, prepending the given prefix to their environment variable names. The path
+// is the dotted path of the struct from the root config struct.
+func (l *loader) loadStruct(val reflect.Value, prefix string, path string) error {
+	meta, err := loadStructMeta(val.Type(), l.tagName, path)
+	if err != nil {
+		return err
+	}
+	marks := make([]loadMark, 0, len(meta.order)+1)
+	for _, i := range meta.order {
+		marks = append(marks, l.mark())
+		// past a soft deadline the fields that need no remote lookup are still loaded
+		if err := l.ctx.Err(); err != nil && (l.softDeadline <= 0 || !errors.Is(err, context.DeadlineExceeded)) {
+			return err
+		}
+		if isSkipped(val.Type().Field(i), l.tagName) {
+			continue
+		}
+		tags, err := meta.fieldTags(i)
+		if err == nil {
+			tags, err = l.getTags(val.Type().Field(i), tags, prefix)
+		}
 		if err != nil {
-			return fmt.Errorf("error getting tags for field: '%s': %w", val.Type().Field(i).Name, err)
+			err = l.fail(fmt.Errorf("error getting tags for field: '%s': %w", joinPath(path, val.Type().Field(i).Name), err))
+			if err != nil {
+				return err
+			}
+			continue
 		}
 		// if the field is a struct, recursively load the nested struct
-		if val.Field(i).Kind() == reflect.Struct {
-			err := LoadEnv(val.Field(i).Addr().Interface())
+		if nested, nestedPath, ok := nestedStruct(val, i, path, true); ok && tags["format"] == "" {
+			err := l.limits.enterStruct(joinPath(path, val.Type().Field(i).Name))
+			if err != nil {
+				return err
+			}
+			err = l.loadStruct(nested, prefix+val.Type().Field(i).Tag.Get(prefixTagName), nestedPath)
+			if err != nil {
+				return err
+			}
+			l.limits.leaveStruct()
+			continue
+		}
+		// if the load is restricted to groups, skip the fields outside of them
+		if !l.inGroups(tags) {
+			continue
+		}
+		if len(l.groups) > 0 {
+			l.groupFields++
+		}
+		// if the field is a slice of structs, load its elements from indexed variables
+		if elemType, ok := indexedSlice(val.Type().Field(i), tags); ok {
+			err := l.loadIndexed(val.Field(i), val.Type().Field(i), elemType, prefix, joinPath(path, val.Type().Field(i).Name))
 			if err != nil {
-				return fmt.Errorf("error loading nested struct '%s': %w", val.Field(i).Type().Field(0).Name, err)
+				err = l.fail(err)
+				if err != nil {
+					return err
+				}
 			}
 			continue
 		}
@@ -96,52 +826,357 @@ func LoadEnv(config interface{}) error {
 		if tags["name"] == "" {
 			continue
 		}
-		str, err := getField(tags)
+		// if the field is a prefix map of structs, load its entries as nested structs
+		if elemType, ok := structPrefixMap(val.Field(i), tags); ok {
+			err := l.loadStructPrefixMap(val.Field(i), elemType, tags, joinPath(path, val.Type().Field(i).Name))
+			if err != nil {
+				err = l.fail(err)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		// if the field cannot be parsed from a string, fail the load or skip it
+		if err := unsupportedField(val.Type().Field(i), tags, joinPath(path, val.Type().Field(i).Name)); err != nil {
+			if l.ignoreUnsupported || !l.valueSupplied(tags) {
+				if l.report != nil {
+					l.report.Skipped = append(l.report.Skipped, joinPath(path, val.Type().Field(i).Name))
+				}
+				continue
+			}
+			err = l.fail(err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err = l.limits.countField(tags["name"])
 		if err != nil {
 			return err
 		}
-		if str == "" {
-			continue
+		origin, err := l.loadField(val.Field(i), val.Type().Field(i), tags, prefix, joinPath(path, val.Type().Field(i).Name), val)
+		if err != nil {
+			err = withField(redactError(err, tags), joinPath(path, val.Type().Field(i).Name), val.Type().Field(i).Type)
+			origin, err = l.degradeField(val.Field(i), tags, err, val)
+		}
+		l.record(joinPath(path, val.Type().Field(i).Name), val.Field(i), tags, origin, err)
+		if err != nil && path != "" {
+			err = fmt.Errorf("error loading field '%s': %w", joinPath(path, val.Type().Field(i).Name), err)
 		}
-		if val.Field(i).Kind() == reflect.Slice || val.Field(i).Kind() == reflect.Array {
-			err = setIterableField(val.Field(i), str, tags)
+		if err != nil {
+			err = l.fail(err)
 			if err != nil {
 				return err
 			}
 			continue
 		}
-		err = setField(val.Field(i), str, tags)
+		// if a factory constructed a struct implementation, load its fields as a nested struct
+		if nested, ok := factoryStruct(val.Field(i)); ok {
+			err := l.limits.enterStruct(joinPath(path, val.Type().Field(i).Name))
+			if err != nil {
+				return err
+			}
+			err = l.loadStruct(nested, prefix+val.Type().Field(i).Tag.Get(prefixTagName), joinPath(path, val.Type().Field(i).Name))
+			if err != nil {
+				return err
+			}
+			l.limits.leaveStruct()
+		}
+	}
+	l.declarationOrder(meta.order, append(marks, l.mark()))
+	return l.runHooks(val, path)
+}
+
+// loadField looks up the environment variable of a tagged field and parses its value into the field, returning where
+// the value came from.
+func (l *loader) loadField(field reflect.Value, structField reflect.StructField, tags map[string]string, prefix string, path string, parent reflect.Value) (Origin, error) {
+	docs := structField.Tag.Get(docsTagName)
+	if _, isPrefixMap := tags["prefixmap"]; isPrefixMap {
+		origin, err := l.loadPrefixMap(field, tags)
+		return origin, withDocs(err, docs)
+	}
+	lookup := l.lookup
+	if shadow, hasShadow := tags["shadow"]; hasShadow {
+		var err error
+		lookup, err = l.shadowLookup(tags["name"], prefix+shadow)
 		if err != nil {
-			return err
+			return OriginUnset, withDocs(err, docs)
 		}
 	}
-	return nil
+	if _, hasFallback := tags["fallback"]; hasFallback {
+		lookup, _ = firstSetLookup(lookup, tags["name"], fallbacks(tags, prefix))
+	}
+	_, hasAlias := tags["alias"]
+	if _, isDeprecated := tags["deprecated"]; hasAlias || isDeprecated {
+		lookup = l.aliasLookup(lookup, tags, prefix)
+	}
+	fromFile := false
+	if _, hasFile := tags["file"]; hasFile || l.fileFallback {
+		var err error
+		lookup, fromFile, err = fileLookup(lookup, tags["name"])
+		if err != nil {
+			return OriginUnset, withDocs(err, docs)
+		}
+	}
+	fromSecretSource := false
+	if hasSecretFlag(tags) && l.secretSources != nil {
+		lookup, fromSecretSource = secretSourceLookup(lookup, l.secretSources, tags["name"])
+	}
+	resolved := false
+	if ref, hasRef := tags["secretref"]; hasRef {
+		var err error
+		lookup, resolved, err = l.secretLookup(lookup, tags, ref)
+		if err != nil {
+			return OriginUnset, withDocs(err, docs)
+		}
+	}
+	condition, conditionValue, err := l.requireIf(tags, prefix)
+	if err != nil {
+		return OriginUnset, withDocs(err, docs)
+	}
+	if _, found := lookup(tags["name"]); !found {
+		err = renderDefault(tags, parent)
+		if err != nil {
+			return OriginUnset, withDocs(err, docs)
+		}
+	}
+	str, origin, err := getField(tags, lookup)
+	if err != nil {
+		return origin, withDocs(withCondition(err, condition, conditionValue), docs)
+	}
+	_, fromFlag := l.flagValues[tags["name"]]
+	switch {
+	case fromFlag && origin == OriginEnv:
+		origin = OriginFlag
+	case l.fromConfigFile[tags["name"]] && origin == OriginEnv:
+		origin = OriginConfigFile
+	case fromSecretSource && origin == OriginEnv:
+		origin = OriginSecretSource
+	case fromFile && origin == OriginEnv:
+		origin = OriginFile
+	case resolved && origin == OriginEnv:
+		origin = OriginSecretRef
+	}
+	err = l.limits.checkValue(tags["name"], str)
+	if err != nil {
+		return origin, err
+	}
+	if origin == OriginUnset {
+		return origin, nil
+	}
+	if _, isEncrypted := tags["encrypted"]; isEncrypted && str != "" {
+		str, err = decryptValue(str, tags["name"])
+		if err != nil {
+			return origin, withDocs(err, docs)
+		}
+	}
+	if len(l.fieldHooks) > 0 {
+		str, err = l.runFieldHooks(structField, tags, path, str)
+		if err != nil {
+			return origin, withDocs(err, docs)
+		}
+	}
+	l.conditionValues[tags["name"]] = str
+	if hasSecretFlag(tags) {
+		l.conditionSecrets[tags["name"]] = struct{}{}
+	}
+	if str == "" {
+		if condition != "" {
+			return origin, withDocs(&EnvNotFoundError{Env: tags["name"], Condition: condition, ConditionValue: conditionValue}, docs)
+		}
+		setEmptyValue(field)
+		// the zero value of an empty variable is validated like any other value, so X= cannot bypass min or oneof
+		return origin, validateField(field, tags)
+	}
+	err = withDocs(setValue(field, str, tags), docs)
+	if err != nil {
+		if _, isOptional := tags["optional"]; l.lenient && isOptional {
+			l.warn(redactError(err, tags))
+			return l.fallBackToDefault(field, tags, parent), nil
+		}
+		return origin, err
+	}
+	return origin, validateField(field, tags)
+}
+
+// setValue parses the string value into the field, dispatching on the kind of the field.
+// used internally by LoadEnv.
+func setValue(field reflect.Value, str string, tags map[string]string) error {
+	if format, hasFormat := tags["format"]; hasFormat && (isDocumentFormat(format) || !hasFormatParser(field.Type())) {
+		err := decodeFormat(field, str, format)
+		if err != nil {
+			return &EnvParseError{value: str, env: tags["name"], err: err}
+		}
+		return nil
+	}
+	if encoding, hasEncoding := tags["encoding"]; hasEncoding {
+		err := decodeEncoding(field, str, encoding)
+		if err != nil {
+			value := str
+			if isSecret(tags, tags["name"]) {
+				value = secretMask
+			}
+			return &EnvParseError{value: value, env: tags["name"], err: err}
+		}
+		return nil
+	}
+	if hasCustomParser(field.Type()) {
+		return setField(field, str, tags)
+	}
+	switch field.Kind() {
+	case reflect.Ptr:
+		return setPointerField(field, str, tags)
+	case reflect.Slice, reflect.Array:
+		return setIterableField(field, str, tags)
+	case reflect.Map:
+		return setMapField(field, str, tags)
+	}
+	return setField(field, str, tags)
+}
+
+// getTags completes the parsed tags of a field with the options of the loader, applies the prefix to its environment
+// variable name and registers the name, returning an error if the name was already used by another field or, in strict
+// mode, if an option is unknown.
+// used internally by LoadEnv.
+func (l *loader) getTags(field reflect.StructField, tags map[string]string, prefix string) (map[string]string, error) {
+	if l.strict {
+		for option := range tags {
+			if !isKnownTag(option) {
+				return nil, fmt.Errorf("unknown tag option: %s", option)
+			}
+		}
+	}
+	switch _, required := tags["required"]; {
+	case required || l.requirement == allRequired:
+		delete(tags, "optional")
+	case l.requirement == allOptional:
+		tags["optional"] = ""
+	}
+	err := checkTier(tags)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tagBase(tags)
+	if err != nil {
+		return nil, err
+	}
+	if l.examples {
+		exampleTags(tags)
+	}
+	if l.defaultsOnly {
+		defaultTags(tags)
+	}
+	if _, isPath := tags["path"]; l.expand || isPath {
+		tags["expand"] = ""
+	}
+	if l.strictBool {
+		tags["strictbool"] = ""
+	}
+	if l.derivedNames && (tags["name"] == "" || tags["name"] == "-") {
+		tags["name"] = derivedName(field, tags)
+	}
+	if name := tags["name"]; name != "" {
+		tags["name"] = prefix + name
+		if _, ok := l.names[tags["name"]]; ok {
+			return nil, fmt.Errorf("duplicate tag: %s", tags["name"])
+		}
+		l.names[tags["name"]] = struct{}{}
+	}
+	if l.redactionPolicy.marks(tags["name"]) {
+		tags["secret"] = ""
+	}
+	return tags, nil
+}
+
+// parseTags parses the given struct tag of a field into a key map without any side effects.
+func parseTags(field reflect.StructField, tagName string) (map[string]string, error) {
+	return ParseTag(field.Tag.Get(tagName))
+}
+
+// ParseTag parses the value of an env struct tag, e.g. PORT;default:8080;optional or
+// name=PORT,default=8080,optional, into a map of its options. The variable name is stored under the "name" key, flags
+// map to an empty string.
+func ParseTag(tag string) (map[string]string, error) {
+	split := splitTagOptions
+	if isKeyValueTag(tag) {
+		split = splitKeyValueTag
+	}
+	options, err := split(tag)
+	if err != nil {
+		return nil, err
+	}
+	return tagSliceToKeyMap(options)
 }
 
-func getTags(field reflect.StructField) (map[string]string, error) {
-	unparsedTags := field.Tag.Get(tagName)
-	tagSlice := strings.FieldsFunc(unparsedTags, SplitTags)
-	return tagSliceToKeyMap(tagSlice)
+// shadowLookup returns a lookup that reads the variable from its old name when it is not set under its new name,
+// for fields that are migrating to a new variable name. When both names are set with different values the conflict
+// is reported as a warning, or returned as an error in strict mode.
+// used internally by LoadEnv.
+func (l *loader) shadowLookup(name string, shadow string) (func(string) (string, bool), error) {
+	value, found := l.lookup(name)
+	shadowValue, shadowFound := l.lookup(shadow)
+	if found && shadowFound && value != shadowValue {
+		err := &ShadowMismatchError{Env: name, Shadow: shadow}
+		if l.strict {
+			return nil, err
+		}
+		l.warn(err)
+	}
+	return func(key string) (string, bool) {
+		if key == name && !found {
+			return shadowValue, shadowFound
+		}
+		return l.lookup(key)
+	}, nil
 }
 
-// TODO support all chars in default value
-// TODO allow for empty string definition of a env var, like SOMETHING=
-// getField gets the value of an environment variable based on the tag. returns the value, a bool indicating if the value is optional, and an error if the value is not found.
+// getField gets the value of an environment variable based on the tag. returns the value, the origin of the value, and an error if the value is not found and the field is not optional.
+// A variable that is set to the empty string counts as found, unless the field has the allowempty flag, in which case it is treated as unset.
 // used internally by LoadEnv.
-func getField(tags map[string]string) (string, error) {
-	str := os.Getenv(tags["name"])
-	if str != "" {
-		return str, nil
+func getField(tags map[string]string, lookup func(string) (string, bool)) (string, Origin, error) {
+	str, found := lookup(tags["name"])
+	if _, allowEmpty := tags["allowempty"]; allowEmpty && str == "" {
+		found = false
+	}
+	if _, expand := tags["expand"]; expand && found {
+		value, err := expandVars(str, lookup)
+		if err != nil {
+			return "", OriginUnset, &EnvParseError{value: str, env: tags["name"], err: err}
+		}
+		return value, OriginEnv, nil
+	}
+	if found {
+		return str, OriginEnv, nil
 	}
 	// if the env var is not found, check if it has a default value
 	if defaultValue, hasDefault := tags["default"]; hasDefault {
-		return defaultValue, nil
+		value, err := expandPseudoVars(defaultValue)
+		if _, expand := tags["expand"]; expand {
+			value, err = expandVars(defaultValue, lookup)
+		}
+		if err != nil {
+			return "", OriginUnset, &EnvParseError{value: defaultValue, env: tags["name"], err: err}
+		}
+		if strings.HasPrefix(value, defaultFuncPrefix) {
+			value, err = callDefaultFunc(strings.TrimPrefix(value, defaultFuncPrefix))
+			if err != nil {
+				return "", OriginUnset, &EnvParseError{value: defaultValue, env: tags["name"], err: err}
+			}
+		}
+		if isExprDefault(value) {
+			value, err = evalExpr(strings.TrimPrefix(value, exprPrefix))
+			if err != nil {
+				return "", OriginUnset, &EnvParseError{value: defaultValue, env: tags["name"], err: err}
+			}
+		}
+		return value, OriginDefault, nil
 	}
 	// if the env var is not found and does not have a default value, check if it is optional
 	if _, isOptional := tags["optional"]; !isOptional {
-		return "", &EnvNotFoundError{Env: tags["name"]}
+		return "", OriginUnset, &EnvNotFoundError{Env: tags["name"]}
 	}
-	return "", nil
+	return "", OriginUnset, nil
 }
 
 // setField sets the value of a field based on the string value and the field type. It returns an error if the field cannot be set or if the string value cannot be parsed into the field type.
@@ -150,19 +1185,94 @@ func setField(field reflect.Value, str string, tags map[string]string) error {
 	if !field.CanSet() {
 		return &EnvParseError{value: str, env: tags["name"], err: errors.New("field cannot be set")}
 	}
-	if unmarshaller, found := envTypes[field.Type()]; found {
-		var value interface{}
-		value, err := unmarshaller(str)
+	if enum, hasEnum := tags["enum"]; hasEnum {
+		value, err := enumValue(str, enum)
+		if err != nil {
+			return &EnvParseError{value: str, env: tags["name"], err: err}
+		}
+		str = value
+	}
+	if format, hasFormat := tags["format"]; hasFormat && !isDocumentFormat(format) {
+		setter, found := formatSetterFor(field.Type())
+		if !found {
+			return &EnvParseError{value: str, env: tags["name"], err: fmt.Errorf("type %s does not support format '%s'", field.Type(), format)}
+		}
+		err := setter(field, str, format)
+		if err != nil {
+			return &EnvParseError{value: str, env: tags["name"], err: err}
+		}
+		return nil
+	}
+	if layout, hasLayout := tags["layout"]; hasLayout && field.Type() == timeType {
+		value, err := parseTime(str, layout)
 		if err != nil {
 			return &EnvParseError{value: str, env: tags["name"], err: err}
 		}
 		field.Set(reflect.ValueOf(value))
-	} else {
-		_, err := fmt.Sscan(str, field.Addr().Interface())
+		return nil
+	}
+	if tz, hasTZ := tags["tz"]; hasTZ && field.Type() == timeWindowType {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return &EnvParseError{value: str, env: tags["name"], err: fmt.Errorf("invalid time zone '%s'", tz)}
+		}
+		window, err := parseTimeWindow(str, location)
+		if err != nil {
+			return &EnvParseError{value: str, env: tags["name"], err: err}
+		}
+		field.Set(reflect.ValueOf(window))
+		return nil
+	}
+	if setter, found := envSetterFor(field.Type()); found {
+		err := setter(field, str)
+		if err != nil {
+			return &EnvParseError{value: str, env: tags["name"], err: err, hint: coercionHint(str, field.Type())}
+		}
+		return nil
+	}
+	if _, strictBool := tags["strictbool"]; strictBool && field.Kind() == reflect.Bool {
+		err := checkStrictBool(str)
 		if err != nil {
 			return &EnvParseError{value: str, env: tags["name"], err: err}
 		}
 	}
+	handled, err := unmarshalEncoding(field, str)
+	if !handled {
+		handled, err = setScalarField(field, str, tags)
+	}
+	if !handled {
+		err = scanField(field, str)
+	}
+	if err != nil {
+		return &EnvParseError{value: str, env: tags["name"], err: err, hint: coercionHint(str, field.Type())}
+	}
+	return nil
+}
+
+// setEmptyValue sets a field to the zero value of its type for a variable that is explicitly set to the empty string,
+// pointer fields point to a zero value so they can be told apart from unset ones.
+// used internally by LoadEnv.
+func setEmptyValue(field reflect.Value) {
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		return
+	}
+	field.Set(reflect.Zero(field.Type()))
+}
+
+// setPointerField allocates a new value for a pointer field and parses the string value into it. Pointer fields are
+// only allocated when a value is found, so an unset optional variable leaves the pointer nil.
+// used internally by LoadEnv.
+func setPointerField(field reflect.Value, str string, tags map[string]string) error {
+	if !field.CanSet() {
+		return &EnvParseError{value: str, env: tags["name"], err: errors.New("field cannot be set")}
+	}
+	ptr := reflect.New(field.Type().Elem())
+	err := setValue(ptr.Elem(), str, tags)
+	if err != nil {
+		return err
+	}
+	field.Set(ptr)
 	return nil
 }
 
@@ -179,13 +1289,27 @@ func setIterableField(field reflect.Value, str string, tags map[string]string) e
 	if field.Kind() == reflect.Array {
 		maxLength = field.Type().Len()
 	}
-	strValues, err := parseArrayString(str)
+	var err error
+	var strValues []string
+	_, trim := tags["trim"]
+	if sep, hasSep := tags["sep"]; hasSep {
+		strValues, err = splitList(str, sep, trim)
+	} else {
+		strValues, err = parseList(str, trim)
+	}
 	if err != nil {
 		return &EnvParseError{value: str, env: tags["name"], err: err}
 	}
 	if maxLength > 0 && len(strValues) > maxLength {
 		return &EnvParseError{value: str, env: tags["name"], err: fmt.Errorf("array size overflow, expected %d, got %d", maxLength, len(strValues))}
 	}
+	pad, hasPad := tags["pad"]
+	if hasPad && pad != "error" && pad != "zero" && pad != "repeat" {
+		return &EnvParseError{value: str, env: tags["name"], err: fmt.Errorf("invalid pad '%s', expected error, zero or repeat", pad)}
+	}
+	if field.Kind() == reflect.Array && pad == "error" && len(strValues) < maxLength {
+		return &EnvParseError{value: str, env: tags["name"], err: fmt.Errorf("array size underflow, expected %d, got %d", maxLength, len(strValues))}
+	}
 	if field.Kind() == reflect.Slice {
 		field.Set(reflect.MakeSlice(field.Type(), len(strValues), len(strValues)))
 	}
@@ -195,48 +1319,214 @@ func setIterableField(field reflect.Value, str string, tags map[string]string) e
 			return err
 		}
 	}
+	if field.Kind() == reflect.Array {
+		padArray(field, len(strValues), pad)
+	}
+	return nil
+}
+
+// padArray fills the elements of an array after the given number of set elements as selected by the pad option: with
+// their zero value for zero, or with the last set element for repeat. Other values leave them untouched.
+func padArray(field reflect.Value, set int, pad string) {
+	for i := set; i < field.Len(); i++ {
+		switch {
+		case pad == "zero" || pad == "repeat" && set == 0:
+			field.Index(i).SetZero()
+		case pad == "repeat":
+			field.Index(i).Set(field.Index(set - 1))
+		}
+	}
+}
+
+// setMapField sets the entries of a map field based on a string of comma separated key=value pairs. Keys and values
+// are parsed into the key and element type of the map like any other field. It returns an error if the field cannot be
+// set or if an entry is malformed or cannot be parsed.
+// used internally by LoadEnv.
+func setMapField(field reflect.Value, str string, tags map[string]string) error {
+	if !field.CanSet() {
+		return &EnvParseError{value: str, env: tags["name"], err: errors.New("field cannot be set")}
+	}
+	entries, err := parseMapString(str)
+	if err != nil {
+		return &EnvParseError{value: str, env: tags["name"], err: err}
+	}
+	m := reflect.MakeMapWithSize(field.Type(), len(entries))
+	for _, entry := range entries {
+		key := reflect.New(field.Type().Key()).Elem()
+		err = setField(key, entry[0], tags)
+		if err != nil {
+			return err
+		}
+		value := reflect.New(field.Type().Elem()).Elem()
+		err = setField(value, entry[1], tags)
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(key, value)
+	}
+	field.Set(m)
 	return nil
 }
 
-func parseArrayString(str string) ([]string, error) {
-	if len(str) < 2 || str[:1] != "[" && str[len(str)-1:] != "]" {
-		return nil, errors.New("invalid array format")
+// ParseMap parses a string of comma separated key=value pairs, e.g. a=1,b=2, into a map. When a key occurs more than
+// once the last value wins.
+func ParseMap(str string) (map[string]string, error) {
+	entries, err := parseMapString(str)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		m[entry[0]] = entry[1]
+	}
+	return m, nil
+}
+
+// parseMapString splits a string of comma separated key=value pairs into key and value pairs.
+func parseMapString(str string) ([][2]string, error) {
+	var entries [][2]string
+	for _, entry := range strings.Split(str, ",") {
+		key, value, found := strings.Cut(entry, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid map entry '%s', expected key=value", entry)
+		}
+		entries = append(entries, [2]string{key, value})
 	}
-	str = str[1 : len(str)-1]
-	return strings.Split(str, ","), nil
+	return entries, nil
+}
+
+// valueTags are the tag options that take a value, e.g. default:8080.
+var valueTags = map[string]struct{}{
+	"alias":      {},
+	"default":    {},
+	"deprecated": {},
+	"encoding":   {},
+	"enum":       {},
+	"example":    {},
+	"format":     {},
+	"group":      {},
+	"layout":     {},
+	"shadow":     {},
+	"tier":       {},
+	"min":        {},
+	"max":        {},
+	"after":      {},
+	"before":     {},
+	"base":       {},
+	"fallback":   {},
+	"tz":         {},
+	"oneof":      {},
+	"regex":      {},
+	"requiredif": {},
+	"secretref":  {},
+	"sep":        {},
+	"pad":        {},
+	"minlen":     {},
+	"maxlen":     {},
+	"minBytes":   {},
+	"minEntropy": {},
+}
+
+// flagTags are the tag options that do not take a value, e.g. optional.
+var flagTags = map[string]struct{}{
+	"optional":   {},
+	"required":   {},
+	"allowempty": {},
+	"expand":     {},
+	"bcp47":      {},
+	"email":      {},
+	"encrypted":  {},
+	"file":       {},
+	"hostport":   {},
+	"iso3166":    {},
+	"iso4217":    {},
+	"mustexist":  {},
+	"notempty":   {},
+	"omitempty":  {},
+	"path":       {},
+	"prefixmap":  {},
+	"secret":     {},
+	"strictbool": {},
+	"trim":       {},
 }
 
-var tagNames = map[string]struct{}{}
+// isKnownTag reports whether the given key of a parsed tag map is a supported tag option.
+func isKnownTag(option string) bool {
+	_, isValue := valueTags[option]
+	_, isFlag := flagTags[option]
+	return option == "name" || isValue || isFlag
+}
 
-// tagSliceToKeyMap converts a slice of tag strings into a map where the key is the tag and the value is the default value.
+// tagSliceToKeyMap converts a slice of tag options into a map where the key is the option and the value is the text
+// after its first colon, or an empty string for flags. The first option is the name of the environment variable, a
+// chain of names like DATABASE_URL|DB_URL stores the names after the first under the fallback key.
 // It is used internally by LoadEnv.
 func tagSliceToKeyMap(slice []string) (map[string]string, error) {
 	m := make(map[string]string)
-	for index := 0; index < len(slice); index++ {
-		item := slice[index]
+	for index, item := range slice {
 		if index == 0 {
-			m["name"] = item
-			if _, ok := tagNames[item]; ok {
-				return nil, fmt.Errorf("duplicate tag: %s", item)
+			name, chain, isChain := strings.Cut(item, "|")
+			m["name"] = name
+			if isChain {
+				m["fallback"] = strings.ReplaceAll(chain, "|", ",")
 			}
-			tagNames[item] = struct{}{}
 			continue
 		}
-		if item == "default" {
-			if _, ok := m[item]; ok {
-				return nil, fmt.Errorf("duplicate tag: %s", item)
+		key, value, hasValue := strings.Cut(item, ":")
+		if key == "" {
+			return nil, fmt.Errorf("missing name for tag option: %s", item)
+		}
+		if _, takesValue := valueTags[key]; takesValue {
+			if _, ok := m[key]; ok {
+				return nil, fmt.Errorf("duplicate tag: %s", key)
+			}
+			if !hasValue {
+				return nil, fmt.Errorf("missing value for tag: %s", key)
 			}
-			m[item] = slice[index+1]
-			index++
-			continue
 		}
-		m[item] = ""
+		m[key] = value
 	}
 	return m, nil
 }
 
-// SplitTags is a helper function used to split struct tags.
-// It is used internally by LoadEnv.
+// splitTagOptions splits a tag on its semicolons into options, skipping empty options. A value can be quoted with
+// single quotes, e.g. default:'a;b', and \;, \\ and \' escape a semicolon, backslash or quote.
+func splitTagOptions(tag string) ([]string, error) {
+	var options []string
+	var option strings.Builder
+	quoted := false
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case c == '\\' && i+1 < len(tag) && strings.IndexByte(`;\'`, tag[i+1]) >= 0:
+			option.WriteByte(tag[i+1])
+			i++
+		case quoted && c == '\'':
+			quoted = false
+		case quoted:
+			option.WriteByte(c)
+		case c == '\'' && strings.HasSuffix(option.String(), ":") && strings.Count(option.String(), ":") == 1:
+			quoted = true
+		case c == ';':
+			if option.Len() > 0 {
+				options = append(options, option.String())
+			}
+			option.Reset()
+		default:
+			option.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quoted value in tag")
+	}
+	if option.Len() > 0 {
+		options = append(options, option.String())
+	}
+	return options, nil
+}
+
+// Deprecated: SplitTags splits a tag on both semicolons and colons, which mangles values containing a colon. Use
+// ParseTag to parse a tag instead.
 func SplitTags(r rune) bool {
 	return r == ';' || r == ':'
 }
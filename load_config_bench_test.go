@@ -0,0 +1,88 @@
+package goloadenv
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const benchFieldCount = 500
+
+// newBenchConfig builds a struct type with benchFieldCount scalar fields and sets a matching environment variable for
+// each of them.
+func newBenchConfig(b *testing.B) reflect.Type {
+	b.Helper()
+	os.Clearenv()
+	kinds := []struct {
+		typ   reflect.Type
+		value string
+	}{
+		{reflect.TypeFor[string](), "localhost"},
+		{reflect.TypeFor[int](), "8080"},
+		{reflect.TypeFor[bool](), "true"},
+		{reflect.TypeFor[float64](), "0.75"},
+	}
+	fields := make([]reflect.StructField, benchFieldCount)
+	for i := range fields {
+		kind := kinds[i%len(kinds)]
+		name := fmt.Sprintf("FIELD_%d", i)
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: kind.typ,
+			Tag:  reflect.StructTag(fmt.Sprintf(`env:"%s"`, name)),
+		}
+		if err := os.Setenv(name, kind.value); err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+func BenchmarkLoadEnvScalars(b *testing.B) {
+	typ := newBenchConfig(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := LoadEnv(reflect.New(typ).Interface()); err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+// BenchmarkLoadEnvScalarsColdCache is the baseline the struct metadata cache improves on, parsing the struct tags on
+// every load.
+func BenchmarkLoadEnvScalarsColdCache(b *testing.B) {
+	typ := newBenchConfig(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		structMetas.Clear()
+		if err := LoadEnv(reflect.New(typ).Interface()); err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+func BenchmarkSetFieldScalar(b *testing.B) {
+	var port int
+	field := reflect.ValueOf(&port).Elem()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := setScalarField(field, "8080", nil); err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+// BenchmarkSetFieldSscan is the baseline the scalar fast path replaces.
+func BenchmarkSetFieldSscan(b *testing.B) {
+	var port int
+	field := reflect.ValueOf(&port).Elem()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := fmt.Sscan("8080", field.Addr().Interface()); err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package goloadenv
 
 import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type CustomMapType map[string]string
@@ -45,7 +54,6 @@ func setTestEnv() error {
 
 func clearTestEnv() error {
 	os.Clearenv()
-	tagNames = map[string]struct{}{}
 	return nil
 }
 
@@ -120,11 +128,11 @@ func TestEnvParseError(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error setting up test environment, got err %v", err)
 	}
-	err = os.Setenv("PARSE_ERR", "key1=value1,key2=value2")
+	err = os.Setenv("PARSE_ERR", "key1=value1,key2")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	expected := "error parsing 'key1=value1,key2=value2' as environment variable PARSE_ERR: can't scan type: *goloadenv.CustomMapType"
+	expected := "error parsing 'key1=value1,key2' as environment variable PARSE_ERR: invalid map entry 'key2', expected key=value"
 	err = LoadEnv(&TestConfig{})
 	if err == nil {
 		t.Errorf("Expected error, got nil")
@@ -134,6 +142,39 @@ func TestEnvParseError(t *testing.T) {
 	}
 }
 
+func TestErrorFields(t *testing.T) {
+	cfg := struct {
+		Host string `env:"HOST"`
+		DB   struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		} `envPrefix:"DB_"`
+	}{}
+
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"DB_PORT": "http"}), WithAllErrors())
+	missing := MissingVars(err)
+	if !reflect.DeepEqual(missing, []string{"HOST", "DB_HOST"}) {
+		t.Errorf("Expected [HOST DB_HOST], got %v", missing)
+	}
+	var notFoundErr *EnvNotFoundError
+	if !errors.Is(err, &EnvNotFoundError{Env: "DB_HOST"}) || !errors.As(err, &notFoundErr) || notFoundErr.Path != "Host" || notFoundErr.Type != reflect.TypeOf("") {
+		t.Errorf("Expected an EnvNotFoundError of field Host, got %v", err)
+	}
+	var parseErr *EnvParseError
+	if !errors.Is(err, &EnvParseError{Path: "DB.Port"}) || !errors.As(err, &parseErr) || parseErr.Env() != "DB_PORT" || parseErr.Type != reflect.TypeOf(0) {
+		t.Errorf("Expected an EnvParseError of field DB.Port, got %v", err)
+	}
+	if errors.Is(err, &EnvNotFoundError{Env: "PORT"}) || errors.Is(err, &EnvParseError{Path: "Host"}) {
+		t.Errorf("Expected no errors of PORT or Host, got %v", err)
+	}
+	if parseErr != nil && errors.Unwrap(parseErr).Error() != "invalid syntax for int" {
+		t.Errorf("Expected the parse error to unwrap to invalid syntax for int, got %v", errors.Unwrap(parseErr))
+	}
+	if MissingVars(nil) != nil {
+		t.Errorf("Expected no missing variables without an error")
+	}
+}
+
 func TestConfigStructNotAPointerError(t *testing.T) {
 	clearTestEnv()
 
@@ -155,12 +196,12 @@ func TestEmbeddedStructParseError(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error setting up test environment, got err %v", err)
 	}
-	err = os.Setenv("PARSE_EMBEDDED_ERR", "key1=value1,key2=value2")
+	err = os.Setenv("PARSE_EMBEDDED_ERR", "key1=value1,key2")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	expected := "error loading nested struct 'ParseErr': error parsing 'key1=value1,key2=value2' as environment variable PARSE_EMBEDDED_ERR: can't scan type: *goloadenv.CustomMapType"
+	expected := "error loading field 'StructParseErr.ParseErr': error parsing 'key1=value1,key2' as environment variable PARSE_EMBEDDED_ERR: invalid map entry 'key2', expected key=value"
 	err = LoadEnv(&TestConfig{})
 	if err == nil {
 		t.Errorf("Expected error, got nil")
@@ -244,3 +285,1334 @@ func TestArrayField(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expected, someStruct.IntArray)
 	}
 }
+
+func TestCoercionHint(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("WORKERS", `{"min":1,"max":4}`)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Workers int `env:"WORKERS"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	expected := "(hint: value looks like a JSON document; change the field to a string or register an unmarshaller for int)"
+	if !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("Expected %s, got %s", expected, err.Error())
+	}
+}
+
+func TestScalarFields(t *testing.T) {
+	clearTestEnv()
+
+	env := map[string]string{
+		"GREETING": "hello world",
+		"MASK":     "0xFF",
+		"RATIO":    "0.5",
+		"ENABLED":  "true",
+	}
+	for key, value := range env {
+		err := os.Setenv(key, value)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	someStruct := struct {
+		Greeting string  `env:"GREETING"`
+		Mask     uint8   `env:"MASK"`
+		Ratio    float32 `env:"RATIO"`
+		Enabled  bool    `env:"ENABLED"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Greeting != "hello world" {
+		t.Errorf("Expected GREETING=hello world, got %s", someStruct.Greeting)
+	}
+	if someStruct.Mask != 0xFF {
+		t.Errorf("Expected MASK=255, got %d", someStruct.Mask)
+	}
+	if someStruct.Ratio != 0.5 {
+		t.Errorf("Expected RATIO=0.5, got %f", someStruct.Ratio)
+	}
+	if !someStruct.Enabled {
+		t.Errorf("Expected ENABLED=true, got %t", someStruct.Enabled)
+	}
+}
+
+func TestIntegerBases(t *testing.T) {
+	someStruct := struct {
+		Mask    uint32 `env:"MASK;base:16"`
+		Prefix  uint32 `env:"PREFIX;base:16"`
+		Mode    int    `env:"MODE;base:8"`
+		Flags   uint8  `env:"FLAGS;base:2"`
+		Offset  int64  `env:"OFFSET;base:16"`
+		Binary  int    `env:"BINARY"`
+		Decimal int    `env:"DECIMAL"`
+	}{}
+	err := LoadEnvFromMap(&someStruct, map[string]string{
+		"MASK": "FF", "PREFIX": "0xff", "MODE": "0o755", "FLAGS": "1010", "OFFSET": "-0x10", "BINARY": "0b11", "DECIMAL": "08",
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Mask != 255 || someStruct.Prefix != 255 || someStruct.Mode != 0o755 || someStruct.Flags != 10 ||
+		someStruct.Offset != -16 || someStruct.Binary != 3 || someStruct.Decimal != 8 {
+		t.Errorf("Expected MASK=255, PREFIX=255, MODE=493, FLAGS=10, OFFSET=-16, BINARY=3 and DECIMAL=8, got %+v", someStruct)
+	}
+
+	for tag, expected := range map[string]string{
+		"MASK;base:16": "error parsing 'GG' as environment variable MASK: invalid syntax for int",
+		"MASK;base:1":  "error getting tags for field: 'Value': invalid base '1', expected 2 to 36",
+	} {
+		_, err = Get[int](tag, WithSources(MapSource{"MASK": "GG"}))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	}
+}
+
+func TestScalarFieldErrors(t *testing.T) {
+	someStruct := struct {
+		Port   uint16     `env:"PORT"`
+		Offset int8       `env:"OFFSET"`
+		Ratio  float32    `env:"RATIO"`
+		Signal complex128 `env:"SIGNAL"`
+	}{}
+	err := LoadEnvWithOptions(&someStruct, WithSources(MapSource{"PORT": "8080", "OFFSET": "-8", "RATIO": "1e10", "SIGNAL": "1+2i"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Port != 8080 || someStruct.Offset != -8 || someStruct.Ratio != 1e10 || someStruct.Signal != 1+2i {
+		t.Errorf("Expected PORT=8080, OFFSET=-8, RATIO=1e10 and SIGNAL=1+2i, got %v", someStruct)
+	}
+
+	for env, expected := range map[string]string{
+		"PORT=70000":   "error parsing '70000' as environment variable PORT: value 70000 overflows uint16",
+		"PORT=-1":      "error parsing '-1' as environment variable PORT: negative value -1 for uint16",
+		"PORT=8080abc": "error parsing '8080abc' as environment variable PORT: invalid syntax for uint16",
+		"OFFSET=200":   "error parsing '200' as environment variable OFFSET: value 200 overflows int8",
+		"RATIO=1e39":   "error parsing '1e39' as environment variable RATIO: value 1e39 overflows float32",
+		"SIGNAL=1+x":   "error parsing '1+x' as environment variable SIGNAL: invalid syntax for complex128",
+	} {
+		key, value, _ := strings.Cut(env, "=")
+		source := MapSource{"PORT": "8080", "OFFSET": "-8", "RATIO": "0.5", "SIGNAL": "1"}
+		source[key] = value
+		err = LoadEnvWithOptions(&someStruct, WithSources(source))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	}
+}
+
+func TestBoolFields(t *testing.T) {
+	for value, expected := range map[string]bool{
+		"true": true, "Yes": true, "ON": true, "1": true, "enabled": true,
+		"false": false, "no": false, "Off": false, "0": false, "DISABLED": false,
+	} {
+		enabled, err := Get[bool]("FEATURE_X", WithSources(MapSource{"FEATURE_X": value}))
+		if err != nil || enabled != expected {
+			t.Errorf("Expected FEATURE_X=%s to be %t, got %t, %v", value, expected, enabled, err)
+		}
+	}
+
+	_, err := Get[bool]("FEATURE_X", WithSources(MapSource{"FEATURE_X": "maybe"}))
+	expected := "error parsing 'maybe' as environment variable FEATURE_X: invalid syntax for bool (hint: boolean values must be written as true or false, yes or no, on or off, 1 or 0, or enabled or disabled)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	_, err = Get[bool]("FEATURE_X", WithSources(MapSource{"FEATURE_X": "yes"}), WithStrictBool())
+	expected = "error parsing 'yes' as environment variable FEATURE_X: boolean values must be true or false"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	enabled, err := Get[bool]("FEATURE_X;strictbool", WithSources(MapSource{"FEATURE_X": "True"}))
+	if err != nil || !enabled {
+		t.Errorf("Expected FEATURE_X=true, got %t, %v", enabled, err)
+	}
+}
+
+type Hostname string
+
+func (Hostname) UnmarshalEnv(str string) (Hostname, error) {
+	return Hostname(strings.ToLower(str)), nil
+}
+
+func TestRegisterTypedEnvType(t *testing.T) {
+	clearTestEnv()
+	RegisterTypedEnvType[Hostname]()
+
+	err := os.Setenv("HOSTNAME", "Example.COM")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Hostname Hostname `env:"HOSTNAME"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Hostname != "example.com" {
+		t.Errorf("Expected HOSTNAME=example.com, got %s", someStruct.Hostname)
+	}
+}
+
+func parseMonth(str string) (time.Month, error) {
+	for month := time.January; month <= time.December; month++ {
+		if strings.EqualFold(month.String(), str) {
+			return month, nil
+		}
+	}
+	return 0, errors.New("unknown month")
+}
+
+func TestRegisterEnvTypeFunc(t *testing.T) {
+	RegisterEnvTypeFunc(parseMonth)
+
+	someStruct := struct {
+		Start    time.Month   `env:"START"`
+		Holidays []time.Month `env:"HOLIDAYS;sep:,"`
+	}{}
+	err := LoadEnvWithOptions(&someStruct, WithSources(MapSource{"START": "march", "HOLIDAYS": "July,August"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Start != time.March || !reflect.DeepEqual(someStruct.Holidays, []time.Month{time.July, time.August}) {
+		t.Errorf("Expected START=March and HOLIDAYS=[July August], got %v", someStruct)
+	}
+
+	_, err = Get[time.Month]("START", WithSources(MapSource{"START": "3"}))
+	var parseErr *EnvParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("Expected EnvParseError, got %v", err)
+	}
+}
+
+func TestMapField(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("LABELS", "team=core,tier=backend")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("WEIGHTS", "primary=10,replica=5")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Labels  map[string]string `env:"LABELS"`
+		Weights map[string]int    `env:"WEIGHTS"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(someStruct.Labels) != 2 || someStruct.Labels["team"] != "core" || someStruct.Labels["tier"] != "backend" {
+		t.Errorf("Expected LABELS=map[team:core tier:backend], got %v", someStruct.Labels)
+	}
+	if len(someStruct.Weights) != 2 || someStruct.Weights["primary"] != 10 || someStruct.Weights["replica"] != 5 {
+		t.Errorf("Expected WEIGHTS=map[primary:10 replica:5], got %v", someStruct.Weights)
+	}
+}
+
+func TestPrefixMapField(t *testing.T) {
+	someStruct := struct {
+		Features map[string]string `env:"FEATURE_;prefixmap"`
+		Limits   map[string]int    `env:"LIMIT_;prefixmap;max:100"`
+		Labels   map[string]string `env:"LABEL_;prefixmap;default:team=core"`
+		Tags     map[string]string `env:"TAG_;prefixmap;optional"`
+	}{}
+
+	err := LoadEnvWithOptions(&someStruct, WithSources(MapSource{"FEATURE_DARK_MODE": "on", "FEATURE_BETA": "off", "LIMIT_API": "10", "LIMIT_UPLOADS": "20"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expectedFeatures := map[string]string{"DARK_MODE": "on", "BETA": "off"}
+	if !reflect.DeepEqual(someStruct.Features, expectedFeatures) {
+		t.Errorf("Expected %v, got %v", expectedFeatures, someStruct.Features)
+	}
+	expectedLimits := map[string]int{"API": 10, "UPLOADS": 20}
+	if !reflect.DeepEqual(someStruct.Limits, expectedLimits) {
+		t.Errorf("Expected %v, got %v", expectedLimits, someStruct.Limits)
+	}
+	if !reflect.DeepEqual(someStruct.Labels, map[string]string{"team": "core"}) || someStruct.Tags != nil {
+		t.Errorf("Expected the default labels and no tags, got %v and %v", someStruct.Labels, someStruct.Tags)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(MapSource{"FEATURE_BETA": "on", "LIMIT_API": "1000"}))
+	expected := "invalid value '1000' for environment variable LIMIT_API: must be at most 100"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+type TenantConfig struct {
+	Host   string `env:"HOST"`
+	DBHost string `env:"DB_HOST;optional"`
+	Port   int    `env:"PORT;default:8080"`
+}
+
+func TestStructPrefixMapField(t *testing.T) {
+	someStruct := struct {
+		Tenants map[string]TenantConfig  `env:"TENANT_;prefixmap"`
+		Regions map[string]*TenantConfig `env:"REGION_;prefixmap;optional"`
+	}{}
+	env := MapSource{
+		"TENANT_ACME_HOST":      "acme.example.com",
+		"TENANT_ACME_DB_HOST":   "db.acme",
+		"TENANT_ACME_PORT":      "9090",
+		"TENANT_GLOBEX_HOST":    "globex.example.com",
+		"TENANT_GLOBEX_CO_PORT": "1",
+	}
+	err := LoadEnvWithOptions(&someStruct, WithSources(env))
+	expected := "error loading field 'Tenants[GLOBEX_CO].Host': environment variable not found: TENANT_GLOBEX_CO_HOST"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	delete(env, "TENANT_GLOBEX_CO_PORT")
+	report, err := LoadEnvReport(&someStruct, WithSources(env))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expectedTenants := map[string]TenantConfig{
+		"ACME":   {Host: "acme.example.com", DBHost: "db.acme", Port: 9090},
+		"GLOBEX": {Host: "globex.example.com", Port: 8080},
+	}
+	if !reflect.DeepEqual(someStruct.Tenants, expectedTenants) || someStruct.Regions != nil {
+		t.Errorf("Expected %v and no regions, got %v and %v", expectedTenants, someStruct.Tenants, someStruct.Regions)
+	}
+	if field := report.Fields[0]; field.Path != "Tenants[ACME].Host" || field.Env != "TENANT_ACME_HOST" {
+		t.Errorf("Expected the report to hold the tenant fields, got %v", report.Fields)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(MapSource{}))
+	if !errors.Is(err, &EnvNotFoundError{Env: "TENANT_"}) {
+		t.Errorf("Expected TENANT_ to be missing, got %v", err)
+	}
+}
+
+func TestDeclarationOrder(t *testing.T) {
+	cfg := struct {
+		Addr string `env:"ADDR;default:tmpl:{{.Host}}:{{.Port}}"`
+		Name string `env:"NAME"`
+		Host string `env:"HOST;default:localhost"`
+		Port int    `env:"PORT"`
+		DB   struct {
+			User string `env:"USER"`
+		} `envPrefix:"DB_"`
+	}{}
+	report, err := LoadEnvReport(&cfg, WithSources(MapSource{"PORT": "x"}), WithAllErrors())
+	expected := "environment variable not found: NAME\n" +
+		"error parsing 'x' as environment variable PORT: invalid syntax for int\n" +
+		"error loading field 'DB.User': environment variable not found: DB_USER"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	var paths []string
+	for _, field := range report.Fields {
+		paths = append(paths, field.Path)
+	}
+	if strings.Join(paths, ",") != "Addr,Name,Host,Port,DB.User" {
+		t.Errorf("Expected the report in declaration order, got %v", paths)
+	}
+}
+
+func TestComputedDefaults(t *testing.T) {
+	RegisterDefaultFunc("zone", func() (string, error) { return "eu-west-1a", nil })
+	RegisterDefaultFunc("broken", func() (string, error) { return "", errors.New("no zone") })
+
+	cfg := struct {
+		Addr string `env:"ADDR;default:tmpl:{{.Host}}:{{.Port}}"`
+		Host string `env:"HOST"`
+		Port int    `env:"PORT;default:8080"`
+		Zone string `env:"ZONE;default:fn:zone"`
+	}{}
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"HOST": "localhost"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Addr != "localhost:8080" || cfg.Zone != "eu-west-1a" {
+		t.Errorf("Expected ADDR=localhost:8080 and ZONE=eu-west-1a, got %v", cfg)
+	}
+
+	err = LoadEnvWithOptions(&cfg, WithSources(MapSource{"HOST": "localhost", "ADDR": "example.com:80", "ZONE": "us-east-1b"}))
+	if err != nil || cfg.Addr != "example.com:80" || cfg.Zone != "us-east-1b" {
+		t.Errorf("Expected the variables to override the computed defaults, got %v, %v", cfg, err)
+	}
+
+	literal := struct {
+		Greeting string `env:"GREETING;default:Hello {{name}}"`
+		URL      string `env:"URL;default:tmpl:{{.Missing}}"`
+	}{}
+	err = LoadEnvWithOptions(&literal, WithSources(MapSource{"URL": "http://example.com"}))
+	if err != nil || literal.Greeting != "Hello {{name}}" || literal.URL != "http://example.com" {
+		t.Errorf("Expected GREETING=Hello {{name}} and the template of URL not to be rendered, got %v, %v", literal, err)
+	}
+
+	broken := struct {
+		Zone string `env:"ZONE;default:fn:broken"`
+		Name string `env:"NAME;default:fn:missing;optional"`
+	}{}
+	err = LoadEnvWithOptions(&broken, WithSources(MapSource{}), WithAllErrors())
+	expected := "error parsing 'fn:broken' as environment variable ZONE: no zone\n" +
+		"error parsing 'fn:missing' as environment variable NAME: no default function registered as 'missing'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+type concurrentID string
+
+func TestConcurrentLoading(t *testing.T) {
+	type concurrentConfig struct {
+		Host string        `env:"HOST"`
+		ID   concurrentID  `env:"ID;default:fn:concurrent"`
+		TTL  time.Duration `env:"TTL;format:seconds;default:30"`
+	}
+	RegisterDefaultFunc("concurrent", func() (string, error) { return "node", nil })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cfg := concurrentConfig{}
+			err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"HOST": "localhost"}))
+			if err != nil || cfg.Host != "localhost" || cfg.TTL != 30*time.Second {
+				t.Errorf("Expected the config to load, got %v, %v", cfg, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			RegisterEnvTypeFunc(func(str string) (concurrentID, error) { return concurrentID(strings.ToUpper(str)), nil })
+			RegisterDefaultFunc("concurrent", func() (string, error) { return "node", nil })
+			RegisterRenderer("concurrent", RendererFunc(renderText))
+		}()
+	}
+	wg.Wait()
+}
+
+type skippedNode struct {
+	Name string `env:"NAME"`
+	Next *skippedNode
+}
+
+func TestSkippedFields(t *testing.T) {
+	cfg := struct {
+		Host    string            `env:"HOST"`
+		Cache   map[string]string `env:"-"`
+		Runtime struct {
+			Node skippedNode
+		} `env:"-"`
+	}{Cache: map[string]string{"a": "b"}}
+
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"HOST": "localhost"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Host != "localhost" || cfg.Cache["a"] != "b" {
+		t.Errorf("Expected the skipped fields to be left untouched, got %v", cfg)
+	}
+	expected := "{\n    Host: localhost\n}"
+	if got := FormatString(cfg); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	schema, err := DescribeConfig(cfg)
+	if err != nil || len(schema.Fields) != 1 || schema.Fields[0].Path != "Host" {
+		t.Errorf("Expected only Host in the schema, got %v, %v", schema, err)
+	}
+}
+
+func TestLoadEnvGroup(t *testing.T) {
+	type GroupConfig struct {
+		Host   string `env:"HOST"`
+		DBHost string `env:"DB_HOST;group:database"`
+		DBPort int    `env:"DB_PORT;default:5432;group:database"`
+		Cache  string `env:"CACHE_URL;group:cache"`
+	}
+	cfg := GroupConfig{Host: "kept", Cache: "kept"}
+	err := LoadEnvGroup(&cfg, "database", WithSources(MapSource{"DB_HOST": "db"}))
+	if err != nil || cfg != (GroupConfig{Host: "kept", DBHost: "db", DBPort: 5432, Cache: "kept"}) {
+		t.Errorf("Expected only the database group to be loaded, got %v, %v", cfg, err)
+	}
+
+	err = LoadEnvWithOptions(&cfg, WithSources(MapSource{"DB_HOST": "db2", "CACHE_URL": "redis://cache"}), WithGroups("database", "cache"))
+	if err != nil || cfg.Host != "kept" || cfg.DBHost != "db2" || cfg.Cache != "redis://cache" {
+		t.Errorf("Expected the database and cache groups to be loaded, got %v, %v", cfg, err)
+	}
+
+	err = LoadEnvGroup(&cfg, "databse", WithSources(MapSource{}))
+	var groupErr *GroupError
+	if !errors.As(err, &groupErr) || groupErr.Groups[0] != "databse" {
+		t.Errorf("Expected a GroupError, got %v", err)
+	}
+	// the options of the caller are not appended to in place
+	opts := make([]Option, 1, 2)
+	opts[0] = WithSources(MapSource{"DB_HOST": "db"})
+	err = LoadEnvGroup(&cfg, "database", opts...)
+	if err != nil || opts[:2][1] != nil {
+		t.Errorf("Expected the options to be left untouched, got %v", err)
+	}
+}
+
+func TestStructMetaCache(t *testing.T) {
+	type cachedConfig struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT;default:8080"`
+	}
+	env := MapSource{"HOST": "a", "APP_HOST": "b", "APP_PORT": "9090"}
+	for i := 0; i < 2; i++ {
+		cfg := cachedConfig{}
+		err := LoadEnvWithOptions(&cfg, WithSources(env), WithPrefix("APP_"), WithAllOptional())
+		if err != nil || cfg.Host != "b" || cfg.Port != 9090 {
+			t.Errorf("Expected {b 9090}, got %v, %v", cfg, err)
+		}
+		cfg = cachedConfig{}
+		err = LoadEnvWithOptions(&cfg, WithSources(env))
+		if err != nil || cfg.Host != "a" || cfg.Port != 8080 {
+			t.Errorf("Expected the cached tags to be unchanged by earlier loads, got %v, %v", cfg, err)
+		}
+	}
+}
+
+func TestTimeFields(t *testing.T) {
+	clearTestEnv()
+
+	env := map[string]string{
+		"TIMEOUT":   "1m30s",
+		"STARTS_AT": "2024-05-01T12:00:00Z",
+		"RELEASE":   "2024-05-01",
+	}
+	for key, value := range env {
+		err := os.Setenv(key, value)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	someStruct := struct {
+		Timeout  time.Duration `env:"TIMEOUT"`
+		StartsAt time.Time     `env:"STARTS_AT"`
+		Release  time.Time     `env:"RELEASE;layout:DateOnly"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Timeout != 90*time.Second {
+		t.Errorf("Expected TIMEOUT=1m30s, got %s", someStruct.Timeout)
+	}
+	if !someStruct.StartsAt.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected STARTS_AT=2024-05-01T12:00:00Z, got %s", someStruct.StartsAt)
+	}
+	if !someStruct.Release.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected RELEASE=2024-05-01, got %s", someStruct.Release)
+	}
+}
+
+type Celsius float64
+
+func (Celsius) UnmarshalEnvFormat(str string, format string) (Celsius, error) {
+	value, err := strconv.ParseFloat(str, 64)
+	if format == "fahrenheit" {
+		value = (value - 32) * 5 / 9
+	}
+	return Celsius(value), err
+}
+
+func TestFormatParsers(t *testing.T) {
+	RegisterFormattedEnvType[Celsius]()
+
+	someStruct := struct {
+		StartAt time.Time     `env:"START_AT;format:2006-01-02"`
+		Created time.Time     `env:"CREATED;format:unix"`
+		TTL     time.Duration `env:"TTL;format:seconds"`
+		Timeout time.Duration `env:"TIMEOUT;format:milliseconds"`
+		Dates   []time.Time   `env:"DATES;format:DateOnly;sep:,"`
+		Limit   Celsius       `env:"LIMIT;format:fahrenheit"`
+	}{}
+	err := LoadEnvWithOptions(&someStruct, WithSources(MapSource{
+		"START_AT": "2024-03-01",
+		"CREATED":  "1700000000",
+		"TTL":      "1.5",
+		"TIMEOUT":  "2s",
+		"DATES":    "2024-01-01,2024-01-02",
+		"LIMIT":    "212",
+	}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !someStruct.StartAt.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || !someStruct.Created.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected START_AT=2024-03-01 and CREATED=2023-11-14T22:13:20Z, got %v and %v", someStruct.StartAt, someStruct.Created)
+	}
+	if someStruct.TTL != 1500*time.Millisecond || someStruct.Timeout != 2*time.Second || len(someStruct.Dates) != 2 || someStruct.Limit != 100 {
+		t.Errorf("Expected TTL=1.5s, TIMEOUT=2s, two dates and LIMIT=100, got %v", someStruct)
+	}
+
+	for tag, expected := range map[string]string{
+		"TTL;format:weeks": "error parsing '1.5' as environment variable TTL: unknown duration unit 'weeks', expected nanoseconds, microseconds, milliseconds, seconds, minutes or hours",
+	} {
+		_, err = Get[time.Duration](tag, WithSources(MapSource{"TTL": "1.5"}))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	}
+	_, err = Get[int]("PORT;format:seconds", WithSources(MapSource{"PORT": "1"}))
+	expected := "error parsing '1' as environment variable PORT: unknown format 'seconds', expected json or yaml"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestLoadEnvLenient(t *testing.T) {
+	clearTestEnv()
+
+	err := setTestEnv()
+	if err != nil {
+		t.Errorf("Error setting up test environment, got err %v", err)
+	}
+	err = os.Setenv("PARSE_ERR", "key1")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg := TestConfig{}
+	warnings, err := LoadEnvLenient(&cfg)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", warnings)
+	}
+	expected := "error parsing 'key1' as environment variable PARSE_ERR: invalid map entry 'key1', expected key=value"
+	if warnings[0].Error() != expected {
+		t.Errorf("Expected %s, got %s", expected, warnings[0].Error())
+	}
+	if cfg.ParseErr != nil {
+		t.Errorf("Expected PARSE_ERR to be left at its zero value, got %v", cfg.ParseErr)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("Expected PORT=8080, got %d", cfg.Port)
+	}
+}
+
+func TestWithLenient(t *testing.T) {
+	type Config struct {
+		Host    string        `env:"HOST"`
+		Workers int           `env:"WORKERS;default:4;optional"`
+		Ratio   float64       `env:"RATIO;optional"`
+		Timeout time.Duration `env:"TIMEOUT;default:5s"`
+	}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}}))
+	env := MapSource{"HOST": "localhost", "WORKERS": "many", "RATIO": "half"}
+
+	var config Config
+	err := LoadEnvWithOptions(&config, WithSources(env), WithLenient(logger))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Workers != 4 || config.Ratio != 0 || config.Timeout != 5*time.Second {
+		t.Errorf("Expected the malformed optional fields at their default or zero value, got %+v", config)
+	}
+	expected := "level=WARN msg=\"goloadenv: error parsing 'many' as environment variable WORKERS: invalid syntax for int\"\n" +
+		"level=WARN msg=\"goloadenv: error parsing 'half' as environment variable RATIO: invalid syntax for float64\"\n"
+	if logs.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, logs.String())
+	}
+
+	env["TIMEOUT"] = "soon"
+	err = LoadEnvWithOptions(&config, WithSources(env), WithLenient(nil))
+	expected = "error parsing 'soon' as environment variable TIMEOUT: time: invalid duration \"soon\""
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	delete(env, "HOST")
+	delete(env, "TIMEOUT")
+	err = LoadEnvWithOptions(&config, WithSources(env), WithLenient(nil))
+	expected = "environment variable not found: HOST"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestPointerFields(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("RETRIES", "0")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Retries *int           `env:"RETRIES;optional"`
+		Name    *string        `env:"NAME;optional"`
+		Timeout *time.Duration `env:"TIMEOUT;default:5s"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Retries == nil || *someStruct.Retries != 0 {
+		t.Errorf("Expected RETRIES to point to 0, got %v", someStruct.Retries)
+	}
+	if someStruct.Name != nil {
+		t.Errorf("Expected NAME to be nil, got %v", *someStruct.Name)
+	}
+	if someStruct.Timeout == nil || *someStruct.Timeout != 5*time.Second {
+		t.Errorf("Expected TIMEOUT to point to 5s, got %v", someStruct.Timeout)
+	}
+}
+
+func TestDocsLinkInErrors(t *testing.T) {
+	clearTestEnv()
+
+	someStruct := struct {
+		Host string `env:"HOST" docs:"https://wiki.example.com/runbooks/host"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	expected := "environment variable not found: HOST (see https://wiki.example.com/runbooks/host)"
+	if err.Error() != expected {
+		t.Errorf("Expected %s, got %s", expected, err.Error())
+	}
+}
+
+func TestNestedStructPrefix(t *testing.T) {
+	clearTestEnv()
+
+	env := map[string]string{
+		"PRIMARY_DB_HOST": "primary",
+		"REPLICA_DB_HOST": "replica",
+	}
+	for key, value := range env {
+		err := os.Setenv(key, value)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	someStruct := struct {
+		Primary EmbbededStruct `envPrefix:"PRIMARY_"`
+		Replica EmbbededStruct `envPrefix:"REPLICA_"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Primary.Host != "primary" {
+		t.Errorf("Expected PRIMARY_DB_HOST=primary, got %s", someStruct.Primary.Host)
+	}
+	if someStruct.Replica.Host != "replica" {
+		t.Errorf("Expected REPLICA_DB_HOST=replica, got %s", someStruct.Replica.Host)
+	}
+}
+
+func TestLoadEnvAll(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("PORT", "http")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	err = LoadEnvAll(&TestConfig{})
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	expected := "environment variable not found: HOST\n" +
+		"error parsing 'http' as environment variable PORT: invalid syntax for int"
+	if err.Error() != expected {
+		t.Errorf("Expected %s, got %s", expected, err.Error())
+	}
+	var notFoundErr *EnvNotFoundError
+	if !errors.As(err, &notFoundErr) || notFoundErr.Env != "HOST" {
+		t.Errorf("Expected the joined error to contain an EnvNotFoundError for HOST, got %v", err)
+	}
+}
+
+func TestEmptyEnv(t *testing.T) {
+	clearTestEnv()
+
+	for _, key := range []string{"GREETING", "NAME", "LEVEL"} {
+		err := os.Setenv(key, "")
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	someStruct := struct {
+		Greeting string  `env:"GREETING;default:hello"`
+		Name     *string `env:"NAME;optional"`
+		Level    string  `env:"LEVEL;allowempty;default:info"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Greeting != "" {
+		t.Errorf("Expected GREETING to be empty, got %s", someStruct.Greeting)
+	}
+	if someStruct.Name == nil || *someStruct.Name != "" {
+		t.Errorf("Expected NAME to point to an empty string, got %v", someStruct.Name)
+	}
+	if someStruct.Level != "info" {
+		t.Errorf("Expected LEVEL=info, got %s", someStruct.Level)
+	}
+}
+
+func TestLoadEnvConcurrent(t *testing.T) {
+	clearTestEnv()
+
+	err := setTestEnv()
+	if err != nil {
+		t.Errorf("Error setting up test environment, got err %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- LoadEnv(&TestConfig{})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+}
+
+func TestEncodingUnmarshalers(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("BIND_IP", "10.0.0.1")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("ENDPOINT", "https://example.com/api")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		BindIP   net.IP   `env:"BIND_IP"`
+		Endpoint *url.URL `env:"ENDPOINT"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !someStruct.BindIP.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Expected BIND_IP=10.0.0.1, got %s", someStruct.BindIP)
+	}
+	if someStruct.Endpoint == nil || someStruct.Endpoint.Host != "example.com" {
+		t.Errorf("Expected ENDPOINT host example.com, got %v", someStruct.Endpoint)
+	}
+}
+
+type CommonConfig struct {
+	LogLevel string `env:"LOG_LEVEL;default:info"`
+}
+
+type TracingConfig struct {
+	Endpoint string `env:"ENDPOINT;optional"`
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("TRACING_ENDPOINT", "http://collector:4318")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		CommonConfig
+		*TracingConfig `envPrefix:"TRACING_"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.LogLevel != "info" {
+		t.Errorf("Expected LOG_LEVEL=info, got %s", someStruct.LogLevel)
+	}
+	if someStruct.TracingConfig == nil || someStruct.Endpoint != "http://collector:4318" {
+		t.Errorf("Expected TRACING_ENDPOINT=http://collector:4318, got %v", someStruct.TracingConfig)
+	}
+
+	var paths []string
+	err = Iterate(&someStruct, func(f FieldInfo, v reflect.Value) error {
+		paths = append(paths, f.Path+"="+f.Name)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := []string{"LogLevel=LOG_LEVEL", "Endpoint=TRACING_ENDPOINT"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func TestFormatFields(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("FEATURES", `{"a":true,"b":false}`)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("UPSTREAMS", "# upstream servers\n- name: primary\n  hosts:\n  - a.example.com\n  - b.example.com\n  weight: 3\n- name: 'fallback'\n  hosts: [\"c.example.com\"]\n")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("LIMITS", "requests: 100\nburst: 20")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	type upstream struct {
+		Name   string   `json:"name"`
+		Hosts  []string `json:"hosts"`
+		Weight int      `json:"weight"`
+	}
+	someStruct := struct {
+		Features  map[string]bool `env:"FEATURES;format:json"`
+		Upstreams []upstream      `env:"UPSTREAMS;format:yaml"`
+		Limits    struct {
+			Requests int `json:"requests"`
+			Burst    int `json:"burst"`
+		} `env:"LIMITS;format:yaml"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(someStruct.Features, map[string]bool{"a": true, "b": false}) {
+		t.Errorf("Expected FEATURES={a:true,b:false}, got %v", someStruct.Features)
+	}
+	expected := []upstream{
+		{Name: "primary", Hosts: []string{"a.example.com", "b.example.com"}, Weight: 3},
+		{Name: "fallback", Hosts: []string{"c.example.com"}},
+	}
+	if !reflect.DeepEqual(someStruct.Upstreams, expected) {
+		t.Errorf("Expected %v, got %v", expected, someStruct.Upstreams)
+	}
+	if someStruct.Limits.Requests != 100 || someStruct.Limits.Burst != 20 {
+		t.Errorf("Expected LIMITS requests 100 burst 20, got %v", someStruct.Limits)
+	}
+
+	err = os.Setenv("FEATURES", "a: true\n  b: false")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	invalid := struct {
+		Features map[string]bool `env:"FEATURES;format:yaml"`
+	}{}
+	err = LoadEnv(&invalid)
+	expectedErr := "error parsing 'a: true\n  b: false' as environment variable FEATURES: yaml line 2: unexpected indentation"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+}
+
+func TestEncodedByteFields(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("HMAC_KEY", "c2VjcmV0LWtleQ==")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("SALT", "deadbeef")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		HMACKey []byte `env:"HMAC_KEY;secret;encoding:base64;minBytes:10"`
+		Salt    []byte `env:"SALT;encoding:hex"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if string(someStruct.HMACKey) != "secret-key" {
+		t.Errorf("Expected HMAC_KEY=secret-key, got %s", someStruct.HMACKey)
+	}
+	if !reflect.DeepEqual(someStruct.Salt, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("Expected SALT=deadbeef, got %x", someStruct.Salt)
+	}
+
+	err = os.Setenv("HMAC_KEY", "not base64!")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = LoadEnv(&someStruct)
+	expected := "error parsing '****' as environment variable HMAC_KEY: invalid base64: illegal base64 data at input byte 3"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = os.Setenv("HMAC_KEY", "c2VjcmV0LWtleQ")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = LoadEnv(&someStruct)
+	expected = "error parsing '****' as environment variable HMAC_KEY: invalid base64: illegal base64 data at input byte 12"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestListTokenizing(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("HOSTS", "a.example.com,b.example.com")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("LABELS", `["a,b", 'c d', e\,f, [g,h]]`)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Hosts  []string `env:"HOSTS;sep:,"`
+		Labels []string `env:"LABELS"`
+		Empty  []int    `env:"EMPTY;default:[]"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(someStruct.Hosts, []string{"a.example.com", "b.example.com"}) {
+		t.Errorf("Expected HOSTS=[a.example.com b.example.com], got %v", someStruct.Hosts)
+	}
+	expected := []string{"a,b", "c d", " e,f", " [g,h]"}
+	if !reflect.DeepEqual(someStruct.Labels, expected) {
+		t.Errorf("Expected %q, got %q", expected, someStruct.Labels)
+	}
+	if len(someStruct.Empty) != 0 {
+		t.Errorf("Expected EMPTY=[], got %v", someStruct.Empty)
+	}
+
+	elements, err := SplitList("", ",")
+	if err != nil || elements != nil {
+		t.Errorf("Expected no elements, got %q and %v", elements, err)
+	}
+
+	_, err = ParseList(`["a" b]`)
+	if err == nil || err.Error() != "unexpected characters after quoted list element" {
+		t.Errorf("Expected unexpected characters after quoted list element, got %v", err)
+	}
+}
+
+func TestTagValuesWithSeparators(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("PATHS", "/usr/bin;/bin")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		URL       string    `env:"URL;default:https://example.com:8080/path"`
+		Quoted    string    `env:"QUOTED;default:'a;b'"`
+		Escaped   string    `env:"ESCAPED;default:a\\;b"`
+		Paths     []string  `env:"PATHS;sep:';'"`
+		StartedAt time.Time `env:"STARTED_AT;layout:2006-01-02 15:04;default:2024-01-02 03:04"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.URL != "https://example.com:8080/path" {
+		t.Errorf("Expected URL=https://example.com:8080/path, got %s", someStruct.URL)
+	}
+	if someStruct.Quoted != "a;b" || someStruct.Escaped != "a;b" {
+		t.Errorf("Expected QUOTED and ESCAPED to be a;b, got %s and %s", someStruct.Quoted, someStruct.Escaped)
+	}
+	if !reflect.DeepEqual(someStruct.Paths, []string{"/usr/bin", "/bin"}) {
+		t.Errorf("Expected PATHS=[/usr/bin /bin], got %v", someStruct.Paths)
+	}
+	if someStruct.StartedAt.Minute() != 4 {
+		t.Errorf("Expected STARTED_AT=2024-01-02 03:04, got %s", someStruct.StartedAt)
+	}
+
+	_, err = ParseTag("URL;default:'unterminated")
+	if err == nil || err.Error() != "unterminated quoted value in tag" {
+		t.Errorf("Expected unterminated quoted value in tag, got %v", err)
+	}
+}
+
+func TestLoadGeneric(t *testing.T) {
+	type Config struct {
+		Host string `env:"APP_HOST"`
+		Port int    `env:"APP_PORT;default:8080"`
+	}
+	t.Setenv("APP_HOST", "localhost")
+	config, err := Load[Config]()
+	if err != nil || config != (Config{Host: "localhost", Port: 8080}) {
+		t.Errorf("Expected the config to be loaded, got %+v and %v", config, err)
+	}
+
+	config, err = LoadWith[Config](WithSources(MapSource{"APP_HOST": "db", "APP_PORT": "x"}))
+	expected := "error parsing 'x' as environment variable APP_PORT: invalid syntax for int"
+	if err == nil || err.Error() != expected || config != (Config{}) {
+		t.Errorf("Expected %s and a zero config, got %v and %+v", expected, err, config)
+	}
+
+	_, err = Load[*Config]()
+	expected = "config type *goloadenv.Config must be a struct"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestArrayPadding(t *testing.T) {
+	config := struct {
+		Untouched [3]int    `env:"UNTOUCHED"`
+		Zero      [3]int    `env:"ZERO;pad:zero"`
+		Repeat    [4]int    `env:"REPEAT;pad:repeat"`
+		Default   [4]string `env:"DEFAULT;default:[a,b];pad:repeat"`
+		Exact     [2]int    `env:"EXACT;pad:error"`
+	}{Untouched: [3]int{7, 7, 7}, Zero: [3]int{7, 7, 7}}
+	env := MapSource{"UNTOUCHED": "[1]", "ZERO": "[1]", "REPEAT": "[3,2]", "EXACT": "[1,2]"}
+	err := LoadEnvWithOptions(&config, WithSources(env))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Untouched != [3]int{1, 7, 7} || config.Zero != [3]int{1, 0, 0} || config.Repeat != [4]int{3, 2, 2, 2} ||
+		config.Default != [4]string{"a", "b", "b", "b"} || config.Exact != [2]int{1, 2} {
+		t.Errorf("Expected the arrays to be padded, got %+v", config)
+	}
+
+	for tag, expected := range map[string]string{
+		"EXACT;pad:error": "error parsing '[1]' as environment variable EXACT: array size underflow, expected 2, got 1",
+		"EXACT;pad:last":  "error parsing '[1]' as environment variable EXACT: invalid pad 'last', expected error, zero or repeat",
+	} {
+		_, err = Get[[2]int](tag, WithSources(MapSource{"EXACT": "[1]"}))
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	}
+}
+
+func TestTrimmedListElements(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("CORS_ORIGINS", "https://a.com | https://b.com/?q=1,2 | ' padded '")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("HOSTS", "[ a , b ]")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("UNTRIMMED", "[ a , b ]")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Origins   []string `env:"CORS_ORIGINS;sep:|;trim"`
+		Hosts     []string `env:"HOSTS;trim"`
+		Untrimmed []string `env:"UNTRIMMED"`
+	}{}
+
+	err = LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := []string{"https://a.com", "https://b.com/?q=1,2", " padded "}
+	if !reflect.DeepEqual(someStruct.Origins, expected) {
+		t.Errorf("Expected %q, got %q", expected, someStruct.Origins)
+	}
+	if !reflect.DeepEqual(someStruct.Hosts, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %q", someStruct.Hosts)
+	}
+	if !reflect.DeepEqual(someStruct.Untrimmed, []string{" a ", " b "}) {
+		t.Errorf("Expected [\" a \" \" b \"], got %q", someStruct.Untrimmed)
+	}
+}
+
+func TestKeyValueTagSyntax(t *testing.T) {
+	clearTestEnv()
+
+	someStruct := struct {
+		URL    string   `env:"name=DB_URL, default='postgres://u:p@h:5432/db', optional"`
+		Levels []string `env:"name=LEVELS,default='debug,info',sep=','"`
+		Quoted string   `env:"name=QUOTED,default='it\\'s'"`
+		Port   int      `env:"PORT;default:8080"`
+	}{}
+
+	err := LoadEnv(&someStruct)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.URL != "postgres://u:p@h:5432/db" {
+		t.Errorf("Expected DB_URL=postgres://u:p@h:5432/db, got %s", someStruct.URL)
+	}
+	if !reflect.DeepEqual(someStruct.Levels, []string{"debug", "info"}) {
+		t.Errorf("Expected LEVELS=[debug info], got %v", someStruct.Levels)
+	}
+	if someStruct.Quoted != "it's" {
+		t.Errorf("Expected QUOTED=it's, got %s", someStruct.Quoted)
+	}
+	if someStruct.Port != 8080 {
+		t.Errorf("Expected PORT=8080, got %d", someStruct.Port)
+	}
+
+	tags, err := ParseTag("name=PORT,default=8080,optional")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := map[string]string{"name": "PORT", "default": "8080", "optional": ""}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+
+	for tag, expectedErr := range map[string]string{
+		"name=A,default='unterminated": "unterminated quoted value in tag",
+		"name=A,default='a'b":          "unexpected 'b' after quoted value in tag",
+		"name=A,=b":                    "missing name for tag option at offset 7",
+		"name=A,de fault=b":            "invalid tag option name 'de fault'",
+		"name=A,name=B":                "duplicate tag: name",
+		"name=A,min=1,min=2":           "duplicate tag: min",
+		"name=A,default":               "missing value for tag: default",
+	} {
+		_, err = ParseTag(tag)
+		if err == nil || err.Error() != expectedErr {
+			t.Errorf("Expected %s for %s, got %v", expectedErr, tag, err)
+		}
+	}
+}
+
+func TestMustLoadEnvAndLoadEnvOrExit(t *testing.T) {
+	clearTestEnv()
+
+	type Config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+		Old  string `env:"NEW;alias:OLD;optional"`
+	}
+	sources := WithSources(MapSource{"PORT": "x", "OLD": "value"})
+
+	var cfg Config
+	func() {
+		defer func() {
+			expected := "goloadenv: invalid configuration:\n" +
+				"  - environment variable not found: HOST\n" +
+				"  - error parsing 'x' as environment variable PORT: invalid syntax for int"
+			if recovered := recover(); recovered != expected {
+				t.Errorf("Expected panic %s, got %v", expected, recovered)
+			}
+		}()
+		MustLoadEnv(&cfg, sources)
+	}()
+
+	exitCode := -1
+	exit = func(code int) { exitCode = code }
+	defer func() { exit = os.Exit }()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}}))
+	LoadEnvOrExit(&cfg, logger, sources)
+	expected := "level=WARN msg=\"configuration warning\" error=\"environment variable OLD is deprecated, use NEW instead\"\n" +
+		"level=ERROR msg=\"invalid configuration\" error=\"environment variable not found: HOST\"\n" +
+		"level=ERROR msg=\"invalid configuration\" error=\"error parsing 'x' as environment variable PORT: invalid syntax for int\"\n"
+	if logs.String() != expected || exitCode != 1 {
+		t.Errorf("Expected exit code 1 and logs %s, got %d and %s", expected, exitCode, logs.String())
+	}
+
+	exitCode = -1
+	LoadEnvOrExit(&cfg, logger, WithSources(MapSource{"HOST": "localhost", "PORT": "8080"}))
+	if exitCode != -1 || cfg.Port != 8080 {
+		t.Errorf("Expected no exit and PORT=8080, got %d and %d", exitCode, cfg.Port)
+	}
+}
+
+func TestLoadEnvDynamic(t *testing.T) {
+	type PluginDB struct {
+		Host string `env:"HOST"`
+	}
+	timeout, quoted := "5s", "it's;fine"
+	schema := []FieldSpec{
+		{Name: "PLUGIN_URL"},
+		{Name: "PLUGIN_TIMEOUT", Type: reflect.TypeFor[time.Duration](), Default: &timeout},
+		{Name: "PLUGIN_RETRIES", Type: reflect.TypeFor[int](), Optional: true, Options: "min:0;max:10"},
+		{Name: "PLUGIN_NOTE", Default: &quoted},
+		{Name: "PLUGIN_DB_", Type: reflect.TypeFor[PluginDB]()},
+	}
+	values, err := LoadEnvDynamic(schema, WithSources(MapSource{"PLUGIN_URL": "http://plugin", "PLUGIN_DB_HOST": "db"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := map[string]any{
+		"PLUGIN_URL":     "http://plugin",
+		"PLUGIN_TIMEOUT": 5 * time.Second,
+		"PLUGIN_NOTE":    "it's;fine",
+		"PLUGIN_DB_":     PluginDB{Host: "db"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+
+	_, err = LoadEnvDynamic(schema, WithSources(MapSource{"PLUGIN_URL": "http://plugin", "PLUGIN_RETRIES": "11", "PLUGIN_DB_HOST": "db"}))
+	expectedErr := "invalid value '11' for environment variable PLUGIN_RETRIES: must be at most 10"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+	_, err = LoadEnvDynamic([]FieldSpec{{Name: "A;B"}})
+	expectedErr = "invalid environment variable name 'A;B' in field spec"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+	_, err = LoadEnvDynamic([]FieldSpec{{Name: "PLUGIN_"}, {Name: "PLUGIN_", Type: reflect.TypeFor[PluginDB]()}})
+	expectedErr = "duplicate environment variable name 'PLUGIN_' in field spec"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+}
@@ -0,0 +1,92 @@
+package goloadenv
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// structMeta is the metadata of a struct type that loading derives from its struct tags: the load order of its fields
+// and their parsed tags. It is cached per type as it does not change, and must not be modified.
+type structMeta struct {
+	order []int
+	tags  []map[string]string
+	errs  []error
+}
+
+type structMetaKey struct {
+	typ     reflect.Type
+	tagName string
+}
+
+// structMetas caches the structMeta of every loaded struct type by structMetaKey.
+var structMetas sync.Map
+
+// loadStructMeta returns the metadata of a struct type, parsing its struct tags on the first load of the type. The path
+// of the struct is only used in the error for invalid dependencies, which is not cached.
+func loadStructMeta(typ reflect.Type, tagName string, path string) (*structMeta, error) {
+	key := structMetaKey{typ: typ, tagName: tagName}
+	if meta, found := structMetas.Load(key); found {
+		return meta.(*structMeta), nil
+	}
+	meta := &structMeta{tags: make([]map[string]string, typ.NumField()), errs: make([]error, typ.NumField())}
+	for i := range meta.tags {
+		meta.tags[i], meta.errs[i] = parseTags(typ.Field(i), tagName)
+	}
+	order, err := loadOrder(typ, meta.tags, path)
+	if err != nil {
+		return nil, err
+	}
+	meta.order = order
+	structMetas.Store(key, meta)
+	return meta, nil
+}
+
+// fieldTags returns a copy of the parsed tags of the i-th field, which the caller may modify.
+func (m *structMeta) fieldTags(i int) (map[string]string, error) {
+	return maps.Clone(m.tags[i]), m.errs[i]
+}
+
+// loadMark is the number of report entries, errors and warnings recorded when the load of a field starts.
+type loadMark struct {
+	fields   int
+	errs     int
+	warnings int
+}
+
+func (l *loader) mark() loadMark {
+	m := loadMark{errs: len(l.errs), warnings: len(l.warnings)}
+	if l.report != nil {
+		m.fields = len(l.report.Fields)
+	}
+	return m
+}
+
+// declarationOrder moves what was recorded while loading the fields of a struct in load order back into the
+// declaration order of the fields, so reports, collected errors and warnings do not depend on the dependencies between
+// fields. The marks are taken before every field in load order and after the last one.
+func (l *loader) declarationOrder(order []int, marks []loadMark) {
+	if slices.IsSorted(order) {
+		return
+	}
+	if l.report != nil {
+		l.report.Fields = reorder(l.report.Fields, order, marks, func(m loadMark) int { return m.fields })
+	}
+	l.errs = reorder(l.errs, order, marks, func(m loadMark) int { return m.errs })
+	l.warnings = reorder(l.warnings, order, marks, func(m loadMark) int { return m.warnings })
+}
+
+// reorder reorders the segments of items recorded per field, as delimited by the marks, from load order to declaration
+// order.
+func reorder[T any](items []T, order []int, marks []loadMark, at func(loadMark) int) []T {
+	segments := make([][]T, len(order))
+	for n, i := range order {
+		segments[i] = items[at(marks[n]):at(marks[n+1])]
+	}
+	reordered := slices.Clone(items[:at(marks[0])])
+	for _, segment := range segments {
+		reordered = append(reordered, segment...)
+	}
+	return append(reordered, items[at(marks[len(order)]):]...)
+}
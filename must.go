@@ -0,0 +1,67 @@
+package goloadenv
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// exit terminates the process, it is replaced in tests.
+var exit = os.Exit
+
+// MustLoadEnv loads environment variables into the provided config struct like LoadEnvWithOptions with WithAllErrors,
+// and panics when the load fails, listing every problem on its own line. It is meant for services that load their
+// config once at startup and cannot run without it.
+//
+// Example:
+//
+//	var cfg Config
+//	goloadenv.MustLoadEnv(&cfg)
+func MustLoadEnv(config interface{}, opts ...Option) {
+	err := LoadEnvWithOptions(config, append([]Option{WithAllErrors()}, opts...)...)
+	if err == nil {
+		return
+	}
+	var message strings.Builder
+	message.WriteString("goloadenv: invalid configuration:")
+	for _, problem := range loadProblems(err) {
+		message.WriteString("\n  - " + strings.ReplaceAll(problem.Error(), "\n", "\n    "))
+	}
+	panic(message.String())
+}
+
+// LoadEnvOrExit loads environment variables into the provided config struct like LoadEnvWithOptions with
+// WithAllErrors. Every warning of the load is logged with the given logger, or slog.Default() when nil, and when the
+// load fails every problem is logged as an error and the process exits with status 1.
+//
+// Example:
+//
+//	var cfg Config
+//	goloadenv.LoadEnvOrExit(&cfg, slog.Default(), goloadenv.WithDotEnv(".env"))
+func LoadEnvOrExit(config interface{}, logger *slog.Logger, opts ...Option) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	report, err := LoadEnvReport(config, append([]Option{WithAllErrors()}, opts...)...)
+	for _, warning := range report.Warnings {
+		logger.Warn("configuration warning", "error", warning)
+	}
+	if err == nil {
+		return
+	}
+	for _, problem := range loadProblems(err) {
+		logger.Error("invalid configuration", "error", problem)
+	}
+	exit(1)
+}
+
+// loadProblems splits the error of a load into the errors joined by WithAllErrors.
+func loadProblems(err error) []error {
+	if _, isBudget := err.(*WarningBudgetError); isBudget {
+		return []error{err}
+	}
+	if joined, isJoined := err.(interface{ Unwrap() []error }); isJoined {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
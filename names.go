@@ -0,0 +1,85 @@
+package goloadenv
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// derivedName returns the variable name derived from the name of a field for WithDerivedNames, or an empty string if
+// the field is not loaded: unexported fields, fields tagged env:"-", and nested structs or indexed slices, whose fields
+// are derived instead.
+func derivedName(field reflect.StructField, tags map[string]string) string {
+	if !field.IsExported() || tags["name"] == "-" {
+		return ""
+	}
+	if _, isIndexed := indexedSlice(field, tags); isIndexed {
+		return ""
+	}
+	typ := field.Type
+	if field.Anonymous && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if isNestedStruct(typ) && tags["format"] == "" {
+		return ""
+	}
+	return snakeCase(field.Name)
+}
+
+// snakeCase converts a CamelCase field name to the SNAKE_CASE name of an environment variable, keeping acronyms
+// together, e.g. MaxIdleConns to MAX_IDLE_CONNS and DBHost to DB_HOST.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				builder.WriteByte('_')
+			}
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return builder.String()
+}
+
+// caseInsensitiveLookup returns a lookup that falls back to the variables whose name only differs in case from the
+// looked up name when it is not found as is. The candidate names are those of the process environment and the given
+// key lists, as a lookup function cannot list its variables.
+// used internally by LoadEnv.
+func caseInsensitiveLookup(lookup func(string) (string, bool), keys [][]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		if value, found := lookup(key); found {
+			return value, true
+		}
+		for _, variable := range os.Environ() {
+			name, _, _ := strings.Cut(variable, "=")
+			if name != key && strings.EqualFold(name, key) {
+				if value, found := lookup(name); found {
+					return value, true
+				}
+			}
+		}
+		for _, names := range keys {
+			for _, name := range names {
+				if name != key && strings.EqualFold(name, key) {
+					if value, found := lookup(name); found {
+						return value, true
+					}
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// mapKeys returns the keys of a map of variables.
+func mapKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,392 @@
+package goloadenv
+
+import (
+	"flag"
+	"log/slog"
+	"time"
+)
+
+// Option customizes the behavior of LoadEnvWithOptions.
+type Option func(*loader)
+
+// WithPrefix prepends the given prefix to the name of every environment variable, e.g. WithPrefix("APP_") loads a
+// field tagged env:"PORT" from APP_PORT.
+func WithPrefix(prefix string) Option {
+	return func(l *loader) {
+		l.prefix = prefix
+	}
+}
+
+// WithTagName reads the field options from the given struct tag instead of the "env" tag.
+func WithTagName(tagName string) Option {
+	return func(l *loader) {
+		l.tagName = tagName
+	}
+}
+
+// WithLookupFunc looks up environment variables with the given function instead of os.LookupEnv.
+func WithLookupFunc(lookup func(key string) (string, bool)) Option {
+	return func(l *loader) {
+		l.lookup = lookup
+	}
+}
+
+// WithSources looks up environment variables in the given sources instead of the process environment, layered in
+// priority order so the first source that has a variable wins, e.g.
+// WithSources(goloadenv.ProcessEnv, goloadenv.MapSource{"PORT": "8080"}) falls back to the map for unset variables.
+func WithSources(sources ...EnvSource) Option {
+	layered := layeredSource(append([]EnvSource{}, sources...))
+	var keys [][]string
+	processEnv := false
+	for _, source := range sources {
+		switch source := source.(type) {
+		case MapSource:
+			keys = append(keys, mapKeys(source))
+		case processEnvSource:
+			processEnv = true
+		}
+	}
+	kv := kvSources(sources)
+	return func(l *loader) {
+		l.lookup = layered.Lookup
+		l.sourceKeys = keys
+		l.listProcessEnv = processEnv
+		l.addKVSources(kv)
+	}
+}
+
+// WithFlags also binds every tagged field to a command-line flag parsed from the given arguments, e.g. os.Args[1:],
+// with flags taking precedence over the environment and the environment over default values. The flag is named by
+// the flag struct tag, or else after the environment variable in lower case with dashes, e.g. -db-host for DB_HOST,
+// and flag:"-" leaves a field without flag. The desc struct tag is used as usage text. The flags are defined on the
+// given flag set so applications can add their own flags, or on a new flag set that returns errors when it is nil. A
+// flag parse error, or flag.ErrHelp for -h, fails the load.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithFlags(nil, os.Args[1:]))
+func WithFlags(flags *flag.FlagSet, args []string) Option {
+	return func(l *loader) {
+		l.flags = true
+		l.flagSet = flags
+		l.flagArgs = args
+	}
+}
+
+// WithConfigFile reads fields with a file struct tag from the given JSON or YAML config file, at the dotted key of the
+// tag, e.g. file:"server.port". The file has the lowest precedence but for default values, so with WithFlags a field
+// tagged env:"PORT" flag:"port" file:"server.port" is read from the -port flag, else PORT, else the file. Fields also
+// need an env tag, as the file values are looked up by variable name. A missing or invalid file fails the load.
+func WithConfigFile(path string) Option {
+	return func(l *loader) {
+		l.configFile = path
+	}
+}
+
+// WithLayerPrefixes looks up every variable in layered environments, one per prefix from the base to the most
+// specific, where a variable of a later layer overrides the same variable of an earlier one. With
+// WithLayerPrefixes("", "STAGING_"), STAGING_DB_HOST overrides DB_HOST, so per-environment or per-tenant overrides can
+// live in a single variable set. The layer prefixes are prepended to the full name, including the prefix of WithPrefix.
+func WithLayerPrefixes(prefixes ...string) Option {
+	return func(l *loader) {
+		l.layerPrefixes = append([]string{}, prefixes...)
+	}
+}
+
+// WithSecretSources looks up the variables of fields with the secret flag in the given sources, in priority order, when
+// they are not found by the lookup, e.g. WithSecretSources(goloadenv.KeychainSource("myapp")) so CLI tools can keep
+// tokens out of shell profiles. Other fields are never looked up in these sources.
+func WithSecretSources(sources ...EnvSource) Option {
+	layered := layeredSource(append([]EnvSource{}, sources...))
+	kv := kvSources(sources)
+	return func(l *loader) {
+		l.secretSources = layered
+		l.addKVSources(kv)
+	}
+}
+
+// WithSecretResolver registers a resolver for the secret references of the secretref tag option with the given
+// scheme, e.g. WithSecretResolver("vault", &goloadenv.VaultResolver{}) for secretref:vault://secret/data/db#password.
+func WithSecretResolver(scheme string, resolver SecretResolver) Option {
+	return func(l *loader) {
+		if l.resolvers == nil {
+			l.resolvers = map[string]SecretResolver{}
+		}
+		l.resolvers[scheme] = resolver
+	}
+}
+
+// WithResolverTimeout bounds every resolution of the secret resolver registered for the given scheme by the timeout,
+// independently of the context of LoadEnvContext, so one slow store cannot consume the whole startup budget. When the
+// resolver times out the field degrades gracefully: it falls back to the last secret resolved for the reference by a
+// load with this option, e.g. by an earlier reload of a Watcher, or else to its default value or to unset when it is
+// optional, and a ResolverTimeoutError is raised as a warning. A field without such a fallback fails the load with the
+// ResolverTimeoutError.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithSecretResolver("vault", &goloadenv.VaultResolver{}),
+//	  goloadenv.WithResolverTimeout("vault", 2*time.Second))
+func WithResolverTimeout(scheme string, timeout time.Duration) Option {
+	bound := &resolverTimeout{timeout: timeout}
+	return func(l *loader) {
+		if l.resolverTimeouts == nil {
+			l.resolverTimeouts = map[string]*resolverTimeout{}
+		}
+		l.resolverTimeouts[scheme] = bound
+	}
+}
+
+// WithSoftDeadline keeps the given margin before the deadline of the context of LoadEnvContext free for the caller.
+// Secret references are not resolved once less than the margin is left, and resolutions still running are abandoned
+// when it is reached, so the load returns a partial config in time instead of failing with the error of the context.
+// The fields left unresolved hold their default value, if any, or are left untouched, and are listed in the
+// PartialLoadError returned by the load, so the caller can decide whether to proceed.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	err := goloadenv.LoadEnvContext(ctx, &cfg, goloadenv.WithSoftDeadline(time.Second))
+//	var partialErr *goloadenv.PartialLoadError
+//	if errors.As(err, &partialErr) {
+//	  log.Printf("starting with defaults for %v", partialErr.Unresolved)
+//	}
+func WithSoftDeadline(margin time.Duration) Option {
+	return func(l *loader) {
+		l.softDeadline = margin
+	}
+}
+
+// WithFileFallback reads every field whose variable is not set from the file named by the variable with the _FILE
+// suffix, as if every field had the file flag, e.g. DB_PASSWORD_FILE=/run/secrets/db_password for DB_PASSWORD.
+func WithFileFallback() Option {
+	return func(l *loader) {
+		l.fileFallback = true
+	}
+}
+
+// WithDerivedNames loads fields without a variable name in their tag from a variable named after the field, converted
+// from CamelCase to SNAKE_CASE, e.g. MaxIdleConns from MAX_IDLE_CONNS, with the prefixes of WithPrefix and envPrefix
+// applied as usual. Fields tagged env:"-" and unexported fields are skipped. Only loading derives names, functions
+// walking the config struct such as Iterate and Format only see tagged fields.
+func WithDerivedNames() Option {
+	return func(l *loader) {
+		l.derivedNames = true
+	}
+}
+
+// WithCaseInsensitiveNames matches variable names regardless of case when a variable is not set under its exact name,
+// e.g. a field tagged env:"DB_HOST" is loaded from db_host. Names are matched against the process environment, the
+// .env files and the MapSources of WithSources, as other lookups cannot list their variables.
+func WithCaseInsensitiveNames() Option {
+	return func(l *loader) {
+		l.caseInsensitive = true
+	}
+}
+
+// WithStrictMode rejects tags with unknown options, catching typos such as env:"PORT;optinal" that would otherwise be
+// silently ignored.
+func WithStrictMode() Option {
+	return func(l *loader) {
+		l.strict = true
+	}
+}
+
+// WithAllRequired requires every tagged field to be set or have a default value, ignoring the optional flag, for
+// projects that want no variable to be silently skipped.
+func WithAllRequired() Option {
+	return func(l *loader) {
+		l.requirement = allRequired
+	}
+}
+
+// WithAllOptional makes every tagged field optional unless it has the required flag, for projects that prefer to opt
+// in to required variables rather than opt out.
+func WithAllOptional() Option {
+	return func(l *loader) {
+		l.requirement = allOptional
+	}
+}
+
+// WithExpand expands $VAR and ${VAR} references in the values of all fields, as if every field had the expand flag.
+func WithExpand() Option {
+	return func(l *loader) {
+		l.expand = true
+	}
+}
+
+// WithStrictBool only accepts true and false for boolean fields, as if every field had the strictbool flag, rejecting
+// the human-friendly values like yes, on and enabled.
+func WithStrictBool() Option {
+	return func(l *loader) {
+		l.strictBool = true
+	}
+}
+
+// WithIgnoreUnsupported skips fields whose type cannot be parsed from an environment variable, such as channels,
+// functions and interfaces without a registered factory, instead of failing with an UnsupportedFieldError. The skipped
+// fields are listed in Report.Skipped.
+func WithIgnoreUnsupported() Option {
+	return func(l *loader) {
+		l.ignoreUnsupported = true
+	}
+}
+
+// WithAllErrors collects every missing or unparseable variable instead of stopping at the first one, the load then
+// fails with all of them joined into a single error in the declaration order of the fields, also when envDependsOn or
+// a template default loads a field earlier. The errors of fields in nested structs include the path of the field.
+func WithAllErrors() Option {
+	return func(l *loader) {
+		l.collect = true
+	}
+}
+
+// WithLenient downgrades parse errors on optional fields to warnings logged with the given logger, if not nil, as in
+// LoadEnvLenient: a malformed optional value sets the field to its default value, or leaves it at its zero value when
+// it has none, instead of failing the load, so new values can be rolled out gradually. Parse errors on required fields
+// and missing required environment variables still fail the load.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithLenient(slog.Default()))
+func WithLenient(logger *slog.Logger) Option {
+	return func(l *loader) {
+		l.lenient = true
+		if logger != nil {
+			l.logger = logger
+		}
+	}
+}
+
+// WithLogger logs the warnings raised during the load, such as a shadowed variable conflicting with its new name,
+// with the given logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *loader) {
+		l.logger = logger
+	}
+}
+
+// WithMaxWarnings fails the load with a WarningBudgetError when it raises more than the given number of warnings, such
+// as a shadowed variable conflicting with its new name, so teams can lower the number over time to ratchet their
+// configuration hygiene. WithMaxWarnings(0) tolerates no warnings.
+func WithMaxWarnings(warnings int) Option {
+	return func(l *loader) {
+		l.maxWarnings = warnings
+	}
+}
+
+// WithReportFile writes a JSON report of the load to the given path after every load, successful or not, so
+// orchestrators and debugging tools can inspect why a container failed at startup, see WriteReportFile for its
+// content. An empty path, e.g. from an unset variable, writes no report. An error writing the report fails the load.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithReportFile(os.Getenv("CONFIG_REPORT_FILE")))
+func WithReportFile(path string) Option {
+	return func(l *loader) {
+		l.reportFile = path
+	}
+}
+
+// WithHook registers a hook receiving the events of the load, and of the reloads of a Watcher created with the
+// option. It can be given multiple times to register multiple hooks.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithHook(metricsHook))
+func WithHook(hook Hook) Option {
+	return func(l *loader) {
+		l.hooks = append(l.hooks, hook)
+	}
+}
+
+// WithTracer traces the load, and the reloads of a Watcher created with the option, with the given tracer: a
+// goloadenv.load span for every load with the number of fields, defaults and validation failures as attributes, and a
+// goloadenv.source span for every call to a key/value source or secret resolver. The spans are children of the span in
+// the context of LoadEnvContext, if any.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvContext(ctx, &cfg, goloadenv.WithTracer(otelTracer{tracer: otel.Tracer("config")}))
+func WithTracer(tracer Tracer) Option {
+	return func(l *loader) {
+		l.tracer = tracer
+	}
+}
+
+// WithMetrics records the statistics of the load, and of the reloads of a Watcher created with the option, with the
+// given metrics: the duration, the number of defaults applied and of validation failures of every load, and the
+// latency of every call to a key/value source or secret resolver.
+func WithMetrics(metrics Metrics) Option {
+	return func(l *loader) {
+		l.metrics = metrics
+	}
+}
+
+// WithDotEnv reads variables from the given .env files, or ".env" when no paths are given, without modifying the
+// process environment. Variables found by the lookup take precedence over the files, see LoadDotEnv for the file
+// format.
+func WithDotEnv(paths ...string) Option {
+	return func(l *loader) {
+		l.dotEnv = append([]string{}, paths...)
+	}
+}
+
+// WithDotEnvOverride reads variables from the given .env files like WithDotEnv, but the files take precedence over the
+// variables found by the lookup.
+func WithDotEnvOverride(paths ...string) Option {
+	return func(l *loader) {
+		l.dotEnv = append([]string{}, paths...)
+		l.dotEnvOverride = true
+	}
+}
+
+// WithOptionalDotEnv skips the .env files of WithDotEnv, WithDotEnvOverride or LoadWithDotenv that do not exist
+// instead of failing the load, for files such as .env.local that are only present in development.
+func WithOptionalDotEnv() Option {
+	return func(l *loader) {
+		l.dotEnvOptional = true
+	}
+}
+
+// WithUserEnvFile reads variables from the per-user .env file of the given application, see UserEnvFile, at the lowest
+// precedence, below the lookup and the files of WithDotEnv, so developers can keep personal settings out of the .env
+// file of a repository. The load does not fail when the file or the user configuration directory does not exist.
+func WithUserEnvFile(app string) Option {
+	return func(l *loader) {
+		l.userEnvApp = app
+	}
+}
+
+// WithMaxDepth fails the load with a LimitError when nested structs are nested deeper than the given depth, the
+// fields of the config struct itself are at depth 0.
+func WithMaxDepth(depth int) Option {
+	return func(l *loader) {
+		l.limits.maxDepth = depth
+	}
+}
+
+// WithMaxFields fails the load with a LimitError when the config struct has more than the given number of tagged
+// fields.
+func WithMaxFields(fields int) Option {
+	return func(l *loader) {
+		l.limits.maxFields = fields
+	}
+}
+
+// WithMaxValueSize fails the load with a LimitError when the value of a variable, or the default value of a field, is
+// longer than the given number of bytes.
+func WithMaxValueSize(size int) Option {
+	return func(l *loader) {
+		l.limits.maxValueSize = size
+	}
+}
+
+// WithGroups only loads the fields with the group tag option set to one of the given groups, leaving every other field
+// untouched, see LoadEnvGroup. The load fails with a GroupError when no field is in any of the groups.
+func WithGroups(groups ...string) Option {
+	return func(l *loader) {
+		l.groups = append(l.groups, groups...)
+	}
+}
@@ -0,0 +1,662 @@
+package goloadenv
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadEnvWithOptions(t *testing.T) {
+	clearTestEnv()
+
+	env := map[string]string{
+		"APP_HOST": "localhost",
+		"APP_PORT": "8080",
+	}
+	someStruct := struct {
+		Host string `config:"HOST"`
+		Port int    `config:"PORT"`
+	}{}
+
+	err := LoadEnvWithOptions(&someStruct,
+		WithPrefix("APP_"),
+		WithTagName("config"),
+		WithLookupFunc(func(key string) (string, bool) {
+			value, found := env[key]
+			return value, found
+		}),
+	)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Host != "localhost" {
+		t.Errorf("Expected HOST=localhost, got %s", someStruct.Host)
+	}
+	if someStruct.Port != 8080 {
+		t.Errorf("Expected PORT=8080, got %d", someStruct.Port)
+	}
+}
+
+func TestLoadEnvWithStrictMode(t *testing.T) {
+	clearTestEnv()
+
+	someStruct := struct {
+		Host string `env:"HOST;optinal"`
+	}{}
+
+	err := LoadEnvWithOptions(&someStruct, WithStrictMode())
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	expected := "error getting tags for field: 'Host': unknown tag option: optinal"
+	if err.Error() != expected {
+		t.Errorf("Expected %s, got %s", expected, err.Error())
+	}
+}
+
+func TestShadowedVariable(t *testing.T) {
+	clearTestEnv()
+
+	env := map[string]string{
+		"DATABASE_HOST": "old",
+		"DB_PORT":       "5432",
+		"DATABASE_PORT": "5433",
+	}
+	lookup := WithLookupFunc(func(key string) (string, bool) {
+		value, found := env[key]
+		return value, found
+	})
+	someStruct := struct {
+		Host string `env:"DB_HOST;shadow:DATABASE_HOST"`
+		Port int    `env:"DB_PORT;shadow:DATABASE_PORT"`
+	}{}
+
+	err := LoadEnvWithOptions(&someStruct, lookup)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Host != "old" {
+		t.Errorf("Expected DB_HOST=old, got %s", someStruct.Host)
+	}
+	if someStruct.Port != 5432 {
+		t.Errorf("Expected DB_PORT=5432, got %d", someStruct.Port)
+	}
+
+	clearTestEnv()
+	err = LoadEnvWithOptions(&someStruct, lookup, WithStrictMode())
+	expected := "environment variables DB_PORT and DATABASE_PORT are both set with different values"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, lookup, WithMaxWarnings(1))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = LoadEnvWithOptions(&someStruct, lookup, WithMaxWarnings(0))
+	expected = "load raised 1 warnings, more than the maximum of 0: environment variables DB_PORT and DATABASE_PORT are both set with different values"
+	var shadowErr *ShadowMismatchError
+	if !errors.As(err, &shadowErr) || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestAliasedVariable(t *testing.T) {
+	someStruct := struct {
+		Host  string `env:"DB_HOST;alias:DATABASE_HOST,PGHOST"`
+		Port  int    `env:"DB_PORT;alias:PGPORT;deprecated:'PGPORT is removed in v3'"`
+		Debug bool   `env:"DEBUG;optional;deprecated:'use LOG_LEVEL'"`
+	}{}
+	env := MapSource{"PGHOST": "old", "PGPORT": "5433", "DEBUG": "true"}
+	report, err := LoadEnvReport(&someStruct, WithSources(env))
+	if err != nil || someStruct.Host != "old" || someStruct.Port != 5433 || !someStruct.Debug {
+		t.Errorf("Expected the aliases to be read, got %v, %v", someStruct, err)
+	}
+	expected := []string{
+		"environment variable PGHOST is deprecated, use DB_HOST instead",
+		"environment variable PGPORT is deprecated: PGPORT is removed in v3",
+		"environment variable DEBUG is deprecated: use LOG_LEVEL",
+	}
+	var warning *DeprecationWarning
+	if len(report.Warnings) != len(expected) || !errors.As(report.Warnings[0], &warning) || warning.Replacement != "DB_HOST" {
+		t.Fatalf("Expected %d deprecation warnings, got %v", len(expected), report.Warnings)
+	}
+	for i, warning := range report.Warnings {
+		if warning.Error() != expected[i] {
+			t.Errorf("Expected %s, got %v", expected[i], warning)
+		}
+	}
+
+	env = MapSource{"DB_HOST": "new", "DATABASE_HOST": "old", "DB_PORT": "5432"}
+	report, err = LoadEnvReport(&someStruct, WithSources(env))
+	if err != nil || someStruct.Host != "new" || len(report.Warnings) != 0 {
+		t.Errorf("Expected the new names to win without warnings, got %v, %v, %v", someStruct, report.Warnings, err)
+	}
+}
+
+func TestFallbackNameChain(t *testing.T) {
+	type Config struct {
+		URL   string `env:"DATABASE_URL|DB_URL|POSTGRES_URL"`
+		Cache string `env:"name=REDIS_URL|REDISCLOUD_URL,default=redis://localhost"`
+		Queue string `env:"QUEUE_URL;fallback:AMQP_URL;optional"`
+	}
+	for _, test := range []struct {
+		env      map[string]string
+		expected Config
+	}{
+		{map[string]string{"DATABASE_URL": "a", "DB_URL": "b", "POSTGRES_URL": "c"}, Config{URL: "a", Cache: "redis://localhost"}},
+		{map[string]string{"POSTGRES_URL": "c", "DB_URL": "b", "REDISCLOUD_URL": "r"}, Config{URL: "b", Cache: "r"}},
+		{map[string]string{"POSTGRES_URL": "c", "AMQP_URL": "q"}, Config{URL: "c", Cache: "redis://localhost", Queue: "q"}},
+	} {
+		var cfg Config
+		report, err := LoadEnvReport(&cfg, WithSources(MapSource(test.env)))
+		if err != nil || len(report.Warnings) != 0 {
+			t.Errorf("Expected no error and no warnings for %v, got %v and %v", test.env, err, report.Warnings)
+		}
+		if cfg != test.expected {
+			t.Errorf("Expected %+v for %v, got %+v", test.expected, test.env, cfg)
+		}
+	}
+
+	var cfg Config
+	err := LoadEnvFromMap(&cfg, map[string]string{})
+	expected := "environment variable not found: DATABASE_URL"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	_, err = ParseTag("A|B;fallback:C")
+	if err == nil || err.Error() != "duplicate tag: fallback" {
+		t.Errorf("Expected duplicate tag: fallback, got %v", err)
+	}
+}
+
+func TestWithAccessLog(t *testing.T) {
+	clearTestEnv()
+
+	err := setTestEnv()
+	if err != nil {
+		t.Errorf("Error setting up test environment, got err %v", err)
+	}
+
+	var log AccessLog
+	err = LoadEnvWithOptions(&TestConfig{}, WithAccessLog(&log))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := []string{"DB_HOST", "DEFAULT", "HOST", "OPTIONAL", "PARSE_EMBEDDED_ERR", "PARSE_ERR", "PORT"}
+	if !reflect.DeepEqual(log.Keys(), expected) {
+		t.Errorf("Expected %v, got %v", expected, log.Keys())
+	}
+}
+
+func TestWithRejectUnknown(t *testing.T) {
+	t.Setenv("MYAPP_DB_HOST", "db")
+	t.Setenv("MYAPP_DB_HSOT", "typo")
+	t.Setenv("MYAPP_LEGACY_PORT", "5432")
+	t.Setenv("MYAPP_TENANT_ACME_HOST", "acme")
+	t.Setenv("MYAPP_TENANT_ACME_HSOT", "typo")
+	t.Setenv("OTHER_SETTING", "ignored")
+
+	type Tenant struct {
+		Host string `env:"HOST"`
+	}
+	type Config struct {
+		Host    string            `env:"DB_HOST"`
+		Port    int               `env:"DB_PORT|LEGACY_PORT"`
+		User    string            `env:"DB_USER;optional"`
+		Tenants map[string]Tenant `env:"TENANT_;prefixmap"`
+	}
+	var config Config
+	err := LoadEnvWithOptions(&config, WithPrefix("MYAPP_"), WithRejectUnknown("MYAPP_"))
+	expected := "unknown environment variables: MYAPP_DB_HSOT, MYAPP_TENANT_ACME_HSOT"
+	var unknownErr *UnknownVariableError
+	if !errors.As(err, &unknownErr) || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	if config.Port != 5432 || config.Tenants["ACME"].Host != "acme" {
+		t.Errorf("Expected the known variables to be loaded, got %+v", config)
+	}
+
+	err = LoadEnvWithOptions(&config, WithPrefix("MYAPP_"), WithRejectUnknown("MYAPP_"), WithSources(MapSource{"MYAPP_USR": "x"}, ProcessEnv))
+	expected = "unknown environment variables: MYAPP_DB_HSOT, MYAPP_TENANT_ACME_HSOT, MYAPP_USR"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	source := MapSource{"MYAPP_DB_HOST": "db", "MYAPP_DB_PORT": "5432", "MYAPP_TENANT_ACME_HOST": "acme", "MYAPP_USR": "x"}
+	err = LoadEnvWithOptions(&config, WithPrefix("MYAPP_"), WithRejectUnknown("MYAPP_"), WithSources(source))
+	expected = "unknown environment variable: MYAPP_USR"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = LoadEnvWithOptions(&config, WithPrefix("MYAPP_"), WithRejectUnknown("OTHER_NAMESPACE_"))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestWithFieldHook(t *testing.T) {
+	type Config struct {
+		Mode    string        `env:"MODE;lowercase"`
+		Region  string        `env:"REGION;default:EU-WEST"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+	var fields []string
+	record := WithFieldHook(func(f FieldInfo, raw string) (string, error) {
+		fields = append(fields, fmt.Sprintf("%s %s %s=%s", f.Path, f.Type, f.Name, raw))
+		return raw, nil
+	})
+	lowercase := WithFieldHook(func(f FieldInfo, raw string) (string, error) {
+		if _, ok := f.Tags["lowercase"]; ok {
+			return strings.ToLower(raw), nil
+		}
+		return raw, nil
+	})
+	seconds := WithFieldHook(func(f FieldInfo, raw string) (string, error) {
+		if f.Type != reflect.TypeFor[time.Duration]() {
+			return raw, nil
+		}
+		if _, err := strconv.Atoi(raw); err != nil {
+			return "", errors.New("expected a number of seconds")
+		}
+		return raw + "s", nil
+	})
+	env := MapSource{"MODE": "Production", "TIMEOUT": "30"}
+
+	var config Config
+	err := LoadEnvWithOptions(&config, WithSources(env), record, lowercase, seconds)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Mode != "production" || config.Region != "EU-WEST" || config.Timeout != 30*time.Second {
+		t.Errorf("Expected the hooks to transform the values, got %+v", config)
+	}
+	expected := []string{"Mode string MODE=Production", "Region string REGION=EU-WEST", "Timeout time.Duration TIMEOUT=30"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, fields)
+	}
+
+	env["TIMEOUT"] = "30s"
+	err = LoadEnvWithOptions(&config, WithSources(env), seconds)
+	expectedErr := "field hook failed for environment variable TIMEOUT: expected a number of seconds"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
+}
+
+func TestRequiredModes(t *testing.T) {
+	clearTestEnv()
+
+	someStruct := struct {
+		Host  string `env:"HOST;required"`
+		Debug bool   `env:"DEBUG;optional"`
+		Port  int    `env:"PORT"`
+	}{}
+
+	err := LoadEnvWithOptions(&someStruct, WithAllOptional(), WithAllErrors())
+	expected := "environment variable not found: HOST"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithAllRequired(), WithAllErrors())
+	expected = "environment variable not found: HOST\n" +
+		"environment variable not found: DEBUG\n" +
+		"environment variable not found: PORT"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	clearTestEnv()
+
+	env := map[string]string{
+		"HOST":        "localhost",
+		"DB_HOST":     "db.example.com",
+		"DB_PASSWORD": "hunter2",
+	}
+	lookup := func(key string) (string, bool) {
+		value, found := env[key]
+		return value, found
+	}
+	someStruct := struct {
+		Host string `env:"HOST"`
+		DB   struct {
+			Host     string `env:"HOST"`
+			Password string `env:"PASSWORD"`
+			Pool     struct {
+				Size int `env:"POOL_SIZE;default:4"`
+			}
+		} `envPrefix:"DB_"`
+	}{}
+
+	err := LoadEnvWithOptions(&someStruct, WithLookupFunc(lookup), WithMaxDepth(2), WithMaxFields(4), WithMaxValueSize(14))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	tests := map[string]Option{
+		"limit exceeded: depth of DB.Pool exceeds the maximum of 1":       WithMaxDepth(1),
+		"limit exceeded: fields of DB_PASSWORD exceeds the maximum of 2":  WithMaxFields(2),
+		"limit exceeded: value size of DB_HOST exceeds the maximum of 10": WithMaxValueSize(10),
+	}
+	for expected, option := range tests {
+		err = LoadEnvWithOptions(&someStruct, WithLookupFunc(lookup), option)
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) || limitErr.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	}
+}
+
+func TestWithSources(t *testing.T) {
+	clearTestEnv()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(path, []byte("HOST=from-file\nPORT=9090\nOPTIONAL=from-file\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	dotEnv, err := DotEnvSource(path)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("HOST", "from-env")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT"`
+		Optional string `env:"OPTIONAL"`
+	}{}
+	err = LoadEnvWithOptions(&someStruct, WithSources(ProcessEnv, MapSource{"PORT": "8080"}, dotEnv))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Host != "from-env" || someStruct.Port != 8080 || someStruct.Optional != "from-file" {
+		t.Errorf("Expected HOST=from-env, PORT=8080 and OPTIONAL=from-file, got %v", someStruct)
+	}
+
+	secrets := struct {
+		Password string `env:"DB_PASSWORD;secret"`
+		Token    string `env:"API_TOKEN;secret"`
+	}{}
+	ssm := MapSource{"/myapp/db_password": "hunter2"}
+	kv := NewKVSource(KVFetcherFunc(func(context.Context) (map[string]string, error) {
+		return map[string]string{"secret/myapp#API_TOKEN": "token"}, nil
+	}), 0)
+	err = LoadEnvWithOptions(&secrets, WithSources(MapSource{}), WithSecretSources(
+		NamespacedSource(ssm, KeyTemplate("/myapp/{lower}")),
+		NamespacedSource(kv, KeyTemplate("secret/myapp#{name}")),
+	))
+	if err != nil || secrets.Password != "hunter2" || secrets.Token != "token" {
+		t.Errorf("Expected the secrets to be read under their mapped keys, got %v, %v", secrets, err)
+	}
+}
+
+func TestLoadEnvFromMap(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("PORT", "9090")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	type Config struct {
+		Host string `env:"HOST;default:localhost"`
+		Port int    `env:"PORT"`
+	}
+	for _, test := range []struct {
+		env      map[string]string
+		expected Config
+		err      string
+	}{
+		{env: map[string]string{"PORT": "8080"}, expected: Config{Host: "localhost", Port: 8080}},
+		{env: map[string]string{"HOST": "db", "PORT": "5432"}, expected: Config{Host: "db", Port: 5432}},
+		{env: map[string]string{}, err: "environment variable not found: PORT"},
+	} {
+		var cfg Config
+		err := LoadEnvFromMap(&cfg, test.env)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Expected %s for %v, got %v", test.err, test.env, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected no error for %v, got %v", test.env, err)
+		}
+		if cfg != test.expected {
+			t.Errorf("Expected %+v for %v, got %+v", test.expected, test.env, cfg)
+		}
+	}
+
+	var prefixed Config
+	err = LoadEnvFromMap(&prefixed, map[string]string{"APP_PORT": "80"}, WithPrefix("APP_"))
+	if err != nil || prefixed.Port != 80 {
+		t.Errorf("Expected APP_PORT=80, got %d and %v", prefixed.Port, err)
+	}
+}
+
+func TestDerivedAndCaseInsensitiveNames(t *testing.T) {
+	someStruct := struct {
+		MaxIdleConns int
+		DBHost       string
+		Skipped      string `env:"-"`
+		Port         int    `env:"PORT"`
+		TLS          struct {
+			CertFile string
+		} `envPrefix:"TLS_"`
+		hidden string
+	}{}
+	env := MapSource{"APP_MAX_IDLE_CONNS": "5", "APP_DB_HOST": "db", "APP_SKIPPED": "x", "app_port": "8080", "App_Tls_Cert_File": "cert.pem"}
+	err := LoadEnvWithOptions(&someStruct, WithSources(env), WithPrefix("APP_"), WithDerivedNames(), WithCaseInsensitiveNames())
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.MaxIdleConns != 5 || someStruct.DBHost != "db" || someStruct.Skipped != "" || someStruct.Port != 8080 || someStruct.TLS.CertFile != "cert.pem" || someStruct.hidden != "" {
+		t.Errorf("Expected MAX_IDLE_CONNS=5, DB_HOST=db, PORT=8080 and TLS_CERT_FILE=cert.pem, got %v", someStruct)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(env), WithPrefix("APP_"), WithDerivedNames())
+	expected := "environment variable not found: APP_PORT"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestWithFlags(t *testing.T) {
+	someStruct := struct {
+		Host    string `env:"DB_HOST" desc:"Database host"`
+		Port    int    `env:"DB_PORT;default:5432"`
+		Verbose bool   `env:"VERBOSE;optional" flag:"v"`
+		Secret  string `env:"SECRET;optional" flag:"-"`
+	}{}
+	env := MapSource{"APP_DB_HOST": "from-env", "APP_DB_PORT": "6432"}
+	report, err := LoadEnvReport(&someStruct, WithSources(env), WithPrefix("APP_"), WithFlags(nil, []string{"-db-host", "from-flag", "-v"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Host != "from-flag" || someStruct.Port != 6432 || !someStruct.Verbose {
+		t.Errorf("Expected DB_HOST=from-flag, DB_PORT=6432 and VERBOSE=true, got %v", someStruct)
+	}
+	if report.Fields[0].Origin != OriginFlag || report.Fields[1].Origin != OriginEnv {
+		t.Errorf("Expected origins flag and env, got %s and %s", report.Fields[0].Origin, report.Fields[1].Origin)
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	err = LoadEnvWithOptions(&someStruct, WithSources(env), WithFlags(flags, []string{"-secret", "x"}))
+	expected := "flag provided but not defined: -secret"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	duplicate := struct {
+		Host    string `env:"DB_HOST"`
+		Replica string `env:"REPLICA_HOST" flag:"db-host"`
+	}{}
+	err = LoadEnvWithOptions(&duplicate, WithSources(env), WithFlags(nil, nil))
+	expected = "flag -db-host of environment variable REPLICA_HOST is already defined"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestWatcherWithFlags(t *testing.T) {
+	type Config struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT;default:5432"`
+	}
+	env := MapSource{"DB_HOST": "from-env"}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	w, err := NewWatcher[Config](WithSources(env), WithFlags(flags, []string{"-db-port", "6432"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	env["DB_HOST"] = "reloaded"
+	changes, err := w.Reload()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(changes) != 1 || *w.Config() != (Config{Host: "reloaded", Port: 6432}) {
+		t.Errorf("Expected the host to change and the flag to be kept, got %v and %+v", changes, *w.Config())
+	}
+}
+
+func TestBindToFlagSet(t *testing.T) {
+	someStruct := struct {
+		Host     string        `env:"DB_HOST" desc:"Database host"`
+		Port     int           `env:"DB_PORT;default:5432;min:1"`
+		Timeout  time.Duration `env:"TIMEOUT;default:5s"`
+		Verbose  bool          `env:"VERBOSE;optional" flag:"v"`
+		Password string        `env:"DB_PASSWORD;secret"`
+		Ignored  string        `env:"IGNORED" flag:"-"`
+	}{Host: "from-env", Password: "hunter2"}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	err := BindToFlagSet(flags, &someStruct)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if someStruct.Port != 5432 || someStruct.Timeout != 5*time.Second {
+		t.Errorf("Expected the defaults to be applied, got %v", someStruct)
+	}
+	if f := flags.Lookup("db-host"); f == nil || f.DefValue != "from-env" || f.Usage != "Database host" {
+		t.Errorf("Expected the db-host flag with default from-env, got %v", f)
+	}
+	if f := flags.Lookup("db-password"); f == nil || f.DefValue != secretMask {
+		t.Errorf("Expected the default of the secret flag to be masked, got %v", f)
+	}
+	if flags.Lookup("ignored") != nil {
+		t.Errorf("Expected no flag for a field tagged flag:\"-\"")
+	}
+
+	err = flags.Parse([]string{"-db-port", "6432", "-timeout", "1m", "-v"})
+	if err != nil || someStruct.Port != 6432 || someStruct.Timeout != time.Minute || !someStruct.Verbose {
+		t.Errorf("Expected the flags to be parsed into the fields, got %v, %v", someStruct, err)
+	}
+	err = flags.Parse([]string{"-db-port", "0"})
+	expected := `invalid value "0" for flag -db-port: invalid value '0' for environment variable DB_PORT: must be at least 1`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	err = flags.Parse([]string{"-db-port="})
+	expected = `invalid value "" for flag -db-port: invalid value '0' for environment variable DB_PORT: must be at least 1`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = BindToFlagSet(flags, &someStruct)
+	if err == nil || err.Error() != "flag redefined: db-host" {
+		t.Errorf("Expected flag redefined: db-host, got %v", err)
+	}
+
+	var types []string
+	err = BindFlags(&someStruct, func(name string, usage string, value FlagValue) {
+		types = append(types, value.Type())
+	})
+	if err != nil || strings.Join(types, ",") != "string,int,duration,bool,string" {
+		t.Errorf("Expected the flag types, got %v, %v", types, err)
+	}
+}
+
+func TestWithConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(yamlPath, []byte("server:\n  host: from-file\n  port: 8080\n  tags:\n    - a\n    - b\nlimits: {\"rps\": 10}\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	someStruct := struct {
+		Host   string         `env:"HOST" flag:"host" file:"server.host"`
+		Port   int            `env:"PORT" file:"server.port"`
+		Tags   []string       `env:"TAGS" file:"server.tags"`
+		Limits map[string]int `env:"LIMITS;format:json" file:"limits"`
+		Debug  bool           `env:"DEBUG;default:true" file:"server.debug"`
+	}{}
+	report, err := LoadEnvReport(&someStruct, WithSources(MapSource{"PORT": "9090"}), WithConfigFile(yamlPath), WithFlags(nil, []string{}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Host != "from-file" || someStruct.Port != 9090 || !reflect.DeepEqual(someStruct.Tags, []string{"a", "b"}) || someStruct.Limits["rps"] != 10 || !someStruct.Debug {
+		t.Errorf("Expected HOST=from-file, PORT=9090, TAGS=[a,b], LIMITS={rps:10} and DEBUG=true, got %v", someStruct)
+	}
+	if report.Fields[0].Origin != OriginConfigFile || report.Fields[1].Origin != OriginEnv || report.Fields[4].Origin != OriginDefault {
+		t.Errorf("Expected origins configfile, env and default, got %v", report.Fields)
+	}
+
+	jsonPath := filepath.Join(dir, "config.json")
+	err = os.WriteFile(jsonPath, []byte(`{"server": {"host": "from-json", "port": 8080, "tags": []}, "limits": {}}`), 0o600)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = LoadEnvWithOptions(&someStruct, WithSources(MapSource{}), WithConfigFile(jsonPath), WithFlags(nil, []string{"-host", "from-flag"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Host != "from-flag" || someStruct.Port != 8080 {
+		t.Errorf("Expected HOST=from-flag and PORT=8080, got %v", someStruct)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithConfigFile(filepath.Join(dir, "config.toml")))
+	expected := "error reading config file: open " + filepath.Join(dir, "config.toml") + ": no such file or directory"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestLoadEnvLayered(t *testing.T) {
+	someStruct := struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT"`
+		User string `env:"DB_USER;default:app"`
+	}{}
+	env := MapSource{"DB_HOST": "db", "DB_PORT": "5432", "STAGING_DB_HOST": "staging-db", "TENANT_DB_PORT": "6432"}
+	err := LoadEnvLayered([]string{"", "STAGING_", "TENANT_"}, &someStruct, WithSources(env))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Host != "staging-db" || someStruct.Port != 6432 || someStruct.User != "app" {
+		t.Errorf("Expected DB_HOST=staging-db, DB_PORT=6432 and DB_USER=app, got %v", someStruct)
+	}
+
+	err = LoadEnvLayered([]string{"STAGING_"}, &someStruct, WithSources(env))
+	expected := "environment variable not found: DB_PORT"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,46 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// normalizePath enforces the path and mustexist options on a string field, replacing its value by the expanded and
+// cleaned path, see expandPath, and checking that the path exists when the field has the mustexist flag.
+func normalizePath(field reflect.Value, tags map[string]string) error {
+	if field.Kind() != reflect.String {
+		return &EnvParseError{value: fmt.Sprint(field.Interface()), env: tags["name"], err: fmt.Errorf("path only applies to string fields, not %s", field.Type())}
+	}
+	path, err := expandPath(field.String())
+	if err != nil {
+		return &EnvParseError{value: field.String(), env: tags["name"], err: err}
+	}
+	field.SetString(path)
+	if _, mustExist := tags["mustexist"]; mustExist {
+		_, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return &ValidationError{Env: tags["name"], Rule: "mustexist", Value: path, Reason: "must exist"}
+		}
+		if err != nil {
+			return &EnvParseError{value: path, env: tags["name"], err: err}
+		}
+	}
+	return nil
+}
+
+// expandPath replaces a leading ~ of a path by the home directory of the user and cleans the path, converting / to the
+// separator of the platform, so ~/data becomes /home/user/data on Unix and C:\Users\user\data on Windows.
+func expandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~`+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = home + path[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(path)), nil
+}
@@ -0,0 +1,133 @@
+package goloadenv
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PEMCertificate is a chain of X.509 certificates in PEM format. The value can be given inline, with newlines
+// optionally escaped as \n, or base64 encoded, and every certificate is parsed at load time so corrupted material
+// fails fast.
+type PEMCertificate struct {
+	// PEM holds the PEM encoded certificates.
+	PEM []byte
+	// Certificates holds the parsed certificates, in the order they appear in the PEM data.
+	Certificates []*x509.Certificate
+}
+
+// UnmarshalText decodes and parses PEM encoded certificates.
+func (c *PEMCertificate) UnmarshalText(text []byte) error {
+	data, err := decodePEMText(text)
+	if err != nil {
+		return err
+	}
+	var certificates []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block type %s, expected CERTIFICATE", block.Type)
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("invalid certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return errors.New("no PEM encoded certificate found")
+	}
+	*c = PEMCertificate{PEM: data, Certificates: certificates}
+	return nil
+}
+
+// Leaf returns the first certificate of the chain, or nil if there is none.
+func (c PEMCertificate) Leaf() *x509.Certificate {
+	if len(c.Certificates) == 0 {
+		return nil
+	}
+	return c.Certificates[0]
+}
+
+// String summarizes the leaf certificate, so printing a config does not dump the PEM data.
+func (c PEMCertificate) String() string {
+	leaf := c.Leaf()
+	if leaf == nil {
+		return ""
+	}
+	return fmt.Sprintf("certificate %s (expires %s)", leaf.Subject.String(), leaf.NotAfter.Format("2006-01-02"))
+}
+
+// MarshalText returns the summary of String, so machine readable output does not dump the PEM data either.
+func (c PEMCertificate) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// PEMPrivateKey is a private key in PEM format, in PKCS #8, PKCS #1 or SEC 1 form. The value can be given inline,
+// with newlines optionally escaped as \n, or base64 encoded, and the key is parsed at load time so corrupted material
+// fails fast.
+type PEMPrivateKey struct {
+	// PEM holds the PEM encoded key.
+	PEM []byte
+	// Key holds the parsed key, an *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey or *ecdh.PrivateKey.
+	Key crypto.PrivateKey
+}
+
+// UnmarshalText decodes and parses a PEM encoded private key.
+func (k *PEMPrivateKey) UnmarshalText(text []byte) error {
+	data, err := decodePEMText(text)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.New("no PEM encoded private key found")
+	}
+	var key crypto.PrivateKey
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return fmt.Errorf("unexpected PEM block type %s, expected a private key", block.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	*k = PEMPrivateKey{PEM: data, Key: key}
+	return nil
+}
+
+// String hides the key material, so printing a config never leaks the key.
+func (k PEMPrivateKey) String() string {
+	if k.Key == nil {
+		return ""
+	}
+	return fmt.Sprintf("%T", k.Key)
+}
+
+// MarshalText returns the placeholder of String, so machine readable output never leaks the key either.
+func (k PEMPrivateKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// decodePEMText returns the PEM data of a value that is either PEM encoded, possibly with escaped newlines, or base64
+// encoded PEM.
+func decodePEMText(text []byte) ([]byte, error) {
+	text = bytes.TrimSpace(text)
+	if !bytes.HasPrefix(text, []byte("-----BEGIN")) {
+		decoded, err := base64.StdEncoding.DecodeString(string(text))
+		if err != nil {
+			return nil, errors.New("value is neither PEM nor base64 encoded PEM")
+		}
+		text = bytes.TrimSpace(decoded)
+	}
+	return []byte(strings.ReplaceAll(string(text), `\n`, "\n")), nil
+}
@@ -0,0 +1,200 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// loadPrefixMap loads a field tagged with the prefixmap option, collecting every variable whose name starts with the
+// name of the field into a map keyed by the rest of its name, e.g. FEATURE_DARK_MODE=on as DARK_MODE for
+// env:"FEATURE_;prefixmap". The values are parsed as the element type of the map. When no variable has the prefix, the
+// field falls back to its default, in the key=value syntax of maps, and is otherwise missing unless optional.
+// used internally by LoadEnv.
+func (l *loader) loadPrefixMap(field reflect.Value, tags map[string]string) (Origin, error) {
+	prefix := tags["name"]
+	if field.Kind() != reflect.Map || field.Type().Key().Kind() != reflect.String {
+		return OriginUnset, &EnvParseError{env: prefix, err: errors.New("prefixmap only applies to maps with string keys")}
+	}
+	entries := reflect.MakeMap(field.Type())
+	for _, name := range l.envNames() {
+		key, hasPrefix := strings.CutPrefix(name, prefix)
+		if !hasPrefix || key == "" {
+			continue
+		}
+		str, found := l.lookup(name)
+		if !found {
+			continue
+		}
+		elemTags := maps.Clone(tags)
+		elemTags["name"] = name
+		elem := reflect.New(field.Type().Elem()).Elem()
+		err := setValue(elem, str, elemTags)
+		if err == nil {
+			err = validateField(elem, elemTags)
+		}
+		if err != nil {
+			return OriginEnv, err
+		}
+		entries.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), elem)
+	}
+	if entries.Len() > 0 {
+		field.Set(entries)
+		return OriginEnv, nil
+	}
+	str, origin, err := getField(tags, func(string) (string, bool) { return "", false })
+	if err != nil || origin == OriginUnset {
+		return origin, err
+	}
+	return origin, setValue(field, str, tags)
+}
+
+// structPrefixMap reports whether a field tagged with the prefixmap option is a map of structs, or of pointers to
+// structs, whose entries are loaded as nested config structs, and returns the struct type of its values.
+func structPrefixMap(field reflect.Value, tags map[string]string) (reflect.Type, bool) {
+	if _, isPrefixMap := tags["prefixmap"]; !isPrefixMap || field.Kind() != reflect.Map || field.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	elem := field.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if !isNestedStruct(elem) {
+		return nil, false
+	}
+	return elem, true
+}
+
+// loadStructPrefixMap loads a map of structs tagged with the prefixmap option, with an entry for every key found
+// between the name of the field and the name of a variable of the struct, e.g. TENANT_ACME_HOST and TENANT_ACME_PORT
+// load the entry ACME of env:"TENANT_;prefixmap" as a nested struct with the prefix TENANT_ACME_. When a variable name
+// matches several variables of the struct, the longest one determines the key. When no entry is set, the field is left
+// untouched, and is missing unless optional.
+// used internally by LoadEnv.
+func (l *loader) loadStructPrefixMap(field reflect.Value, elemType reflect.Type, tags map[string]string, path string) error {
+	err := checkCycles(elemType)
+	if err != nil {
+		return err
+	}
+	names, err := structEnvNames(elemType, l.tagName)
+	if err != nil {
+		return err
+	}
+	// match the longest variable names first, so the shortest key wins
+	slices.SortFunc(names, func(a, b string) int { return len(b) - len(a) })
+	prefix := tags["name"]
+	keys := map[string]struct{}{}
+	for _, name := range l.envNames() {
+		rest, hasPrefix := strings.CutPrefix(name, prefix)
+		if !hasPrefix {
+			continue
+		}
+		rest = strings.TrimSuffix(rest, fileSuffix)
+		for _, elemName := range names {
+			key, isElem := strings.CutSuffix(rest, "_"+elemName)
+			if isElem && key != "" {
+				// only look up the variables of entries, the others are unknown to WithRejectUnknown
+				if _, found := l.lookup(name); found {
+					keys[key] = struct{}{}
+				}
+				break
+			}
+		}
+	}
+	if len(keys) == 0 {
+		if _, isOptional := tags["optional"]; !isOptional {
+			return &EnvNotFoundError{Env: prefix, Path: path, Type: field.Type()}
+		}
+		return nil
+	}
+	entries := reflect.MakeMapWithSize(field.Type(), len(keys))
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		elemPath := fmt.Sprintf("%s[%s]", path, key)
+		err := l.limits.enterStruct(elemPath)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(elemType)
+		err = l.loadStruct(elem.Elem(), prefix+key+"_", elemPath)
+		if err != nil {
+			return err
+		}
+		l.limits.leaveStruct()
+		if field.Type().Elem().Kind() == reflect.Ptr {
+			entries.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), elem)
+		} else {
+			entries.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), elem.Elem())
+		}
+	}
+	field.Set(entries)
+	return nil
+}
+
+// fieldVariables calls fn with the variables backing a field, the value of each and the tag options to format it
+// with. A field tagged with the prefixmap option has a variable per entry, e.g. FEATURE_DARK_MODE for the entry
+// DARK_MODE of env:"FEATURE_;prefixmap", and the entries of maps of structs are walked field by field, e.g.
+// TENANT_ACME_HOST. Other fields are backed by the variable of their name.
+func fieldVariables(name string, v reflect.Value, tags map[string]string, fn func(name string, v reflect.Value, tags map[string]string) error) error {
+	if _, isPrefixMap := tags["prefixmap"]; !isPrefixMap || v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return fn(name, v, tags)
+	}
+	_, isStructMap := structPrefixMap(v, tags)
+	entryTags := maps.Clone(tags)
+	delete(entryTags, "prefixmap")
+	keys := v.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+	for _, key := range keys {
+		entry := v.MapIndex(key)
+		if !isStructMap {
+			err := fn(name+key.String(), entry, entryTags)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if entry.Kind() == reflect.Ptr {
+			if entry.IsNil() {
+				continue
+			}
+			entry = entry.Elem()
+		}
+		err := iterateStruct(entry, "", name+key.String()+"_", tagName, func(f FieldInfo, field reflect.Value) error {
+			if f.Name == "" || !f.StructField.IsExported() {
+				return nil
+			}
+			return fieldVariables(f.Name, field, f.Tags, fn)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envNames returns the sorted names of the variables of the sources of the loader, and of the process environment
+// unless the sources exclude it, as a lookup function cannot list its variables.
+func (l *loader) envNames() []string {
+	names := map[string]struct{}{}
+	if l.listProcessEnv {
+		for _, variable := range os.Environ() {
+			name, _, _ := strings.Cut(variable, "=")
+			names[name] = struct{}{}
+		}
+	}
+	for _, keys := range l.sourceKeys {
+		for _, name := range keys {
+			names[name] = struct{}{}
+		}
+	}
+	for name := range l.configValues {
+		names[name] = struct{}{}
+	}
+	for name := range l.flagValues {
+		names[name] = struct{}{}
+	}
+	return slices.Sorted(maps.Keys(names))
+}
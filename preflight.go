@@ -0,0 +1,56 @@
+package goloadenv
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Checklist lists the problems found by ValidateEnv, each with the field, the variable and the error.
+type Checklist struct {
+	// Missing holds the required fields whose variable is not set.
+	Missing []FieldReport
+	// Malformed holds the fields whose value cannot be parsed or violates a validation rule.
+	Malformed []FieldReport
+}
+
+// OK reports whether the checklist has no problems.
+func (c *Checklist) OK() bool {
+	return len(c.Missing) == 0 && len(c.Malformed) == 0
+}
+
+// ValidateEnv performs every lookup, parse and validation of loading the config struct, but loads into a copy so the
+// config is not modified, and returns the problems of all fields at once. It is meant as a pre-flight check, e.g. in an
+// init container or a health endpoint. The config may be a struct or a pointer to a struct. An error is returned for
+// problems that are not tied to a field, such as an unreadable .env file or a failing Validate hook.
+//
+// Example:
+//
+//	checklist, err := goloadenv.ValidateEnv(Config{})
+//	for _, field := range checklist.Missing {
+//	  fmt.Println("missing", field.Env)
+//	}
+func ValidateEnv(config interface{}, opts ...Option) (*Checklist, error) {
+	typ := reflect.TypeOf(config)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("config must be a struct or a pointer to a struct")
+	}
+	report, err := LoadEnvReport(reflect.New(typ).Interface(), append(append([]Option{}, opts...), WithAllErrors())...)
+	checklist := &Checklist{}
+	for _, field := range report.Fields {
+		var notFound *EnvNotFoundError
+		switch {
+		case field.Err == nil:
+		case errors.As(field.Err, &notFound):
+			checklist.Missing = append(checklist.Missing, field)
+		default:
+			checklist.Malformed = append(checklist.Malformed, field)
+		}
+	}
+	if err != nil && checklist.OK() {
+		return checklist, err
+	}
+	return checklist, nil
+}
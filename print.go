@@ -1,49 +1,319 @@
 package goloadenv
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
+	"sync"
 )
 
-// TODO maybe an extra tag that ensures a field is not printed, handy for passwords for example
-func FormatString(config interface{}) string {
-	return fmt.Sprintf("{\n%s\n}", formatStruct(reflect.ValueOf(config), 1))
+// PrintField is a config field prepared for rendering. Nested structs have a nil Value and their fields in Fields.
+// Renderers should dereference pointer values, the built-in renderers print nil pointers as <nil>, or <unset> for
+// optional fields, and as null in JSON and YAML.
+type PrintField struct {
+	// Name is the name of the struct field.
+	Name string
+	// Path is the dotted path of the field from the root config struct, e.g. "DB.Host".
+	Path string
+	// Env is the name of the environment variable backing the field, empty if the field is not tagged.
+	Env string
+	// Value is the value to print for the field.
+	Value interface{}
+	// Secret reports whether the field is tagged as secret, its Value is masked when it is set. The set elements of
+	// secret slices, arrays and maps are masked one by one, except for byte slices.
+	Secret bool
+	// Unset reports whether the field is an optional pointer field that was left nil.
+	Unset bool
+	// Fields holds the fields of a nested struct.
+	Fields []PrintField
+	// tags holds the tag options of the field, for the renderers writing values in the formats LoadEnv parses.
+	tags map[string]string
 }
 
-func formatStruct(v reflect.Value, indent int) string {
+// secretMask replaces the value of secret fields in printed output.
+const secretMask = "****"
+
+// unsetValue is printed for unset optional pointer fields by the text based renderers.
+const unsetValue = "<unset>"
+
+// displayValue formats the value of the field for the text based renderers.
+func (f PrintField) displayValue() string {
+	if f.Unset {
+		return unsetValue
+	}
+	return formatValue(f.Value)
+}
+
+// blockValue formats the value of the field for the text renderer, which prints large collections and structs in
+// collections over multiple lines starting at the given indentation.
+func (f PrintField) blockValue(indent textIndent) string {
+	if f.Unset {
+		return unsetValue
+	}
+	return formatBlockValue(f.Value, indent)
+}
+
+// IsStruct reports whether the field is a nested struct.
+func (f PrintField) IsStruct() bool {
+	return f.Fields != nil
+}
+
+// Renderer renders the fields of a config struct in a specific output format.
+type Renderer interface {
+	Render(w io.Writer, fields []PrintField) error
+}
+
+// RendererFunc is an adapter to allow the use of ordinary functions as a Renderer.
+type RendererFunc func(w io.Writer, fields []PrintField) error
+
+// Render calls f(w, fields).
+func (f RendererFunc) Render(w io.Writer, fields []PrintField) error {
+	return f(w, fields)
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		"text":   RendererFunc(renderText),
+		"json":   RendererFunc(renderJSON),
+		"yaml":   RendererFunc(renderYAML),
+		"table":  RendererFunc(renderTable),
+		"logfmt": RendererFunc(renderLogfmt),
+		"dotenv": RendererFunc(renderDotEnv),
+	}
+)
+
+// RegisterRenderer registers a renderer under the given name for use with Format, replacing any renderer previously
+// registered under that name.
+func RegisterRenderer(name string, renderer Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[name] = renderer
+}
+
+// Format renders a config struct with the renderer registered under the given name. The built-in renderers are "text",
+// "json", "yaml", "table", "logfmt" and "dotenv". The options limit the size of the output, values and collections are
+// truncated before they are passed to the renderer.
+func Format(config interface{}, format string, opts ...PrintOption) (string, error) {
+	renderersMu.RLock()
+	renderer, found := renderers[format]
+	renderersMu.RUnlock()
+	if !found {
+		return "", fmt.Errorf("unknown renderer: %s", format)
+	}
+	options := newPrintOptions(opts)
+	if format == "text" && options.indentWidth > 0 {
+		renderer = textRenderer(options.indentWidth)
+	}
+	fields, err := printFields(config, options)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	err = renderer.Render(&builder, options.truncateFields(fields))
+	if err != nil {
+		return "", err
+	}
+	return options.truncateOutput(builder.String()), nil
+}
+
+// FormatJSON renders a config struct as an indented JSON object like Format with the "json" renderer, with secrets
+// masked and the declaration order of the fields kept.
+func FormatJSON(config interface{}, opts ...PrintOption) (string, error) {
+	return Format(config, "json", opts...)
+}
+
+// FormatYAML renders a config struct as a YAML mapping like Format with the "yaml" renderer, with secrets masked.
+func FormatYAML(config interface{}, opts ...PrintOption) (string, error) {
+	return Format(config, "yaml", opts...)
+}
+
+// FormatDotEnv renders the fields of a config struct backed by an environment variable as a .env file like Format with
+// the "dotenv" renderer. Values are written in the formats LoadEnv parses and secrets are masked, so the output can be
+// diffed or loaded by tooling.
+func FormatDotEnv(config interface{}, opts ...PrintOption) (string, error) {
+	return Format(config, "dotenv", opts...)
+}
+
+// FormatString renders a config struct in a human readable format. Fields with the secret tag flag, e.g.
+// env:"DB_PASSWORD;secret", are printed masked. The output is stable across Go versions and environments, pointers are
+// dereferenced and maps are sorted by key, so it can be used in golden file tests. The options limit the size of the
+// output like they do for Format.
+func FormatString(config interface{}, opts ...PrintOption) string {
+	options := newPrintOptions(opts)
+	fields, err := printFields(config, options)
+	if err != nil {
+		return options.truncateOutput(fmt.Sprintf("{\n%v\n}", config))
+	}
+	var builder strings.Builder
+	_ = textRenderer(options.indentWidth).Render(&builder, options.truncateFields(fields))
+	return options.truncateOutput(builder.String())
+}
+
+// printFields collects the exported fields of a config struct in declaration order and masks the values of secret
+// fields, it is the shared first step of every renderer. Nested structs are collected up to the depth limit of the
+// options, and a struct pointing back to a struct it is nested in is printed as {…}, so cyclic pointers end.
+func printFields(config interface{}, options printOptions) ([]PrintField, error) {
+	v := reflect.ValueOf(config)
+	visiting := map[uintptr]struct{}{}
 	if v.Kind() == reflect.Ptr {
+		visiting[v.Pointer()] = struct{}{}
 		v = v.Elem()
 	}
-
 	if v.Kind() != reflect.Struct {
-		return fmt.Sprint(v.Interface())
+		return nil, errors.New("config must be a struct or a pointer to a struct")
 	}
+	err := checkCycles(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	return collectPrintFields(v, "", "", options.maxDepth, options.omitEmpty, visiting), nil
+}
 
-	var lines []string
-	maxLen := getMaxFieldNameLength(v)
-
+// collectPrintFields collects the fields of a struct for printFields. The depth is the number of levels of nested
+// structs left to collect, unlimited when zero or less, omitEmpty leaves out the optional fields holding their zero
+// value, and visiting holds the addresses of the pointed-to structs the struct is nested in.
+func collectPrintFields(v reflect.Value, path string, prefix string, depth int, omitEmpty bool, visiting map[uintptr]struct{}) []PrintField {
+	fields := []PrintField{}
 	for i := 0; i < v.NumField(); i++ {
 		fieldType := v.Type().Field(i)
-		fieldValue := v.Field(i)
-		indentation := strings.Repeat("    ", indent)
+		if !fieldType.IsExported() || isSkipped(fieldType, tagName) {
+			continue
+		}
+		tags, _ := parseTags(fieldType, tagName)
+		if isOmitted(v.Field(i), tags, omitEmpty) {
+			continue
+		}
+		field := PrintField{Name: fieldType.Name, Path: fieldType.Name}
+		if path != "" {
+			field.Path = path + "." + fieldType.Name
+		}
+		if nested, _, ok := nestedStruct(v, i, path, false); ok && tags["format"] == "" {
+			field.Fields, field.Value = collectNestedFields(nested, field.Path, prefix+fieldType.Tag.Get(prefixTagName), depth, omitEmpty, visiting, reflect.Value{})
+		} else if nested, ok := pointedStruct(v.Field(i)); ok && tags["name"] == "" {
+			field.Fields, field.Value = collectNestedFields(nested, field.Path, prefix+fieldType.Tag.Get(prefixTagName), depth, omitEmpty, visiting, v.Field(i))
+		} else {
+			if tags["name"] != "" {
+				field.Env = prefix + tags["name"]
+			}
+			field.Secret = isSecret(tags, field.Env)
+			field.tags = tags
+			field.Value = v.Field(i).Interface()
+			if source, isParsed := sourceValue(v.Field(i)); isParsed {
+				field.Value = source
+			}
+			if field.Secret {
+				field.Value = maskSecret(v.Field(i))
+			}
+			if _, isOptional := tags["optional"]; isOptional && v.Field(i).Kind() == reflect.Ptr && v.Field(i).IsNil() {
+				field.Unset = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// elementFields collects the fields of a struct held by a collection, which renderers print field by field.
+func elementFields(nested reflect.Value) []PrintField {
+	return collectPrintFields(nested, "", "", 0, false, map[uintptr]struct{}{})
+}
+
+// isOmitted reports whether a field holding its zero value is left out of printed output, as it has the omitempty flag
+// or is optional while omitEmpty is set. Nested structs are always printed.
+func isOmitted(field reflect.Value, tags map[string]string, omitEmpty bool) bool {
+	if tags["name"] == "" || !field.IsZero() {
+		return false
+	}
+	_, hasOmitEmpty := tags["omitempty"]
+	_, isOptional := tags["optional"]
+	return hasOmitEmpty || omitEmpty && isOptional
+}
+
+// collectNestedFields collects the fields of a nested struct for collectPrintFields, or returns the {…} value printed
+// instead when the depth limit is reached or the pointer it is reached through points to a struct it is nested in.
+func collectNestedFields(nested reflect.Value, path string, prefix string, depth int, omitEmpty bool, visiting map[uintptr]struct{}, pointer reflect.Value) ([]PrintField, interface{}) {
+	if depth == 1 {
+		return nil, "{" + ellipsis + "}"
+	}
+	if pointer.IsValid() {
+		if _, isCycle := visiting[pointer.Pointer()]; isCycle {
+			return nil, "{" + ellipsis + "}"
+		}
+		visiting[pointer.Pointer()] = struct{}{}
+		defer delete(visiting, pointer.Pointer())
+	}
+	return collectPrintFields(nested, path, prefix, depth-1, omitEmpty, visiting), nil
+}
 
-		if fieldValue.Kind() == reflect.Struct {
-			lines = append(lines, fmt.Sprintf("%s%-*s {\n%s\n%s}", indentation, maxLen, fmt.Sprintf("%s:", fieldType.Name), formatStruct(fieldValue, indent+1), indentation))
+// defaultIndentWidth is the number of spaces per indentation level of the text renderer.
+const defaultIndentWidth = 4
+
+// textIndent is an indentation level of the text renderer and the width of a level.
+type textIndent struct {
+	level int
+	width int
+}
+
+// String returns the spaces of the indentation.
+func (i textIndent) String() string {
+	return strings.Repeat(" ", i.level*i.width)
+}
+
+// next returns the indentation one level deeper.
+func (i textIndent) next() textIndent {
+	return textIndent{level: i.level + 1, width: i.width}
+}
+
+// pointedStruct returns the struct a non-nil pointer field points to, for untagged pointers to structs that are
+// printed field by field like nested structs. Nil pointers are printed as <nil>.
+func pointedStruct(field reflect.Value) (reflect.Value, bool) {
+	if field.Kind() != reflect.Ptr || field.IsNil() {
+		return reflect.Value{}, false
+	}
+	return elementStruct(field)
+}
+
+// renderText renders the fields in the human readable format of FormatString.
+func renderText(w io.Writer, fields []PrintField) error {
+	return textRenderer(defaultIndentWidth).Render(w, fields)
+}
+
+// textRenderer returns the text renderer indenting by the given number of spaces per level, or by defaultIndentWidth
+// when it is zero or less.
+func textRenderer(width int) Renderer {
+	if width <= 0 {
+		width = defaultIndentWidth
+	}
+	return RendererFunc(func(w io.Writer, fields []PrintField) error {
+		_, err := fmt.Fprintf(w, "{\n%s\n}", formatStruct(fields, textIndent{level: 1, width: width}))
+		return err
+	})
+}
+
+func formatStruct(fields []PrintField, indent textIndent) string {
+	var lines []string
+	maxLen := getMaxFieldNameLength(fields)
+	indentation := indent.String()
+
+	for _, field := range fields {
+		if field.IsStruct() {
+			lines = append(lines, fmt.Sprintf("%s%-*s {\n%s\n%s}", indentation, maxLen, fmt.Sprintf("%s:", field.Name), formatStruct(field.Fields, indent.next()), indentation))
 		} else {
-			lines = append(lines, fmt.Sprintf("%s%-*s %v", indentation, maxLen, fmt.Sprintf("%s:", fieldType.Name), fieldValue.Interface()))
+			lines = append(lines, fmt.Sprintf("%s%-*s %s", indentation, maxLen, fmt.Sprintf("%s:", field.Name), field.blockValue(indent)))
 		}
 	}
 
 	return strings.Join(lines, "\n")
 }
 
-func getMaxFieldNameLength(v reflect.Value) int {
+func getMaxFieldNameLength(fields []PrintField) int {
 	maxLen := 0
-	for i := 0; i < v.NumField(); i++ {
-		fieldType := v.Type().Field(i)
-		if len(fieldType.Name)+1 > maxLen {
-			maxLen = len(fieldType.Name) + 1
+	for _, field := range fields {
+		if len(field.Name)+1 > maxLen {
+			maxLen = len(field.Name) + 1
 		}
 	}
 	return maxLen
@@ -0,0 +1,217 @@
+package goloadenv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around the changed lines of a diff.
+	diffContext = 3
+
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+	ansiReset = "\x1b[0m"
+)
+
+// WithColor enables or disables ANSI colors in the output of WriteDiff, overriding the detection of a terminal.
+func WithColor(enabled bool) PrintOption {
+	return func(o *printOptions) {
+		o.color = &enabled
+	}
+}
+
+// FormatDiff renders a unified diff of two versions of a config struct in the human readable format of FormatString,
+// with secrets masked, e.g. to log what changed on a reload. It returns an empty string when the printed configs are
+// equal. The output is not colored unless the WithColor option enables it.
+func FormatDiff(old interface{}, new interface{}, opts ...PrintOption) (string, error) {
+	var builder strings.Builder
+	err := writeDiff(&builder, old, new, newPrintOptions(opts), false)
+	if err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// WriteDiff writes a unified diff of two versions of a config struct like FormatDiff. Removed lines are colored red and
+// added lines green when w is a terminal and the NO_COLOR environment variable is not set, or when the WithColor
+// option enables it.
+//
+// Example:
+//
+//	old := w.Config()
+//	changes, err := w.Reload()
+//	if err == nil && len(changes) > 0 {
+//	  _ = goloadenv.WriteDiff(os.Stderr, old, w.Config())
+//	}
+func WriteDiff(w io.Writer, old interface{}, new interface{}, opts ...PrintOption) error {
+	var builder strings.Builder
+	err := writeDiff(&builder, old, new, newPrintOptions(opts), isTerminal(w))
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, builder.String())
+	return err
+}
+
+func writeDiff(builder *strings.Builder, old interface{}, new interface{}, options printOptions, color bool) error {
+	if options.color != nil {
+		color = *options.color
+	}
+	oldLines, err := diffLines(old, options)
+	if err != nil {
+		return err
+	}
+	newLines, err := diffLines(new, options)
+	if err != nil {
+		return err
+	}
+	edits := diffEdits(oldLines, newLines)
+	hunks := diffHunks(edits)
+	if len(hunks) == 0 {
+		return nil
+	}
+	writeDiffLine(builder, "--- old", "", color)
+	writeDiffLine(builder, "+++ new", "", color)
+	for _, hunk := range hunks {
+		writeDiffLine(builder, hunk.header(edits), ansiCyan, color)
+		for _, edit := range edits[hunk.start:hunk.end] {
+			switch edit.op {
+			case '-':
+				writeDiffLine(builder, "-"+edit.line, ansiRed, color)
+			case '+':
+				writeDiffLine(builder, "+"+edit.line, ansiGreen, color)
+			default:
+				writeDiffLine(builder, " "+edit.line, "", color)
+			}
+		}
+	}
+	return nil
+}
+
+func writeDiffLine(builder *strings.Builder, line string, ansi string, color bool) {
+	if color && ansi != "" {
+		line = ansi + line + ansiReset
+	}
+	builder.WriteString(line + "\n")
+}
+
+// diffLines renders a config in the format of FormatString and splits it into lines.
+func diffLines(config interface{}, options printOptions) ([]string, error) {
+	fields, err := printFields(config, options)
+	if err != nil {
+		return nil, err
+	}
+	var builder strings.Builder
+	err = textRenderer(options.indentWidth).Render(&builder, options.truncateFields(fields))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(builder.String(), "\n"), nil
+}
+
+// diffEdit is a line of a diff, its op is ' ' for an unchanged line, '-' for a removed line and '+' for an added line.
+type diffEdit struct {
+	op   byte
+	line string
+	// oldLine and newLine are the zero based line numbers in the old and new text the edit is at.
+	oldLine int
+	newLine int
+}
+
+// diffEdits computes the shortest edit script turning the old lines into the new lines from their longest common
+// subsequence. Configs are small, so the quadratic table is not a concern.
+func diffEdits(oldLines []string, newLines []string) []diffEdit {
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var edits []diffEdit
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			edits = append(edits, diffEdit{op: ' ', line: oldLines[i], oldLine: i, newLine: j})
+			i++
+			j++
+		case j == len(newLines) || i < len(oldLines) && lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, diffEdit{op: '-', line: oldLines[i], oldLine: i, newLine: j})
+			i++
+		default:
+			edits = append(edits, diffEdit{op: '+', line: newLines[j], oldLine: i, newLine: j})
+			j++
+		}
+	}
+	return edits
+}
+
+// diffHunk is a range of edits shown together, the changed edits surrounded by up to diffContext unchanged ones.
+type diffHunk struct {
+	start int
+	end   int
+}
+
+// diffHunks groups the changed edits into hunks, merging hunks whose context overlaps.
+func diffHunks(edits []diffEdit) []diffHunk {
+	var hunks []diffHunk
+	for i, edit := range edits {
+		if edit.op == ' ' {
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := min(i+1+diffContext, len(edits))
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+			continue
+		}
+		hunks = append(hunks, diffHunk{start: start, end: end})
+	}
+	return hunks
+}
+
+// header formats the @@ -l,s +l,s @@ line of the hunk with one based line numbers.
+func (h diffHunk) header(edits []diffEdit) string {
+	oldCount, newCount := 0, 0
+	for _, edit := range edits[h.start:h.end] {
+		if edit.op != '+' {
+			oldCount++
+		}
+		if edit.op != '-' {
+			newCount++
+		}
+	}
+	oldStart, newStart := edits[h.start].oldLine+1, edits[h.start].newLine+1
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount)
+}
+
+// isTerminal reports whether colored output should be written to w: w is a terminal and the NO_COLOR environment
+// variable is not set.
+func isTerminal(w io.Writer) bool {
+	if _, noColor := os.LookupEnv("NO_COLOR"); noColor {
+		return false
+	}
+	file, isFile := w.(*os.File)
+	if !isFile {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,158 @@
+package goloadenv
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// renderDotEnv renders the fields backed by an environment variable as KEY=value lines of a .env file, with the values
+// written in the formats LoadEnv parses, so a loaded config can be written back out. Unset optional fields are written
+// commented out.
+func renderDotEnv(w io.Writer, fields []PrintField) error {
+	var lines []string
+	appendDotEnvLines(&lines, fields)
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func appendDotEnvLines(lines *[]string, fields []PrintField) {
+	for _, field := range fields {
+		if field.IsStruct() {
+			appendDotEnvLines(lines, field.Fields)
+			continue
+		}
+		if field.Env == "" {
+			continue
+		}
+		if field.Unset {
+			*lines = append(*lines, "# "+field.Env+"=")
+			continue
+		}
+		_ = fieldVariables(field.Env, reflect.ValueOf(field.Value), field.tags, func(name string, v reflect.Value, tags map[string]string) error {
+			// the fields of prefix map entries are not masked by printFields
+			if name != field.Env && isSecret(tags, name) {
+				v = reflect.ValueOf(maskSecret(v))
+			}
+			*lines = append(*lines, name+"="+dotEnvQuote(envValue(v, tags)))
+			return nil
+		})
+	}
+}
+
+// envValue formats a value the way LoadEnv parses it with the given tag options: encoding.TextMarshaler and
+// fmt.Stringer values through their methods, also when implemented on the pointer like url.URL, time zones, regular
+// expressions and templates as the text they were parsed from, integers in the base of the base option, slices and
+// arrays as [a,b] lists or joined by the sep option, maps as sorted key=value pairs and structs as JSON. Values of
+// fields with the json or yaml format are written as JSON, byte slices in the encoding of the encoding option and
+// durations and times in the unit or layout of the format option. Members of enums are written by their name.
+func envValue(v reflect.Value, tags map[string]string) string {
+	if source, isParsed := sourceValue(v); isParsed {
+		return source
+	}
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() && !v.Type().Implements(textMarshalerType) {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return ""
+	}
+	if enum, hasEnum := tags["enum"]; hasEnum && v.Kind() != reflect.Slice && v.Kind() != reflect.Array && v.Kind() != reflect.Map {
+		memberTags := maps.Clone(tags)
+		delete(memberTags, "enum")
+		return enumName(envValue(v, memberTags), enum)
+	}
+	if isDocumentFormat(tags["format"]) && v.CanInterface() {
+		if data, err := json.Marshal(v.Interface()); err == nil {
+			return string(data)
+		}
+	}
+	if encoding, hasEncoding := tags["encoding"]; hasEncoding && v.Type() == byteSliceType {
+		return encodeEncoding(v.Bytes(), encoding)
+	}
+	if format, hasFormat := tags["format"]; hasFormat && v.Type() == durationType {
+		return formatDuration(time.Duration(v.Int()), format)
+	}
+	if layout := cmp.Or(tags["format"], tags["layout"]); layout != "" && v.Type() == timeType {
+		return formatTime(v.Interface().(time.Time), layout)
+	}
+	// types such as url.URL, net.IPNet and mail.Address implement their methods on the pointer
+	if v.Kind() == reflect.Struct && v.CanInterface() && !v.Type().Implements(textMarshalerType) && !v.Type().Implements(stringerType) {
+		if ptr := reflect.PointerTo(v.Type()); ptr.Implements(textMarshalerType) || ptr.Implements(stringerType) {
+			copied := reflect.New(v.Type())
+			copied.Elem().Set(v)
+			v = copied
+		}
+	}
+	if v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return formatInt(v, tags)
+	case reflect.Slice, reflect.Array:
+		sep, hasSep := tags["sep"]
+		if !hasSep {
+			sep = ","
+		}
+		elements := make([]string, v.Len())
+		for i := range elements {
+			elements[i] = listElementQuote(envValue(v.Index(i), tags), sep)
+		}
+		if hasSep {
+			return strings.Join(elements, sep)
+		}
+		return "[" + strings.Join(elements, ",") + "]"
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries = append(entries, envValue(iter.Key(), tags)+"="+envValue(iter.Value(), tags))
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, ",")
+	case reflect.Struct:
+		if data, err := json.Marshal(v.Interface()); err == nil {
+			return string(data)
+		}
+	}
+	return formatReflectValue(v)
+}
+
+// listElementQuote double quotes a list element when it contains the separator or characters that SplitList would
+// otherwise interpret.
+func listElementQuote(str string, sep string) string {
+	if !strings.Contains(str, sep) && !strings.ContainsAny(str, ",[]{}\"'\\") && strings.TrimSpace(str) == str {
+		return str
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str) + `"`
+}
+
+// dotEnvQuote quotes a .env value when it contains whitespace, comments, quotes or escapes, see LoadDotEnv. Values are
+// single quoted to be taken literally when possible, and double quoted with escapes otherwise.
+func dotEnvQuote(str string) string {
+	if !strings.ContainsAny(str, " \t\r\n#\"'\\") {
+		return str
+	}
+	if !strings.ContainsAny(str, "'\r\n") {
+		return "'" + str + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(str) + `"`
+}
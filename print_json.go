@@ -0,0 +1,111 @@
+package goloadenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// renderJSON renders the fields as an indented JSON object, keeping the declaration order of the struct fields.
+func renderJSON(w io.Writer, fields []PrintField) error {
+	var builder strings.Builder
+	err := writeJSONObject(&builder, fields, 0)
+	if err != nil {
+		return err
+	}
+	builder.WriteString("\n")
+	_, err = io.WriteString(w, builder.String())
+	return err
+}
+
+func writeJSONObject(builder *strings.Builder, fields []PrintField, indent int) error {
+	if len(fields) == 0 {
+		builder.WriteString("{}")
+		return nil
+	}
+	indentation := strings.Repeat("  ", indent+1)
+	builder.WriteString("{\n")
+	for i, field := range fields {
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(builder, "%s%s: ", indentation, name)
+		if field.IsStruct() {
+			err = writeJSONObject(builder, field.Fields, indent+1)
+		} else {
+			err = writeJSONValue(builder, field.Value, indent+1)
+		}
+		if err != nil {
+			return fmt.Errorf("error rendering field '%s' as JSON: %w", field.Path, err)
+		}
+		if i < len(fields)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString(strings.Repeat("  ", indent) + "}")
+	return nil
+}
+
+// writeJSONValue writes a value starting on a line at the given indentation level. Structs in slices, arrays and maps
+// are written field by field like nested structs, so their secret fields are masked.
+func writeJSONValue(builder *strings.Builder, value interface{}, indent int) error {
+	collection := derefPrinted(reflect.ValueOf(value))
+	if !collection.IsValid() || !isPrintedPerElement(collection.Type()) || !hasStructElements(collection) {
+		data, err := json.MarshalIndent(value, strings.Repeat("  ", indent), "  ")
+		if err != nil {
+			return err
+		}
+		builder.Write(data)
+		return nil
+	}
+	elementIndentation := strings.Repeat("  ", indent+1)
+	var names []string
+	elements := map[string]reflect.Value{}
+	if collection.Kind() == reflect.Map {
+		iter := collection.MapRange()
+		for iter.Next() {
+			name, err := json.Marshal(formatReflectValue(iter.Key()))
+			if err != nil {
+				return err
+			}
+			names = append(names, string(name))
+			elements[string(name)] = iter.Value()
+		}
+		sort.Strings(names)
+		builder.WriteString("{\n")
+	} else {
+		builder.WriteString("[\n")
+	}
+	for i := 0; i < collection.Len(); i++ {
+		element := collection.Index(i)
+		builder.WriteString(elementIndentation)
+		if collection.Kind() == reflect.Map {
+			element = elements[names[i]]
+			builder.WriteString(names[i] + ": ")
+		}
+		var err error
+		if nested, isStruct := elementStruct(element); isStruct {
+			err = writeJSONObject(builder, elementFields(nested), indent+1)
+		} else {
+			err = writeJSONValue(builder, element.Interface(), indent+1)
+		}
+		if err != nil {
+			return err
+		}
+		if i < collection.Len()-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	if collection.Kind() == reflect.Map {
+		builder.WriteString(strings.Repeat("  ", indent) + "}")
+	} else {
+		builder.WriteString(strings.Repeat("  ", indent) + "]")
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package goloadenv
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// renderLogfmt renders the fields as a single logfmt line of key=value pairs, nested fields are keyed by their dotted
+// path.
+func renderLogfmt(w io.Writer, fields []PrintField) error {
+	var pairs []string
+	appendLogfmtPairs(&pairs, fields)
+	_, err := io.WriteString(w, strings.Join(pairs, " ")+"\n")
+	return err
+}
+
+func appendLogfmtPairs(pairs *[]string, fields []PrintField) {
+	for _, field := range fields {
+		if field.IsStruct() {
+			appendLogfmtPairs(pairs, field.Fields)
+			continue
+		}
+		*pairs = append(*pairs, field.Path+"="+logfmtValue(field.displayValue()))
+	}
+}
+
+// logfmtValue quotes a value when it is empty or contains characters that would break the key=value pairs.
+func logfmtValue(str string) string {
+	if str == "" || strings.ContainsAny(str, " =\"\t\n\\") {
+		return strconv.Quote(str)
+	}
+	return str
+}
@@ -0,0 +1,91 @@
+package goloadenv
+
+import (
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+// SlogAttrs returns the fields of a config struct as structured slog attributes keyed by field name, with secrets
+// masked like the other printers and nested structs as groups. Booleans, numbers, strings, durations and times keep
+// their type, other values are formatted as in FormatString. The options limit the size of the values like they do for
+// Format. An invalid config results in a single error attribute.
+//
+// Example:
+//
+//	slog.LogAttrs(ctx, slog.LevelInfo, "config loaded", goloadenv.SlogAttrs(&cfg)...)
+func SlogAttrs(config interface{}, opts ...PrintOption) []slog.Attr {
+	options := newPrintOptions(opts)
+	fields, err := printFields(config, options)
+	if err != nil {
+		return []slog.Attr{slog.String("error", err.Error())}
+	}
+	return slogAttrs(options.truncateFields(fields))
+}
+
+// LogValue returns a slog.LogValuer logging a config struct as a group of the attributes of SlogAttrs. The config is
+// only formatted when the record is handled, so it can be passed to disabled log levels without cost.
+//
+// Example:
+//
+//	slog.Info("config loaded", "config", goloadenv.LogValue(&cfg))
+func LogValue(config interface{}, opts ...PrintOption) slog.LogValuer {
+	return configLogValuer{config: config, opts: opts}
+}
+
+// configLogValuer defers SlogAttrs until a log record holding the config is handled.
+type configLogValuer struct {
+	config interface{}
+	opts   []PrintOption
+}
+
+// LogValue implements slog.LogValuer.
+func (v configLogValuer) LogValue() slog.Value {
+	return slog.GroupValue(SlogAttrs(v.config, v.opts...)...)
+}
+
+func slogAttrs(fields []PrintField) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		if field.IsStruct() {
+			attrs = append(attrs, slog.Attr{Key: field.Name, Value: slog.GroupValue(slogAttrs(field.Fields)...)})
+			continue
+		}
+		attrs = append(attrs, slog.Attr{Key: field.Name, Value: slogValue(field)})
+	}
+	return attrs
+}
+
+// slogValue returns the slog value of a field, keeping the type of scalar values. Secret values are already masked by
+// collectPrintFields, a set secret scalar is the string mask.
+func slogValue(field PrintField) slog.Value {
+	if field.Unset {
+		return slog.StringValue(unsetValue)
+	}
+	v := derefPrinted(reflect.ValueOf(field.Value))
+	if !v.IsValid() || !v.CanInterface() {
+		return slog.StringValue(field.displayValue())
+	}
+	switch v.Type() {
+	case durationType:
+		return slog.DurationValue(v.Interface().(time.Duration))
+	case timeType:
+		return slog.TimeValue(v.Interface().(time.Time))
+	}
+	if v.Type().Implements(stringerType) {
+		return slog.StringValue(field.displayValue())
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return slog.BoolValue(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return slog.Int64Value(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return slog.Uint64Value(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return slog.Float64Value(v.Float())
+	case reflect.String:
+		return slog.StringValue(v.String())
+	}
+	return slog.StringValue(field.displayValue())
+}
@@ -0,0 +1,30 @@
+package goloadenv
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// renderTable renders the fields as an aligned table with one row per field, nested structs are flattened into their
+// dotted paths.
+func renderTable(w io.Writer, fields []PrintField) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tENV\tVALUE")
+	writeTableRows(tw, fields)
+	return tw.Flush()
+}
+
+func writeTableRows(w io.Writer, fields []PrintField) {
+	for _, field := range fields {
+		if field.IsStruct() {
+			writeTableRows(w, field.Fields)
+			continue
+		}
+		env := field.Env
+		if env == "" {
+			env = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", field.Path, env, field.displayValue())
+	}
+}
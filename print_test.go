@@ -0,0 +1,425 @@
+package goloadenv
+
+import (
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+type PrintConfig struct {
+	Host string
+	Port int
+	DB   EmbbededStruct
+}
+
+func TestFormatString(t *testing.T) {
+	cfg := PrintConfig{Host: "localhost", Port: 8080, DB: EmbbededStruct{Host: "db"}}
+	expected := "{\n    Host: localhost\n    Port: 8080\n    DB:   {\n        Host: db\n    }\n}"
+	got := FormatString(&cfg)
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestFormatStringStable(t *testing.T) {
+	port := 8080
+	cfg := struct {
+		Ratio   float64
+		Small   float32
+		Timeout time.Duration
+		Weights map[string]int
+		Port    *int
+		Missing *int
+	}{Ratio: 0.1, Small: 1e-7, Timeout: 90 * time.Second, Weights: map[string]int{"b": 2, "a": 1, "c": 3}, Port: &port}
+
+	expected := "{\n    Ratio:   0.1\n    Small:   1e-07\n    Timeout: 1m30s\n    Weights: map[a:1 b:2 c:3]\n    Port:    8080\n    Missing: <nil>\n}"
+	got := FormatString(cfg)
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestFormatRenderers(t *testing.T) {
+	cfg := PrintConfig{Host: "localhost", Port: 8080, DB: EmbbededStruct{Host: "db"}}
+	tests := map[string]string{
+		"json":   "{\n  \"Host\": \"localhost\",\n  \"Port\": 8080,\n  \"DB\": {\n    \"Host\": \"db\"\n  }\n}\n",
+		"yaml":   "Host: localhost\nPort: 8080\nDB:\n  Host: db\n",
+		"logfmt": "Host=localhost Port=8080 DB.Host=db\n",
+		"table":  "FIELD    ENV      VALUE\nHost     -        localhost\nPort     -        8080\nDB.Host  DB_HOST  db\n",
+	}
+	for format, expected := range tests {
+		got, err := Format(cfg, format)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if got != expected {
+			t.Errorf("Expected %s output %q, got %q", format, expected, got)
+		}
+	}
+}
+
+func TestFormatPointers(t *testing.T) {
+	timeout := 5 * time.Second
+	cfg := struct {
+		Timeout  *time.Duration `env:"TIMEOUT"`
+		Retries  *int           `env:"RETRIES;optional"`
+		Fallback *string        `env:"FALLBACK"`
+	}{Timeout: &timeout}
+
+	tests := map[string]string{
+		"text":   "{\n    Timeout:  5s\n    Retries:  <unset>\n    Fallback: <nil>\n}",
+		"json":   "{\n  \"Timeout\": 5000000000,\n  \"Retries\": null,\n  \"Fallback\": null\n}\n",
+		"yaml":   "Timeout: 5s\nRetries: null\nFallback: null\n",
+		"logfmt": "Timeout=5s Retries=<unset> Fallback=<nil>\n",
+	}
+	for format, expected := range tests {
+		got, err := Format(cfg, format)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if got != expected {
+			t.Errorf("Expected %s output %q, got %q", format, expected, got)
+		}
+	}
+}
+
+func TestFormatLayout(t *testing.T) {
+	type level struct {
+		Host string `env:"HOST"`
+		Deep struct {
+			Port int `env:"PORT"`
+		}
+	}
+	cfg := struct {
+		DB    *level
+		Cache *level
+		Start time.Time `env:"START"`
+		Tags  []string  `env:"TAGS"`
+	}{DB: &level{Host: "db"}, Start: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Tags: []string{"a", "b", "c", "d", "e"}}
+
+	expected := "{\n    DB:    {\n        Host: db\n        Deep: {\n            Port: 0\n        }\n    }\n    Cache: <nil>\n" +
+		"    Start: 2024-01-02T03:04:05Z\n    Tags:  [\n        a\n        b\n        c\n        d\n        e\n    ]\n}"
+	got := FormatString(cfg)
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	expected = "{\n  DB:    {\n    Host: db\n    Deep: {…}\n  }\n  Cache: <nil>\n" +
+		"  Start: 2024-01-02T03:04:05Z\n  Tags:  [\n    a\n    b\n    c\n    d\n    e\n  ]\n}"
+	got, err := Format(cfg, "text", WithIndent(2), WithDepthLimit(2))
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+
+	type node struct {
+		Name string `env:"NAME"`
+		Next *node
+	}
+	cyclic := &node{Name: "a"}
+	cyclic.Next = &node{Name: "b", Next: cyclic}
+	expected = "{\n    Name: a\n    Next: {\n        Name: b\n        Next: {…}\n    }\n}"
+	got = FormatString(cyclic)
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	expected = "{\n    Name: a\n    Next: {…}\n}"
+	got, err = Format(cyclic, "text", WithDepthLimit(1))
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+}
+
+func TestFormatUnknownRenderer(t *testing.T) {
+	_, err := Format(PrintConfig{}, "toml")
+	expected := "unknown renderer: toml"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestFormatSecret(t *testing.T) {
+	cfg := struct {
+		User     string `env:"DB_USER"`
+		Password string `env:"DB_PASSWORD;secret"`
+		Token    string `env:"TOKEN;secret;optional"`
+	}{User: "admin", Password: "hunter2"}
+
+	expected := "{\n    User:     admin\n    Password: ****\n    Token:    \n}"
+	got := FormatString(cfg)
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestSlogAttrs(t *testing.T) {
+	cfg := struct {
+		User     string        `env:"DB_USER"`
+		Password string        `env:"DB_PASSWORD;secret"`
+		Port     int           `env:"PORT"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Tags     []string      `env:"TAGS"`
+		DB       EmbbededStruct
+	}{User: "admin", Password: "hunter2", Port: 5432, Timeout: time.Second, Tags: []string{"a", "b"}, DB: EmbbededStruct{Host: "db"}}
+
+	var builder strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&builder, &slog.HandlerOptions{ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}}))
+	logger.Info("config", "config", LogValue(&cfg))
+	expected := `{"level":"INFO","msg":"config","config":{"User":"admin","Password":"****","Port":5432,"Timeout":1000000000,"Tags":"[a b]","DB":{"Host":"db"}}}` + "\n"
+	if builder.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, builder.String())
+	}
+
+	attrs := SlogAttrs(42)
+	if len(attrs) != 1 || attrs[0].Key != "error" {
+		t.Errorf("Expected a single error attribute, got %v", attrs)
+	}
+}
+
+func TestBanner(t *testing.T) {
+	cfg := struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD;secret"`
+	}{Host: "localhost", Password: "hunter2"}
+	info := &debug.BuildInfo{
+		GoVersion: "go1.23.0",
+		Main:      debug.Module{Path: "github.com/acme/app", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	got, err := Banner(cfg, info)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := "github.com/acme/app v1.2.3 (go1.23.0, rev 0123456789ab+dirty)\nconfig: Host=localhost Password=****"
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestFormatCollections(t *testing.T) {
+	type Upstream struct {
+		Name  string
+		Token string `env:"TOKEN;secret"`
+	}
+	cfg := struct {
+		Hosts     []string
+		Ports     []int
+		Upstreams []Upstream
+		Tokens    map[string]string `env:"TOKENS;secret"`
+		Key       []byte            `env:"KEY;secret"`
+	}{
+		Hosts:     []string{"a", "b", "c", "d", "e"},
+		Ports:     []int{80, 443},
+		Upstreams: []Upstream{{Name: "a", Token: "hunter2"}, {Name: "b"}},
+		Tokens:    map[string]string{"svc": "hunter2", "other": "hunter3"},
+		Key:       []byte("key"),
+	}
+
+	tests := map[string]string{
+		"text": "{\n    Hosts:     [\n        a\n        b\n        c\n        d\n        e\n    ]\n    Ports:     [80 443]\n" +
+			"    Upstreams: [\n        {\n            Name:  a\n            Token: ****\n        }\n        {\n            Name:  b\n            Token: \n        }\n    ]\n" +
+			"    Tokens:    map[other:**** svc:****]\n    Key:       ****\n}",
+		"yaml": "Hosts:\n  - a\n  - b\n  - c\n  - d\n  - e\nPorts: [80,443]\nUpstreams:\n  - Name: a\n    Token: \"****\"\n  - Name: b\n    Token: \"\"\n" +
+			"Tokens: {\"other\":\"****\",\"svc\":\"****\"}\nKey: \"****\"\n",
+		"logfmt": "Hosts=\"[a b c d e]\" Ports=\"[80 443]\" Upstreams=\"[{a ****} {b }]\" Tokens=\"map[other:**** svc:****]\" Key=****\n",
+	}
+	for format, expected := range tests {
+		got, err := Format(cfg, format)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if got != expected {
+			t.Errorf("Expected %s output %q, got %q", format, expected, got)
+		}
+	}
+
+	got, err := Format(cfg, "json")
+	expected := "  \"Upstreams\": [\n    {\n      \"Name\": \"a\",\n      \"Token\": \"****\"\n    },\n    {\n      \"Name\": \"b\",\n      \"Token\": \"\"\n    }\n  ],\n"
+	if err != nil || !strings.Contains(got, expected) {
+		t.Errorf("Expected json output containing %q, got %q, %v", expected, got, err)
+	}
+}
+
+func TestFormatTruncation(t *testing.T) {
+	cfg := struct {
+		Cert    string
+		Hosts   []string
+		Weights map[string]int
+	}{
+		Cert:    "-----BEGIN CERTIFICATE-----",
+		Hosts:   []string{"a", "b", "c", "d", "e"},
+		Weights: map[string]int{"b": 2, "a": 1, "c": 3},
+	}
+
+	got := FormatString(cfg, WithValueTruncation(10), WithElementLimit(2))
+	expected := "{\n    Cert:    -----BEGIN…\n    Hosts:   [a b … 3 more]\n    Weights: map[a:1 b:2 …:1 more]\n}"
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	got, err := Format(cfg, "logfmt", WithOutputLimit(20))
+	expected = "Cert=\"-----BEGIN CER…\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	old := WatchConfig{Host: "localhost", Port: 8080, Password: "hunter2"}
+	new := WatchConfig{Host: "localhost", Port: 9090, Password: "hunter3"}
+
+	got, err := FormatDiff(old, new)
+	expected := "--- old\n+++ new\n@@ -1,5 +1,5 @@\n {\n     Host:     localhost\n-    Port:     8080\n+    Port:     9090\n     Password: ****\n }\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+
+	got, err = FormatDiff(old, old)
+	if err != nil || got != "" {
+		t.Errorf("Expected no diff, got %q, %v", got, err)
+	}
+
+	var builder strings.Builder
+	err = WriteDiff(&builder, old, new, WithColor(true))
+	expected = "--- old\n+++ new\n\x1b[36m@@ -1,5 +1,5 @@\x1b[0m\n {\n     Host:     localhost\n\x1b[31m-    Port:     8080\x1b[0m\n\x1b[32m+    Port:     9090\x1b[0m\n     Password: ****\n }\n"
+	if err != nil || builder.String() != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, builder.String(), err)
+	}
+}
+
+func TestFormatDotEnv(t *testing.T) {
+	type DotEnvConfig struct {
+		Greeting string            `env:"GREETING"`
+		Hosts    []string          `env:"HOSTS"`
+		Quote    string            `env:"QUOTE"`
+		Timeout  time.Duration     `env:"TIMEOUT"`
+		Start    time.Time         `env:"START"`
+		Weights  map[string]int    `env:"WEIGHTS"`
+		Retries  *int              `env:"RETRIES;optional"`
+		Password string            `env:"PASSWORD;secret"`
+		DB       EmbbededStruct    `envPrefix:"APP_"`
+		Labels   map[string]string `env:"LABELS;optional"`
+		Internal string
+	}
+	cfg := DotEnvConfig{
+		Greeting: "hello # world",
+		Hosts:    []string{"a", "b,c"},
+		Quote:    "it's",
+		Timeout:  90 * time.Second,
+		Start:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Weights:  map[string]int{"b": 2, "a": 1},
+		Password: "hunter2",
+		DB:       EmbbededStruct{Host: "db"},
+		Labels:   map[string]string{"team": "core"},
+	}
+
+	got, err := FormatDotEnv(cfg)
+	expected := "GREETING='hello # world'\nHOSTS='[a,\"b,c\"]'\nQUOTE=\"it's\"\nTIMEOUT=1m30s\nSTART=2024-01-02T03:04:05Z\nWEIGHTS=a=1,b=2\n# RETRIES=\nPASSWORD=****\nAPP_DB_HOST=db\nLABELS=team=core\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+
+	env, err := ParseDotEnv(got)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	loaded := DotEnvConfig{}
+	err = LoadEnvWithOptions(&loaded, WithSources(MapSource(env)))
+	loaded.Password, loaded.Internal = cfg.Password, cfg.Internal
+	if err != nil || !reflect.DeepEqual(loaded, cfg) {
+		t.Errorf("Expected the written .env file to load %v, got %v, %v", cfg, loaded, err)
+	}
+
+	type Tenant struct {
+		Token string `env:"TOKEN;secret"`
+	}
+	prefixMaps := struct {
+		Features map[string]string `env:"FEATURE_;prefixmap"`
+		Tenants  map[string]Tenant `env:"TENANT_;prefixmap"`
+	}{Features: map[string]string{"DARK_MODE": "on", "BETA": "off"}, Tenants: map[string]Tenant{"ACME": {Token: "hunter2"}}}
+	got, err = FormatDotEnv(prefixMaps)
+	expected = "FEATURE_BETA=off\nFEATURE_DARK_MODE=on\nTENANT_ACME_TOKEN=****\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+
+	json, err := FormatJSON(PrintConfig{Host: "localhost"})
+	if err != nil || !strings.HasPrefix(json, "{\n  \"Host\": \"localhost\",") {
+		t.Errorf("Expected JSON output, got %q, %v", json, err)
+	}
+	yaml, err := FormatYAML(PrintConfig{Host: "localhost"})
+	if err != nil || !strings.HasPrefix(yaml, "Host: localhost\n") {
+		t.Errorf("Expected YAML output, got %q, %v", yaml, err)
+	}
+}
+
+func TestFormatOmitEmpty(t *testing.T) {
+	cfg := struct {
+		Host    string   `env:"HOST"`
+		Proxy   string   `env:"PROXY;optional"`
+		Retries *int     `env:"RETRIES;optional"`
+		Region  string   `env:"REGION;omitempty"`
+		Labels  []string `env:"LABELS;optional"`
+		Debug   bool     `env:"DEBUG;optional"`
+	}{Host: "localhost", Debug: true}
+
+	got, err := FormatDotEnv(cfg)
+	expected := "HOST=localhost\nPROXY=\n# RETRIES=\nLABELS=[]\nDEBUG=true\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+	got, err = FormatDotEnv(cfg, WithOmitEmpty())
+	expected = "HOST=localhost\nDEBUG=true\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+	got, err = Format(cfg, "logfmt", WithOmitEmpty())
+	expected = "Host=localhost Debug=true\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+}
+
+func TestRedactionPolicy(t *testing.T) {
+	SetRedactionPolicy(&RedactionPolicy{Pattern: DefaultRedactionPattern, Deny: []string{"DSN"}, Allow: []string{"TOKEN_URL"}})
+	defer SetRedactionPolicy(nil)
+
+	cfg := struct {
+		Password string `env:"DB_PASSWORD"`
+		DSN      string `env:"DSN"`
+		TokenURL string `env:"TOKEN_URL"`
+		Key      string `env:"SIGNING_KEY;secret"`
+		Host     string `env:"HOST"`
+	}{Password: "hunter2", DSN: "postgres://u:p@db", TokenURL: "https://auth/token", Key: "k", Host: "localhost"}
+	got, err := Format(cfg, "logfmt")
+	expected := "Password=**** DSN=**** TokenURL=https://auth/token Key=**** Host=localhost\n"
+	if err != nil || got != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, got, err)
+	}
+
+	report, err := LoadEnvReport(&cfg, WithSources(MapSource{"DB_PASSWORD": "a", "DSN": "b", "TOKEN_URL": "c", "SIGNING_KEY": "d", "HOST": "e"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if report.Fields[0].Value != secretMask || report.Fields[2].Value != "c" {
+		t.Errorf("Expected DB_PASSWORD masked and TOKEN_URL=c, got %v", report.Fields)
+	}
+	report, err = LoadEnvReport(&cfg, WithSources(MapSource{"DB_PASSWORD": "a", "DSN": "b", "TOKEN_URL": "c", "SIGNING_KEY": "d", "HOST": "e"}),
+		WithRedactionPolicy(&RedactionPolicy{Deny: []string{"HOST"}}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if report.Fields[0].Value != "a" || report.Fields[3].Value != secretMask || report.Fields[4].Value != secretMask {
+		t.Errorf("Expected the policy of the load to replace the default policy, got %v", report.Fields)
+	}
+}
@@ -0,0 +1,178 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+)
+
+// ellipsis marks values and output that were cut short.
+const ellipsis = "…"
+
+// PrintOption customizes the output of Format, FormatString, Banner and the diff renderers.
+type PrintOption func(*printOptions)
+
+// printOptions holds the settings of printed output, a limit of zero or less is disabled.
+type printOptions struct {
+	// maxValueLength is the number of characters a value is truncated to.
+	maxValueLength int
+	// maxElements is the number of elements shown of a slice, array or map.
+	maxElements int
+	// maxOutputSize is the number of bytes the whole output is truncated to.
+	maxOutputSize int
+	// maxDepth is the number of levels of nested structs printed.
+	maxDepth int
+	// indentWidth is the number of spaces per indentation level of the text renderer.
+	indentWidth int
+	// color enables or disables colored output of WriteDiff, if set.
+	color *bool
+	// omitEmpty leaves out the optional fields holding their zero value.
+	omitEmpty bool
+}
+
+// WithValueTruncation truncates printed values that are longer than the given number of characters and marks them with
+// an ellipsis, so large values such as PEM blocks or JSON documents do not flood the logs. Elements of slices, arrays
+// and maps are truncated one by one.
+func WithValueTruncation(length int) PrintOption {
+	return func(o *printOptions) {
+		o.maxValueLength = length
+	}
+}
+
+// WithElementLimit prints only the given number of elements of slices, arrays and maps, followed by the number of
+// elements left out, e.g. "… 3 more". Map entries are kept in the order of their keys and the number of entries left
+// out is added under the key "…".
+func WithElementLimit(elements int) PrintOption {
+	return func(o *printOptions) {
+		o.maxElements = elements
+	}
+}
+
+// WithOutputLimit truncates the whole printed output to the given number of bytes followed by an ellipsis.
+func WithOutputLimit(size int) PrintOption {
+	return func(o *printOptions) {
+		o.maxOutputSize = size
+	}
+}
+
+// WithDepthLimit prints nested structs up to the given number of levels deep, deeper structs are printed as {…}. A
+// depth of 1 prints only the fields of the config struct itself.
+func WithDepthLimit(depth int) PrintOption {
+	return func(o *printOptions) {
+		o.maxDepth = depth
+	}
+}
+
+// WithIndent indents the output of FormatString, the text renderer of Format and the diff renderers by the given number
+// of spaces per level instead of 4.
+func WithIndent(width int) PrintOption {
+	return func(o *printOptions) {
+		o.indentWidth = width
+	}
+}
+
+// WithOmitEmpty leaves every optional field that holds its zero value, e.g. an unset optional pointer or an empty
+// optional string, out of the output of Format and GenerateEnvTemplate, so exported files are not cluttered with empty
+// entries. Fields with the omitempty flag are always left out when they hold their zero value, also by CommandEnv, e.g.
+// env:"PROXY_URL;optional;omitempty".
+func WithOmitEmpty() PrintOption {
+	return func(o *printOptions) {
+		o.omitEmpty = true
+	}
+}
+
+func newPrintOptions(opts []PrintOption) printOptions {
+	var o printOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// truncateFields applies the value and element limits to the values of the fields and their nested fields. The depth
+// limit is applied by printFields.
+func (o printOptions) truncateFields(fields []PrintField) []PrintField {
+	if o.maxValueLength <= 0 && o.maxElements <= 0 {
+		return fields
+	}
+	for i := range fields {
+		switch {
+		case fields[i].IsStruct():
+			fields[i].Fields = o.truncateFields(fields[i].Fields)
+		case !fields[i].Unset:
+			fields[i].Value = o.truncateValue(reflect.ValueOf(fields[i].Value), false)
+		}
+	}
+	return fields
+}
+
+// truncateValue applies the value and element limits to a value. Collections are copied into a []interface{} or a
+// map[string]interface{} keyed by the formatted keys, structs in collections are left to be printed field by field.
+func (o printOptions) truncateValue(v reflect.Value, element bool) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if collection := derefPrinted(v); collection.IsValid() && isPrintedPerElement(collection.Type()) && !collection.IsZero() {
+		return o.truncateCollection(collection)
+	}
+	if _, isStruct := elementStruct(v); element && isStruct || o.maxValueLength <= 0 {
+		return v.Interface()
+	}
+	str := formatReflectValue(v)
+	if utf8.RuneCountInString(str) <= o.maxValueLength {
+		return v.Interface()
+	}
+	return string([]rune(str)[:o.maxValueLength]) + ellipsis
+}
+
+func (o printOptions) truncateCollection(v reflect.Value) interface{} {
+	shown := v.Len()
+	if o.maxElements > 0 && shown > o.maxElements {
+		shown = o.maxElements
+	}
+	more := fmt.Sprintf("%d more", v.Len()-shown)
+	if v.Kind() != reflect.Map {
+		elements := make([]interface{}, shown, shown+1)
+		for i := range elements {
+			elements[i] = o.truncateValue(v.Index(i), true)
+		}
+		if shown < v.Len() {
+			elements = append(elements, ellipsis+" "+more)
+		}
+		return elements
+	}
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key := formatReflectValue(iter.Key())
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+	sort.Strings(keys)
+	entries := make(map[string]interface{}, shown+1)
+	for _, key := range keys[:shown] {
+		entries[key] = o.truncateValue(values[key], true)
+	}
+	if shown < v.Len() {
+		entries[ellipsis] = more
+	}
+	return entries
+}
+
+// truncateOutput truncates rendered output to the output limit, keeping a trailing newline.
+func (o printOptions) truncateOutput(output string) string {
+	if o.maxOutputSize <= 0 || len(output) <= o.maxOutputSize {
+		return output
+	}
+	size := o.maxOutputSize
+	for size > 0 && !utf8.RuneStart(output[size]) {
+		size--
+	}
+	truncated := output[:size] + ellipsis
+	if output[len(output)-1] == '\n' {
+		truncated += "\n"
+	}
+	return truncated
+}
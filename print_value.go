@@ -0,0 +1,235 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formatValue formats a value for the text based renderers independently of the Go version and the environment:
+// pointers are dereferenced instead of printed as addresses, times use RFC 3339, locations, regular expressions and
+// templates the value they were parsed from, fmt.Stringer values use their String method, floats use the shortest
+// representation that round trips and maps are sorted by their formatted keys. Fields of structs with the secret tag
+// flag are masked.
+func formatValue(value interface{}) string {
+	return formatReflectValue(reflect.ValueOf(value))
+}
+
+func formatReflectValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "<nil>"
+	}
+	if redacted, isURL := redactedURL(v); isURL {
+		return redacted
+	}
+	if source, isParsed := sourceValue(v); isParsed {
+		return source
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339Nano)
+	}
+	if v.CanInterface() && v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String()
+	}
+	// types such as net.IPNet and mail.Address implement fmt.Stringer on their pointer
+	if v.CanInterface() && v.Kind() == reflect.Struct && reflect.PointerTo(v.Type()).Implements(stringerType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(fmt.Stringer).String()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return formatReflectValue(v.Elem())
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries = append(entries, formatReflectValue(iter.Key())+":"+formatReflectValue(iter.Value()))
+		}
+		sort.Strings(entries)
+		return "map[" + strings.Join(entries, " ") + "]"
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return "[]"
+		}
+		elements := make([]string, v.Len())
+		for i := range elements {
+			elements[i] = formatReflectValue(v.Index(i))
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	case reflect.Struct:
+		fields := make([]string, 0, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if isSkipped(v.Type().Field(i), tagName) {
+				continue
+			}
+			field := formatReflectValue(v.Field(i))
+			if tags, _ := parseTags(v.Type().Field(i), tagName); v.Type().Field(i).IsExported() && tags != nil {
+				if isSecret(tags, tags["name"]) {
+					field = formatValue(maskSecret(v.Field(i)))
+				}
+			}
+			fields = append(fields, field)
+		}
+		return "{" + strings.Join(fields, " ") + "}"
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+	if v.CanInterface() {
+		return fmt.Sprint(v.Interface())
+	}
+	return v.Type().String()
+}
+
+// maxInlineElements is the number of elements up to which the text based renderers print a slice, array or map on a
+// single line, larger collections are printed one element per line.
+const maxInlineElements = 4
+
+// maskSecret returns the printed value of a secret field. Slices, arrays and maps keep their structure with every set
+// element masked, so e.g. the keys of a map of tokens remain visible, other values are masked as a whole when set.
+func maskSecret(v reflect.Value) interface{} {
+	if v.IsZero() {
+		return v.Interface()
+	}
+	if !isPrintedPerElement(v.Type()) {
+		return secretMask
+	}
+	if v.Kind() == reflect.Map {
+		masked := reflect.MakeMapWithSize(reflect.MapOf(v.Type().Key(), anyType), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			masked.SetMapIndex(iter.Key(), reflect.ValueOf(maskSecret(iter.Value())))
+		}
+		return masked.Interface()
+	}
+	masked := make([]interface{}, v.Len())
+	for i := range masked {
+		masked[i] = maskSecret(v.Index(i))
+	}
+	return masked
+}
+
+var anyType = reflect.TypeFor[interface{}]()
+
+// isPrintedPerElement reports whether values of the type are printed and masked element by element. Byte slices hold a
+// single secret such as a key, and types with their own String method may print their elements in any way.
+func isPrintedPerElement(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return false
+	}
+	return typ.Elem().Kind() != reflect.Uint8 && !typ.Implements(stringerType)
+}
+
+// blockCollection returns the slice, array or map held by the value, dereferencing pointers and interfaces, and
+// whether it is printed one element per line: when it has more than maxInlineElements elements or holds structs.
+func blockCollection(v reflect.Value) (reflect.Value, bool) {
+	v = derefPrinted(v)
+	if !v.IsValid() || !isPrintedPerElement(v.Type()) {
+		return v, false
+	}
+	return v, v.Len() > maxInlineElements || hasStructElements(v)
+}
+
+// hasStructElements reports whether a slice, array or map holds structs that are printed field by field.
+func hasStructElements(v reflect.Value) bool {
+	if v.Kind() == reflect.Map {
+		iter := v.MapRange()
+		for iter.Next() {
+			if _, isStruct := elementStruct(iter.Value()); isStruct {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i < v.Len(); i++ {
+		if _, isStruct := elementStruct(v.Index(i)); isStruct {
+			return true
+		}
+	}
+	return false
+}
+
+// derefPrinted follows the pointers and interfaces of a value up to the value that is printed, stopping at nil values
+// and values with their own String method.
+func derefPrinted(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() && !v.Type().Implements(stringerType) {
+		v = v.Elem()
+	}
+	return v
+}
+
+// elementStruct returns the struct held by an element of a collection, dereferencing pointers and interfaces, and
+// whether the element holds a struct that is printed field by field.
+func elementStruct(v reflect.Value) (reflect.Value, bool) {
+	v = derefPrinted(v)
+	if v.Kind() != reflect.Struct || !isNestedStruct(v.Type()) || v.Type().Implements(stringerType) || checkCycles(v.Type()) != nil {
+		return v, false
+	}
+	return v, true
+}
+
+// formatBlockValue formats a value for the text renderer, printing collections with more than maxInlineElements
+// elements or holding structs one element per line, and structs in collections field by field. The indent is the
+// indentation of the line the value starts on.
+func formatBlockValue(value interface{}, indent textIndent) string {
+	v, isBlock := blockCollection(reflect.ValueOf(value))
+	if !isBlock {
+		return formatValue(value)
+	}
+	indentation := indent.String()
+	elementIndentation := indent.next().String()
+	var lines []string
+	if v.Kind() == reflect.Map {
+		entries := make([][2]string, 0, v.Len())
+		keyLen := 0
+		iter := v.MapRange()
+		for iter.Next() {
+			key := formatReflectValue(iter.Key())
+			entries = append(entries, [2]string{key, formatBlockElement(iter.Value(), indent.next())})
+			keyLen = max(keyLen, len(key)+1)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i][0] < entries[j][0] })
+		for _, entry := range entries {
+			lines = append(lines, fmt.Sprintf("%s%-*s %s", elementIndentation, keyLen, entry[0]+":", entry[1]))
+		}
+		return "map[\n" + strings.Join(lines, "\n") + "\n" + indentation + "]"
+	}
+	for i := 0; i < v.Len(); i++ {
+		lines = append(lines, elementIndentation+formatBlockElement(v.Index(i), indent.next()))
+	}
+	return "[\n" + strings.Join(lines, "\n") + "\n" + indentation + "]"
+}
+
+// formatBlockElement formats an element of a collection printed one element per line, structs are printed field by
+// field with their secret fields masked.
+func formatBlockElement(v reflect.Value, indent textIndent) string {
+	if nested, isStruct := elementStruct(v); isStruct {
+		fields := elementFields(nested)
+		if len(fields) == 0 {
+			return "{}"
+		}
+		return "{\n" + formatStruct(fields, indent.next()) + "\n" + indent.String() + "}"
+	}
+	if !v.CanInterface() {
+		return formatReflectValue(v)
+	}
+	return formatBlockValue(v.Interface(), indent)
+}
@@ -0,0 +1,175 @@
+package goloadenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var stringerType = reflect.TypeFor[fmt.Stringer]()
+
+// renderYAML renders the fields as a YAML mapping, nested structs become nested mappings. Collections are written in
+// flow style, unless they have more than maxInlineElements elements or hold structs, then they are written as block
+// sequences and mappings.
+func renderYAML(w io.Writer, fields []PrintField) error {
+	var builder strings.Builder
+	err := writeYAMLMapping(&builder, fields, 0)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, builder.String())
+	return err
+}
+
+func writeYAMLMapping(builder *strings.Builder, fields []PrintField, indent int) error {
+	indentation := strings.Repeat("  ", indent)
+	for _, field := range fields {
+		if field.IsStruct() {
+			if len(field.Fields) == 0 {
+				fmt.Fprintf(builder, "%s%s: {}\n", indentation, field.Name)
+				continue
+			}
+			fmt.Fprintf(builder, "%s%s:\n", indentation, field.Name)
+			err := writeYAMLMapping(builder, field.Fields, indent+1)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if collection, isBlock := blockCollection(reflect.ValueOf(field.Value)); isBlock {
+			fmt.Fprintf(builder, "%s%s:\n", indentation, field.Name)
+			err := writeYAMLBlock(builder, collection, indent+1)
+			if err != nil {
+				return fmt.Errorf("error rendering field '%s' as YAML: %w", field.Path, err)
+			}
+			continue
+		}
+		value, err := yamlScalar(field.Value)
+		if err != nil {
+			return fmt.Errorf("error rendering field '%s' as YAML: %w", field.Path, err)
+		}
+		fmt.Fprintf(builder, "%s%s: %s\n", indentation, field.Name, value)
+	}
+	return nil
+}
+
+// writeYAMLBlock writes a slice, array or map as a block sequence or mapping at the given indentation level, structs in
+// the collection become nested mappings.
+func writeYAMLBlock(builder *strings.Builder, collection reflect.Value, indent int) error {
+	indentation := strings.Repeat("  ", indent)
+	if collection.Kind() != reflect.Map {
+		for i := 0; i < collection.Len(); i++ {
+			if nested, isStruct := elementStruct(collection.Index(i)); isStruct {
+				err := writeYAMLSequenceMapping(builder, elementFields(nested), indent)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			value, err := yamlScalar(collection.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(builder, "%s- %s\n", indentation, value)
+		}
+		return nil
+	}
+	keys := make([]string, 0, collection.Len())
+	values := map[string]reflect.Value{}
+	iter := collection.MapRange()
+	for iter.Next() {
+		key, err := yamlScalar(iter.Key().Interface())
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if nested, isStruct := elementStruct(values[key]); isStruct {
+			fields := elementFields(nested)
+			if len(fields) == 0 {
+				fmt.Fprintf(builder, "%s%s: {}\n", indentation, key)
+				continue
+			}
+			fmt.Fprintf(builder, "%s%s:\n", indentation, key)
+			err := writeYAMLMapping(builder, fields, indent+1)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := yamlScalar(values[key].Interface())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(builder, "%s%s: %s\n", indentation, key, value)
+	}
+	return nil
+}
+
+// writeYAMLSequenceMapping writes the fields of a struct as a mapping that is an item of a block sequence.
+func writeYAMLSequenceMapping(builder *strings.Builder, fields []PrintField, indent int) error {
+	indentation := strings.Repeat("  ", indent)
+	if len(fields) == 0 {
+		fmt.Fprintf(builder, "%s- {}\n", indentation)
+		return nil
+	}
+	var item strings.Builder
+	err := writeYAMLMapping(&item, fields, indent+1)
+	if err != nil {
+		return err
+	}
+	builder.WriteString(indentation + "- " + strings.TrimPrefix(item.String(), indentation+"  "))
+	return nil
+}
+
+// yamlScalar formats a value as a single line YAML value. Strings and fmt.Stringer values are only quoted when YAML
+// would otherwise read them as another type, everything else is written as JSON, which YAML accepts as flow style.
+func yamlScalar(value interface{}) (string, error) {
+	var str string
+	v := reflect.ValueOf(value)
+	switch {
+	case value == nil || v.Kind() == reflect.Ptr && v.IsNil():
+		return "null", nil
+	case v.Kind() == reflect.String:
+		str = v.String()
+	case v.Type().Implements(stringerType):
+		str = value.(fmt.Stringer).String()
+	case v.Kind() == reflect.Ptr:
+		return yamlScalar(v.Elem().Interface())
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	if yamlPlainSafe(str) {
+		return str, nil
+	}
+	return strconv.Quote(str), nil
+}
+
+// yamlPlainSafe reports whether a string can be written as a plain YAML scalar without changing its meaning.
+func yamlPlainSafe(str string) bool {
+	if str == "" || strings.TrimSpace(str) != str {
+		return false
+	}
+	switch strings.ToLower(str) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return false
+	}
+	if _, err := strconv.ParseFloat(str, 64); err == nil {
+		return false
+	}
+	if strings.ContainsAny(str[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	return !strings.Contains(str, ": ") && !strings.Contains(str, " #") && !strings.ContainsAny(str, "\n\t")
+}
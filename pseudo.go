@@ -0,0 +1,59 @@
+package goloadenv
+
+import (
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+)
+
+// pseudoVars are the built-in variables that can be referenced in default values and expanded values as $NAME or
+// ${NAME}, so defaults can adapt to the machine they run on. In expanded values, a set variable of that name wins.
+var pseudoVars = map[string]func() (string, error){
+	"NUMCPU": func() (string, error) {
+		return strconv.Itoa(runtime.NumCPU()), nil
+	},
+	"GOMAXPROCS": func() (string, error) {
+		return strconv.Itoa(runtime.GOMAXPROCS(0)), nil
+	},
+	"HOSTNAME": os.Hostname,
+	"PID": func() (string, error) {
+		return strconv.Itoa(os.Getpid()), nil
+	},
+}
+
+// pseudoVarPattern matches a $$ escape, or a complete ${NAME} or $NAME reference, so $PID_FILE is not read as $PID.
+var pseudoVarPattern = regexp.MustCompile(`\$\$|\$\{([A-Z_][A-Z0-9_]*)\}|\$([A-Z_][A-Z0-9_]*)`)
+
+// expandPseudoVars replaces the references to pseudo variables in a string with their values and $$ with a literal $,
+// references to unknown names are left untouched.
+func expandPseudoVars(str string) (string, error) {
+	var expandErr error
+	expanded := pseudoVarPattern.ReplaceAllStringFunc(str, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		match := pseudoVarPattern.FindStringSubmatch(ref)
+		resolve, found := pseudoVars[match[1]+match[2]]
+		if !found {
+			return ref
+		}
+		value, err := resolve()
+		if err != nil && expandErr == nil {
+			expandErr = err
+		}
+		return value
+	})
+	return expanded, expandErr
+}
+
+// hasPseudoVarReference reports whether a string holds a reference that expandPseudoVars would replace with the value
+// of a pseudo variable, unlike a literal $$.
+func hasPseudoVarReference(str string) bool {
+	for _, match := range pseudoVarPattern.FindAllStringSubmatch(str, -1) {
+		if _, found := pseudoVars[match[1]+match[2]]; found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package goloadenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateUnits maps the unit names accepted in a RateLimit to their durations.
+var rateUnits = map[string]time.Duration{
+	"ms": time.Millisecond, "s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "d": 24 * time.Hour, "day": 24 * time.Hour,
+}
+
+// RateLimit is a rate limit specification parsed from values like RATE_LIMIT=100/min or RATE_LIMIT=10/s burst=20.
+// The period is a unit (ms, s, sec, second, m, min, minute, h, hour, d or day) or a duration like 10s. The burst
+// defaults to the count.
+type RateLimit struct {
+	// Count is the number of events allowed per period.
+	Count int
+	// Per is the period the count applies to.
+	Per time.Duration
+	// Burst is the number of events allowed at once.
+	Burst int
+}
+
+// UnmarshalText parses a rate limit specification.
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("invalid rate limit '%s', expected <count>/<period> [burst=<n>]", text)
+	}
+	countText, periodText, found := strings.Cut(fields[0], "/")
+	if !found {
+		return fmt.Errorf("invalid rate limit '%s', expected <count>/<period> [burst=<n>]", text)
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count <= 0 {
+		return fmt.Errorf("invalid rate limit count '%s', expected a positive integer", countText)
+	}
+	per, found := rateUnits[periodText]
+	if !found {
+		per, err = time.ParseDuration(periodText)
+		if err != nil || per <= 0 {
+			return fmt.Errorf("invalid rate limit period '%s'", periodText)
+		}
+	}
+	burst := count
+	if len(fields) == 2 {
+		burstText, found := strings.CutPrefix(fields[1], "burst=")
+		burst, err = strconv.Atoi(burstText)
+		if !found || err != nil || burst < 0 {
+			return fmt.Errorf("invalid rate limit burst '%s', expected burst=<n>", fields[1])
+		}
+	}
+	*r = RateLimit{Count: count, Per: per, Burst: burst}
+	return nil
+}
+
+// PerSecond returns the rate in events per second, e.g. for rate.Limit of golang.org/x/time/rate.
+func (r RateLimit) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Count) / r.Per.Seconds()
+}
+
+// Interval returns the time between two events at the steady rate.
+func (r RateLimit) Interval() time.Duration {
+	if r.Count <= 0 {
+		return 0
+	}
+	return r.Per / time.Duration(r.Count)
+}
+
+// String formats the rate limit back into its specification.
+func (r RateLimit) String() string {
+	return fmt.Sprintf("%d/%s burst=%d", r.Count, r.Per, r.Burst)
+}
@@ -0,0 +1,127 @@
+package goloadenv
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RedactionPolicy marks fields as secret by the name of their environment variable, in addition to the secret tag
+// flag, so secrets are masked even when the flag was forgotten. Names are matched as in the struct tags, including the
+// prefixes of envPrefix tags.
+type RedactionPolicy struct {
+	// Pattern marks the fields whose variable name it matches as secret, e.g. regexp.MustCompile(`PASSWORD|TOKEN|SECRET`).
+	Pattern *regexp.Regexp
+	// Deny lists variable names that are always secret.
+	Deny []string
+	// Allow lists variable names that are not marked secret by the Pattern, e.g. TOKEN_URL. Fields with the secret tag
+	// flag stay secret.
+	Allow []string
+}
+
+// DefaultRedactionPattern matches the variable names that usually hold secrets, e.g. DB_PASSWORD or API_TOKEN.
+var DefaultRedactionPattern = regexp.MustCompile(`(?i)PASSWORD|PASSWD|TOKEN|SECRET|API_?KEY|PRIVATE_?KEY|CREDENTIAL`)
+
+var (
+	redactionMu     sync.RWMutex
+	redactionPolicy *RedactionPolicy
+)
+
+// SetRedactionPolicy sets the default redaction policy, applied by the printers, diffs and every other function
+// treating secret fields differently, and by the loads without the WithRedactionPolicy option, replacing any policy set
+// before. A nil policy only relies on the secret tag flag, which is the default.
+//
+// Example:
+//
+//	goloadenv.SetRedactionPolicy(&goloadenv.RedactionPolicy{Pattern: goloadenv.DefaultRedactionPattern, Allow: []string{"TOKEN_URL"}})
+func SetRedactionPolicy(policy *RedactionPolicy) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+	redactionPolicy = policy
+}
+
+// WithRedactionPolicy applies the given redaction policy to the load instead of the default policy set with
+// SetRedactionPolicy, marking the fields whose variable name it matches as secret, so their values are masked in the
+// errors, reports and hooks of the load. A nil policy only relies on the secret tag flag.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithRedactionPolicy(&goloadenv.RedactionPolicy{Deny: []string{"DSN"}}))
+func WithRedactionPolicy(policy *RedactionPolicy) Option {
+	return func(l *loader) {
+		l.redactionPolicy = policy
+	}
+}
+
+// defaultRedactionPolicy returns the redaction policy set with SetRedactionPolicy.
+func defaultRedactionPolicy() *RedactionPolicy {
+	redactionMu.RLock()
+	defer redactionMu.RUnlock()
+	return redactionPolicy
+}
+
+// marks reports whether the policy marks the variable with the given name as secret. A nil policy marks none.
+func (p *RedactionPolicy) marks(name string) bool {
+	if p == nil || name == "" {
+		return false
+	}
+	if slices.Contains(p.Deny, name) {
+		return true
+	}
+	if slices.Contains(p.Allow, name) {
+		return false
+	}
+	return p.Pattern != nil && p.Pattern.MatchString(name)
+}
+
+// isSecret reports whether a field is secret, by the secret or encrypted flag in its tags or by the default redaction
+// policy for the name of its variable.
+func isSecret(tags map[string]string, name string) bool {
+	return hasSecretFlag(tags) || defaultRedactionPolicy().marks(name)
+}
+
+// hasSecretFlag reports whether the tags of a field have the secret or encrypted flag. The tags of a loaded field carry
+// the secret flag when the redaction policy of the load marks it.
+func hasSecretFlag(tags map[string]string) bool {
+	_, isSecret := tags["secret"]
+	_, isEncrypted := tags["encrypted"]
+	return isSecret || isEncrypted
+}
+
+// redactedError masks the value of a secret field in the message of an error, which may quote the value, e.g. the
+// error of time.ParseDuration.
+type redactedError struct {
+	err   error
+	value string
+}
+
+func (e *redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.value, secretMask)
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError masks the value of a secret field in its parse and validation errors, so secrets do not leak into
+// error messages, logs and reports.
+// used internally by LoadEnv.
+func redactError(err error, tags map[string]string) error {
+	if err == nil || !hasSecretFlag(tags) {
+		return err
+	}
+	var parseErr *EnvParseError
+	if errors.As(err, &parseErr) && parseErr.value != secretMask {
+		if parseErr.value != "" {
+			parseErr.err = &redactedError{err: parseErr.err, value: parseErr.value}
+		}
+		parseErr.value = secretMask
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		validationErr.Value = secretMask
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+//go:build windows
+
+package goloadenv
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// registryRoots maps the names of the predefined registry keys, in full and abbreviated, to their handles.
+var registryRoots = map[string]syscall.Handle{
+	"HKEY_CLASSES_ROOT":   syscall.HKEY_CLASSES_ROOT,
+	"HKCR":                syscall.HKEY_CLASSES_ROOT,
+	"HKEY_CURRENT_USER":   syscall.HKEY_CURRENT_USER,
+	"HKCU":                syscall.HKEY_CURRENT_USER,
+	"HKEY_LOCAL_MACHINE":  syscall.HKEY_LOCAL_MACHINE,
+	"HKLM":                syscall.HKEY_LOCAL_MACHINE,
+	"HKEY_USERS":          syscall.HKEY_USERS,
+	"HKU":                 syscall.HKEY_USERS,
+	"HKEY_CURRENT_CONFIG": syscall.HKEY_CURRENT_CONFIG,
+	"HKCC":                syscall.HKEY_CURRENT_CONFIG,
+}
+
+// RegistrySource returns an EnvSource reading the values of the registry key at the given path, e.g.
+// HKLM\Software\MyApp, as Windows services are often configured through the registry. A variable is looked up as the
+// value with the same name, string values are returned as is, DWORD and QWORD values as decimal numbers and multi
+// string values joined with commas. The key is opened on every lookup, so changes are picked up by a Watcher. It
+// returns an error if the key cannot be opened.
+//
+// Example:
+//
+//	registry, err := goloadenv.RegistrySource(`HKLM\Software\MyApp`)
+//	if err != nil {
+//	  return err
+//	}
+//	err = goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithSources(goloadenv.ProcessEnv, registry))
+func RegistrySource(path string) (EnvSource, error) {
+	rootName, subkey, _ := strings.Cut(path, `\`)
+	root, found := registryRoots[strings.ToUpper(rootName)]
+	if !found {
+		return nil, fmt.Errorf("unknown registry root key '%s' in %s", rootName, path)
+	}
+	source := registrySource{root: root, subkey: subkey}
+	key, err := source.open()
+	if err != nil {
+		return nil, fmt.Errorf("error opening registry key %s: %w", path, err)
+	}
+	_ = syscall.RegCloseKey(key)
+	return source, nil
+}
+
+// registrySource looks variables up as the values of a registry key.
+type registrySource struct {
+	root   syscall.Handle
+	subkey string
+}
+
+func (s registrySource) open() (syscall.Handle, error) {
+	subkey, err := syscall.UTF16PtrFromString(s.subkey)
+	if err != nil {
+		return 0, err
+	}
+	var key syscall.Handle
+	err = syscall.RegOpenKeyEx(s.root, subkey, 0, syscall.KEY_READ, &key)
+	return key, err
+}
+
+func (s registrySource) Lookup(name string) (string, bool) {
+	key, err := s.open()
+	if err != nil {
+		return "", false
+	}
+	defer syscall.RegCloseKey(key)
+	value, err := queryRegistryValue(key, name)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// queryRegistryValue reads the value with the given name of an open registry key as a string.
+func queryRegistryValue(key syscall.Handle, name string) (string, error) {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return "", err
+	}
+	var valueType, size uint32
+	err = syscall.RegQueryValueEx(key, namePtr, nil, &valueType, nil, &size)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, size)
+	for {
+		var data *byte
+		if len(buf) > 0 {
+			data = &buf[0]
+		}
+		size = uint32(len(buf))
+		err = syscall.RegQueryValueEx(key, namePtr, nil, &valueType, data, &size)
+		if !errors.Is(err, syscall.ERROR_MORE_DATA) {
+			break
+		}
+		// the value grew between the calls
+		buf = make([]byte, size)
+	}
+	if err != nil {
+		return "", err
+	}
+	buf = buf[:size]
+	switch valueType {
+	case syscall.REG_SZ, syscall.REG_EXPAND_SZ:
+		value, _, _ := strings.Cut(decodeUTF16(buf), "\x00")
+		return value, nil
+	case syscall.REG_MULTI_SZ:
+		values := strings.Split(strings.TrimRight(decodeUTF16(buf), "\x00"), "\x00")
+		return strings.Join(values, ","), nil
+	case syscall.REG_DWORD:
+		if len(buf) != 4 {
+			return "", fmt.Errorf("invalid DWORD registry value %s", name)
+		}
+		return strconv.FormatUint(uint64(binary.LittleEndian.Uint32(buf)), 10), nil
+	case syscall.REG_QWORD:
+		if len(buf) != 8 {
+			return "", fmt.Errorf("invalid QWORD registry value %s", name)
+		}
+		return strconv.FormatUint(binary.LittleEndian.Uint64(buf), 10), nil
+	}
+	return "", fmt.Errorf("unsupported type %d of registry value %s", valueType, name)
+}
+
+// decodeUTF16 decodes a UTF-16 registry string including its null characters.
+func decodeUTF16(buf []byte) string {
+	if len(buf) < 2 {
+		return ""
+	}
+	return string(utf16.Decode(unsafe.Slice((*uint16)(unsafe.Pointer(&buf[0])), len(buf)/2)))
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package goloadenv
+
+import (
+	"testing"
+)
+
+func TestRegistrySource(t *testing.T) {
+	source, err := RegistrySource(`HKLM\SOFTWARE\Microsoft\Windows NT\CurrentVersion`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value, found := source.Lookup("CurrentBuildNumber"); !found || value == "" {
+		t.Errorf("Expected CurrentBuildNumber to be set, got %q", value)
+	}
+	if _, found := source.Lookup("GOLOADENV_MISSING"); found {
+		t.Errorf("Expected GOLOADENV_MISSING to be unset")
+	}
+
+	_, err = RegistrySource(`HKXX\Software`)
+	expected := `unknown registry root key 'HKXX' in HKXX\Software`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,99 @@
+package goloadenv
+
+import (
+	"reflect"
+)
+
+// Origin describes where the value of a field came from.
+type Origin string
+
+const (
+	// OriginFlag means the value was read from the command-line flag of the field, see WithFlags.
+	OriginFlag Origin = "flag"
+	// OriginEnv means the value was read from the environment.
+	OriginEnv Origin = "env"
+	// OriginConfigFile means the value was read from the config file of WithConfigFile, at the key of the file tag.
+	OriginConfigFile Origin = "configfile"
+	// OriginFile means the value was read from the file named by the *_FILE variable of the field.
+	OriginFile Origin = "file"
+	// OriginSecretRef means the value was resolved from the secret reference of the secretref tag option.
+	OriginSecretRef Origin = "secretref"
+	// OriginSecretSource means the value of a secret field was read from the sources of the WithSecretSources option.
+	OriginSecretSource Origin = "secretsource"
+	// OriginDefault means the value is the default value from the tag.
+	OriginDefault Origin = "default"
+	// OriginUnset means no value was found and the optional field was left untouched.
+	OriginUnset Origin = "unset"
+)
+
+// IsSet reports whether the value was configured by the operator, through the environment, a file, a flag or a secret
+// store, rather than taken from a default or left unset.
+func (o Origin) IsSet() bool {
+	return o != OriginDefault && o != OriginUnset && o != ""
+}
+
+// FieldReport describes the outcome of loading a single field.
+type FieldReport struct {
+	// Path is the dotted path of the field from the root config struct, e.g. "DB.Host".
+	Path string
+	// Env is the name of the environment variable consulted.
+	Env string
+	// Origin is where the value came from.
+	Origin Origin
+	// Value is the final value of the field, masked for secret fields.
+	Value interface{}
+	// Err is the error loading the field, if any.
+	Err error
+}
+
+// Report describes the outcome of a load, see LoadEnvReport.
+type Report struct {
+	// Fields holds the report of every tagged field, in declaration order.
+	Fields []FieldReport
+	// Warnings holds the problems that did not abort the load.
+	Warnings []error
+	// Skipped holds the dotted paths of the fields skipped as their type cannot be parsed, see UnsupportedFieldError.
+	Skipped []string
+}
+
+// IsSet reports whether the field at the given dotted path, e.g. "DB.Host", was configured by the operator rather than
+// taken from a default or left unset, see Origin.IsSet. It returns false for paths that are not in the report.
+func (r *Report) IsSet(path string) bool {
+	for _, field := range r.Fields {
+		if field.Path == path {
+			return field.Origin.IsSet()
+		}
+	}
+	return false
+}
+
+// WasSet returns whether every field of the report was configured by the operator, by the dotted path of the field.
+func (r *Report) WasSet() map[string]bool {
+	set := make(map[string]bool, len(r.Fields))
+	for _, field := range r.Fields {
+		set[field.Path] = field.Origin.IsSet()
+	}
+	return set
+}
+
+// record adds the outcome of loading a field to the report, if one is requested.
+func (l *loader) record(path string, field reflect.Value, tags map[string]string, origin Origin, err error) {
+	if l.report == nil {
+		return
+	}
+	value := field.Interface()
+	if hasSecretFlag(tags) {
+		value = maskSecret(field)
+	}
+	fieldReport := FieldReport{
+		Path:   path,
+		Env:    tags["name"],
+		Origin: origin,
+		Value:  value,
+		Err:    err,
+	}
+	l.report.Fields = append(l.report.Fields, fieldReport)
+	for _, hook := range l.hooks {
+		hook.OnFieldResolved(fieldReport)
+	}
+}
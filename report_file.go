@@ -0,0 +1,136 @@
+package goloadenv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// reportFile is the JSON document written by WriteReportFile.
+type reportFile struct {
+	Status      string            `json:"status"`
+	Error       string            `json:"error,omitempty"`
+	Warnings    []string          `json:"warnings"`
+	Fields      []reportFileField `json:"fields"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+type reportFileField struct {
+	Path   string `json:"path"`
+	Env    string `json:"env"`
+	Origin Origin `json:"origin"`
+	Value  string `json:"value"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WriteReportFile writes a load report and the error of the load, if any, as JSON to the given path, readable only by
+// its owner. The document holds the status, "ok" or "failed", the error and warnings as strings, the variable, origin
+// and value of every field with secrets masked, and a fingerprint: the SHA-256 of the variables and values, which only
+// changes when the loaded configuration does. As secrets are masked, rotating a secret does not change the
+// fingerprint.
+func WriteReportFile(path string, report *Report, loadErr error) error {
+	document := reportFile{Status: "ok", Warnings: []string{}, Fields: []reportFileField{}}
+	if loadErr != nil {
+		document.Status = "failed"
+		document.Error = loadErr.Error()
+	}
+	for _, warning := range report.Warnings {
+		document.Warnings = append(document.Warnings, warning.Error())
+	}
+	for _, field := range report.Fields {
+		entry := reportFileField{Path: field.Path, Env: field.Env, Origin: field.Origin, Value: formatValue(field.Value)}
+		if field.Err != nil {
+			entry.Error = field.Err.Error()
+		}
+		document.Fields = append(document.Fields, entry)
+	}
+	document.Fingerprint = fingerprint(report)
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, append(content, '\n'), 0o600)
+	if err != nil {
+		return fmt.Errorf("error writing report file: %w", err)
+	}
+	return nil
+}
+
+// Fingerprint returns a stable fingerprint of the effective configuration held by a config struct, so deployments can
+// log the version of their configuration at startup and compare it across instances and releases. It is the SHA-256
+// of the variable and value of every tagged field in declaration order, formatted as in the report of WriteReportFile,
+// so it only changes when the configuration does. Secret values are masked before hashing, so the fingerprint is safe
+// to log, and only reflects whether a secret is set: rotating a secret does not change it, see SecretFingerprint. The
+// options should match the options the config was loaded with, so e.g. WithPrefix is applied to the names. The config
+// may be a struct or a pointer to a struct.
+//
+// Example:
+//
+//	fingerprint, err := goloadenv.Fingerprint(&cfg)
+//	slog.Info("configuration loaded", "fingerprint", fingerprint)
+func Fingerprint(config interface{}, opts ...Option) (string, error) {
+	hash := sha256.New()
+	err := newLoader(opts...).iterate(config, func(f FieldInfo, v reflect.Value) error {
+		if f.Name == "" {
+			return nil
+		}
+		value := v.Interface()
+		if hasSecretFlag(f.Tags) {
+			value = maskSecret(v)
+		}
+		writeFingerprintField(hash, f.Name, value)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// SecretFingerprint returns a fingerprint of the values of the secret fields of a config struct, which the masked
+// Fingerprint leaves out, so a rotated secret can be detected without logging it. It is the HMAC-SHA256 with the given
+// key of the variable and value of every secret field and Secret in declaration order. The key must be kept secret, as
+// an unkeyed hash of a short secret can be brute forced. The options should match the options the config was loaded
+// with.
+//
+// Example:
+//
+//	secrets, err := goloadenv.SecretFingerprint(&cfg, key)
+//	slog.Info("configuration loaded", "secrets", secrets)
+func SecretFingerprint(config interface{}, key []byte, opts ...Option) (string, error) {
+	hash := hmac.New(sha256.New, key)
+	err := newLoader(opts...).iterate(config, func(f FieldInfo, v reflect.Value) error {
+		if f.Name == "" || !f.StructField.IsExported() {
+			return nil
+		}
+		if secret, isSecret := v.Interface().(Secret); isSecret {
+			writeFingerprintField(hash, f.Name, secret.Expose())
+		} else if hasSecretFlag(f.Tags) {
+			writeFingerprintField(hash, f.Name, envValue(v, f.Tags))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fingerprint returns the SHA-256 of the variables and masked values of the fields of a report, see WriteReportFile.
+func fingerprint(report *Report) string {
+	hash := sha256.New()
+	for _, field := range report.Fields {
+		writeFingerprintField(hash, field.Env, field.Value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeFingerprintField adds the variable and value of a field to a fingerprint.
+func writeFingerprintField(hash io.Writer, env string, value interface{}) {
+	fmt.Fprintf(hash, "%s=%q\n", env, formatValue(value))
+}
@@ -0,0 +1,169 @@
+package goloadenv
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadEnvReport(t *testing.T) {
+	clearTestEnv()
+
+	err := os.Setenv("HOST", "localhost")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = os.Setenv("PASSWORD", "hunter2")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg := struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD;secret"`
+		DB       struct {
+			Port int `env:"PORT;default:5432"`
+		} `envPrefix:"DB_"`
+		Debug bool `env:"DEBUG;optional"`
+	}{}
+
+	report, err := LoadEnvReport(&cfg)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expected := []FieldReport{
+		{Path: "Host", Env: "HOST", Origin: OriginEnv, Value: "localhost"},
+		{Path: "Password", Env: "PASSWORD", Origin: OriginEnv, Value: "****"},
+		{Path: "DB.Port", Env: "DB_PORT", Origin: OriginDefault, Value: 5432},
+		{Path: "Debug", Env: "DEBUG", Origin: OriginUnset, Value: false},
+	}
+	if !reflect.DeepEqual(report.Fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, report.Fields)
+	}
+	if !report.IsSet("Host") || report.IsSet("DB.Port") || report.IsSet("Debug") || report.IsSet("Missing") {
+		t.Errorf("Expected only Host and Password to be set, got %v", report.WasSet())
+	}
+	expectedSet := map[string]bool{"Host": true, "Password": true, "DB.Port": false, "Debug": false}
+	if !reflect.DeepEqual(report.WasSet(), expectedSet) {
+		t.Errorf("Expected %v, got %v", expectedSet, report.WasSet())
+	}
+}
+
+func TestWithReportFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	someStruct := struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT"`
+		Password string `env:"PASSWORD;secret;optional"`
+	}{}
+
+	err := LoadEnvWithOptions(&someStruct, WithSources(MapSource{"HOST": "localhost", "PORT": "8080", "PASSWORD": "hunter2"}), WithReportFile(path))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report struct {
+		Status      string
+		Fields      []map[string]string
+		Fingerprint string
+	}
+	err = json.Unmarshal(content, &report)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if report.Status != "ok" || len(report.Fields) != 3 || report.Fields[2]["value"] != secretMask || len(report.Fingerprint) != 64 {
+		t.Errorf("Expected an ok report with a masked password and a fingerprint, got %s", content)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(MapSource{"HOST": "localhost", "PORT": "http"}), WithReportFile(path))
+	if err == nil {
+		t.Errorf("Expected an error, got nil")
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := `"status": "failed",
+  "error": "error parsing 'http' as environment variable PORT: invalid syntax for int"`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("Expected report containing %s, got %s", expected, content)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	type Config struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT"`
+		Password string `env:"PASSWORD;secret;optional"`
+		State    string
+	}
+	config := Config{}
+	report, err := LoadEnvReport(&config, WithSources(MapSource{"HOST": "localhost", "PORT": "8080", "PASSWORD": "hunter2"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first, err := Fingerprint(&config)
+	if err != nil || first != fingerprint(report) {
+		t.Errorf("Expected the fingerprint of the report %s, got %s and %v", fingerprint(report), first, err)
+	}
+
+	config.State, config.Password = "running", "rotated"
+	second, _ := Fingerprint(config)
+	if second != first {
+		t.Errorf("Expected untagged fields and rotated secrets to keep the fingerprint %s, got %s", first, second)
+	}
+	config.Password = ""
+	third, _ := Fingerprint(config)
+	config.Password, config.Port = "rotated", 9090
+	fourth, _ := Fingerprint(config)
+	if third == first || fourth == first || third == fourth {
+		t.Errorf("Expected unsetting a secret and changing a value to change the fingerprint, got %s, %s and %s", first, third, fourth)
+	}
+
+	_, err = Fingerprint("config")
+	expected := "config must be a struct or a pointer to a struct"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	prefixed := Config{}
+	opts := []Option{WithPrefix("APP_"), WithDerivedNames(), WithSources(MapSource{"APP_HOST": "localhost", "APP_PORT": "8080", "APP_STATE": "running"})}
+	report, err = LoadEnvReport(&prefixed, opts...)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	withOptions, err := Fingerprint(&prefixed, opts...)
+	if err != nil || withOptions != fingerprint(report) {
+		t.Errorf("Expected the fingerprint of the report %s with the load options, got %s and %v", fingerprint(report), withOptions, err)
+	}
+}
+
+func TestSecretFingerprint(t *testing.T) {
+	type Config struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD;secret"`
+		Token    Secret `env:"TOKEN"`
+	}
+	token, _ := NewSecret("t0ken")
+	config := Config{Host: "localhost", Password: "hunter2", Token: token}
+	key := []byte("fingerprint-key")
+	first, err := SecretFingerprint(&config, key)
+	if err != nil || len(first) != 64 {
+		t.Errorf("Expected a fingerprint, got %s and %v", first, err)
+	}
+
+	config.Host = "db"
+	second, _ := SecretFingerprint(config, key)
+	otherKey, _ := SecretFingerprint(config, []byte("other-key"))
+	config.Token, _ = NewSecret("rotated")
+	third, _ := SecretFingerprint(config, key)
+	if second != first || otherKey == first || third == first {
+		t.Errorf("Expected only the secrets and the key to change the fingerprint, got %s, %s, %s and %s", first, second, otherKey, third)
+	}
+}
@@ -0,0 +1,71 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// requireIf applies the requiredif option of a field, e.g. requiredif:TLS_ENABLED=true, which makes the field required
+// when the condition holds and optional otherwise. It returns the condition with the prefix of the field applied and
+// the value of its variable, masked when the variable is secret, when it holds, and empty strings otherwise.
+// used internally by LoadEnv.
+func (l *loader) requireIf(tags map[string]string, prefix string) (string, string, error) {
+	condition, hasCondition := tags["requiredif"]
+	if !hasCondition || l.examples || l.defaultsOnly {
+		return "", "", nil
+	}
+	name, expected, hasValue := strings.Cut(condition, "=")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", &EnvParseError{value: condition, env: tags["name"], err: fmt.Errorf("invalid requiredif condition '%s', expected NAME or NAME=VALUE", condition)}
+	}
+	name = prefix + name
+	// the value of a field loaded earlier includes its default value
+	value, found := l.conditionValues[name]
+	_, isSecret := l.conditionSecrets[name]
+	if !found {
+		value, _ = l.lookup(name)
+		isSecret = l.redactionPolicy.marks(name)
+	}
+	if !conditionHolds(value, expected, hasValue) {
+		tags["optional"] = ""
+		return "", "", nil
+	}
+	delete(tags, "optional")
+	if isSecret {
+		value = secretMask
+	}
+	if hasValue {
+		return name + "=" + expected, value, nil
+	}
+	return name, value, nil
+}
+
+// conditionHolds reports whether the value of the variable of a requiredif condition matches the expected value, or
+// is not empty when the condition has no value. Boolean values match regardless of how they are written, e.g. 1 and
+// yes match true.
+func conditionHolds(value string, expected string, hasValue bool) bool {
+	if !hasValue {
+		return value != ""
+	}
+	if value == expected {
+		return true
+	}
+	actualBool, err := parseBool(value)
+	if err != nil {
+		return false
+	}
+	expectedBool, err := parseBool(expected)
+	return err == nil && actualBool == expectedBool
+}
+
+// withCondition adds the requiredif condition that made a field required, and the value of its variable, to the error
+// of a missing variable.
+func withCondition(err error, condition string, value string) error {
+	var notFoundErr *EnvNotFoundError
+	if condition != "" && errors.As(err, &notFoundErr) {
+		notFoundErr.Condition, notFoundErr.ConditionValue = condition, value
+	}
+	return err
+}
@@ -0,0 +1,189 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var scannerType = reflect.TypeFor[fmt.Scanner]()
+
+// setScalarField assigns string, integer, unsigned integer, float, complex and bool values directly through the
+// reflect.Value setters with strconv, which rejects partial parses like "8080abc" and reports overflows of the field
+// size, e.g. value 70000 overflows uint16. Integers are parsed in the base of the base tag option, if set. It reports
+// whether the field kind was handled, types implementing fmt.Scanner are left to scanField so their custom scanning is
+// respected.
+// used internally by setField.
+func setScalarField(field reflect.Value, str string, tags map[string]string) (bool, error) {
+	if reflect.PointerTo(field.Type()).Implements(scannerType) {
+		return false, nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		digits, base, err := intDigits(str, tags)
+		if err != nil {
+			return true, err
+		}
+		value, err := strconv.ParseInt(digits, base, field.Type().Bits())
+		if err != nil {
+			return true, numberError(err, str, field.Kind())
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if strings.HasPrefix(strings.TrimSpace(str), "-") {
+			return true, fmt.Errorf("negative value %s for %s", str, field.Kind())
+		}
+		digits, base, err := intDigits(str, tags)
+		if err != nil {
+			return true, err
+		}
+		value, err := strconv.ParseUint(digits, base, field.Type().Bits())
+		if err != nil {
+			return true, numberError(err, str, field.Kind())
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(str, field.Type().Bits())
+		if err != nil {
+			return true, numberError(err, str, field.Kind())
+		}
+		field.SetFloat(value)
+	case reflect.Complex64, reflect.Complex128:
+		value, err := strconv.ParseComplex(str, field.Type().Bits())
+		if err != nil {
+			return true, numberError(err, str, field.Kind())
+		}
+		field.SetComplex(value)
+	case reflect.Bool:
+		value, err := parseBool(str)
+		if err != nil {
+			return true, err
+		}
+		field.SetBool(value)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// boolValues maps the lower case boolean values accepted by parseBool to their value.
+var boolValues = map[string]bool{
+	"true": true, "yes": true, "on": true, "1": true, "enabled": true, "t": true, "y": true,
+	"false": false, "no": false, "off": false, "0": false, "disabled": false, "f": false, "n": false,
+}
+
+// parseBool parses a boolean case-insensitively from true/false, yes/no, on/off, 1/0, enabled/disabled and their
+// single letter forms, as operators routinely write FEATURE_X=yes.
+func parseBool(str string) (bool, error) {
+	value, found := boolValues[strings.ToLower(strings.TrimSpace(str))]
+	if !found {
+		return false, errors.New("invalid syntax for bool")
+	}
+	return value, nil
+}
+
+// checkStrictBool returns an error unless a boolean is written as true or false, for fields with the strictbool flag.
+func checkStrictBool(str string) error {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "true", "false":
+		return nil
+	}
+	return errors.New("boolean values must be true or false")
+}
+
+// numberError replaces the errors of strconv, which repeat the function and the value, with a short description of the
+// problem for the kind of the field.
+func numberError(err error, str string, kind reflect.Kind) error {
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		return err
+	}
+	if errors.Is(numErr.Err, strconv.ErrRange) {
+		return fmt.Errorf("value %s overflows %s", str, kind)
+	}
+	return fmt.Errorf("invalid syntax for %s", kind)
+}
+
+// scanField parses a string into an addressable field whose type implements fmt.Scanner, failing when the scanner
+// does not consume the whole value. Fields of other kinds that are not handled by setScalarField are not supported.
+// used internally by setField.
+func scanField(field reflect.Value, str string) error {
+	if !reflect.PointerTo(field.Type()).Implements(scannerType) {
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	var rest string
+	n, err := fmt.Sscan(str, field.Addr().Interface(), &rest)
+	if n == 2 {
+		return fmt.Errorf("unexpected text '%s' after %s value", rest, field.Type())
+	}
+	if n == 0 {
+		return err
+	}
+	return nil
+}
+
+// intBasePrefixes maps the bases with a conventional prefix to it.
+var intBasePrefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+// intDigits returns the digits of an integer and the base to parse them in. With the base tag option, e.g. base:16,
+// the value is parsed in that base and may carry the conventional prefix of the base, so 0xFF and FF are both 255.
+// Without it the base is derived from the prefix of the value, see intBase.
+func intDigits(str string, tags map[string]string) (string, int, error) {
+	base, err := tagBase(tags)
+	if err != nil {
+		return "", 0, err
+	}
+	if base == 0 {
+		return str, intBase(str), nil
+	}
+	prefix, hasPrefix := intBasePrefixes[base]
+	unsigned := strings.TrimLeft(str, "+-")
+	if hasPrefix && len(unsigned) > len(prefix) && strings.EqualFold(unsigned[:len(prefix)], prefix) {
+		return str[:len(str)-len(unsigned)] + unsigned[len(prefix):], base, nil
+	}
+	return str, base, nil
+}
+
+// tagBase returns the base of the base tag option, or 0 when the field has none.
+func tagBase(tags map[string]string) (int, error) {
+	baseOption, hasBase := tags["base"]
+	if !hasBase {
+		return 0, nil
+	}
+	base, err := strconv.Atoi(baseOption)
+	if err != nil || base < 2 || base > 36 {
+		return 0, fmt.Errorf("invalid base '%s', expected 2 to 36", baseOption)
+	}
+	return base, nil
+}
+
+// formatInt formats an integer in the base of the base tag option with the conventional prefix of the base, e.g. 0xff
+// for base:16, so it is parsed back to the same value. Without the option it is formatted in decimal.
+func formatInt(v reflect.Value, tags map[string]string) string {
+	base, err := tagBase(tags)
+	if err != nil || base == 0 {
+		base = 10
+	}
+	var digits string
+	if v.CanInt() {
+		digits = strconv.FormatInt(v.Int(), base)
+	} else {
+		digits = strconv.FormatUint(v.Uint(), base)
+	}
+	unsigned := strings.TrimPrefix(digits, "-")
+	return digits[:len(digits)-len(unsigned)] + intBasePrefixes[base] + unsigned
+}
+
+// intBase returns 0 so strconv honours the 0x, 0o and 0b prefixes when the value carries one, and 10 otherwise so
+// zero padded decimals like "08" are not mistaken for octal.
+func intBase(str string) int {
+	unsigned := strings.TrimLeft(str, "+-")
+	if len(unsigned) > 2 && unsigned[0] == '0' && strings.ContainsRune("xXoObB", rune(unsigned[1])) {
+		return 0
+	}
+	return 10
+}
@@ -0,0 +1,73 @@
+package goloadenv
+
+import (
+	"reflect"
+)
+
+// Schema describes the environment variables of a config struct, see DescribeConfig.
+type Schema struct {
+	// Fields holds the tagged fields in declaration order.
+	Fields []SchemaField `json:"fields"`
+}
+
+// SchemaField describes the environment variable of a single field.
+type SchemaField struct {
+	// Path is the dotted path of the field from the root config struct, e.g. "DB.Host".
+	Path string `json:"path"`
+	// Env is the name of the environment variable, including the prefixes of envPrefix tags.
+	Env string `json:"env"`
+	// Type is the Go type of the field, e.g. "time.Duration".
+	Type string `json:"type"`
+	// Default is the default value from the tag, nil if the field has none.
+	Default *string `json:"default,omitempty"`
+	// Optional is set for fields that may be left unset.
+	Optional bool `json:"optional"`
+	// Secret is set for fields that are masked when printed.
+	Secret bool `json:"secret"`
+	// Description is the desc struct tag.
+	Description string `json:"description,omitempty"`
+	// Docs is the docs struct tag linking to the documentation.
+	Docs string `json:"docs,omitempty"`
+	// Example is the example tag option, see ExampleConfig.
+	Example string `json:"example,omitempty"`
+}
+
+// Required reports whether the variable must be set, because the field is neither optional nor has a default value.
+func (f SchemaField) Required() bool {
+	return !f.Optional && f.Default == nil
+}
+
+// DescribeConfig returns the schema of the environment variables of a config struct, for tooling such as admin UIs or
+// generators of Helm values and Terraform variable files. The config may be a struct or a pointer to a struct, its
+// values are not used.
+//
+// Example:
+//
+//	schema, err := goloadenv.DescribeConfig(Config{})
+//	for _, field := range schema.Fields {
+//	  fmt.Println(field.Env, field.Type, field.Required())
+//	}
+func DescribeConfig(config interface{}) (Schema, error) {
+	schema := Schema{Fields: []SchemaField{}}
+	err := Iterate(config, func(f FieldInfo, _ reflect.Value) error {
+		if f.Name == "" {
+			return nil
+		}
+		field := SchemaField{
+			Path:        f.Path,
+			Env:         f.Name,
+			Type:        f.Type.String(),
+			Secret:      isSecret(f.Tags, f.Name),
+			Description: f.StructField.Tag.Get(descTagName),
+			Docs:        f.StructField.Tag.Get(docsTagName),
+			Example:     f.Tags["example"],
+		}
+		if defaultValue, hasDefault := f.Tags["default"]; hasDefault {
+			field.Default = &defaultValue
+		}
+		_, field.Optional = f.Tags["optional"]
+		schema.Fields = append(schema.Fields, field)
+		return nil
+	})
+	return schema, err
+}
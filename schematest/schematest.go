@@ -0,0 +1,118 @@
+// Package schematest guards the configuration surface of an application in its tests: the schema of a config struct
+// is compared with a golden file, so adding, removing or changing an environment variable fails the test until the
+// golden file is updated and the change can be reviewed.
+package schematest
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/munisense/goloadenv"
+)
+
+var update = flag.Bool("schematest.update", false, "update the golden schema files")
+
+// Assert fails the test when the schema of the config struct, see goloadenv.DescribeConfig, differs from the golden
+// JSON file at the given path, listing the added, removed and changed variables. Running the tests with
+// -schematest.update writes the current schema to the golden file instead.
+//
+// Example:
+//
+//	func TestConfigSchema(t *testing.T) {
+//	  schematest.Assert(t, Config{}, "testdata/schema.golden.json")
+//	}
+func Assert(t testing.TB, config interface{}, golden string) {
+	t.Helper()
+	schema, err := goloadenv.DescribeConfig(config)
+	if err != nil {
+		t.Fatalf("error describing config: %v", err)
+	}
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		t.Fatalf("error encoding schema: %v", err)
+	}
+	content = append(content, '\n')
+	if *update {
+		err = os.MkdirAll(filepath.Dir(golden), 0o755)
+		if err == nil {
+			err = os.WriteFile(golden, content, 0o644)
+		}
+		if err != nil {
+			t.Fatalf("error updating golden schema: %v", err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden schema %s does not exist, run the tests with -schematest.update to create it", golden)
+	}
+	if err != nil {
+		t.Fatalf("error reading golden schema: %v", err)
+	}
+	var expectedSchema goloadenv.Schema
+	err = json.Unmarshal(expected, &expectedSchema)
+	if err != nil {
+		t.Fatalf("error decoding golden schema %s: %v", golden, err)
+	}
+	if changes := compare(expectedSchema, schema); len(changes) > 0 {
+		t.Errorf("config schema differs from %s, run the tests with -schematest.update to accept the changes:\n%s", golden, strings.Join(changes, "\n"))
+	}
+}
+
+// compare lists the variables added, removed or changed from the expected to the actual schema.
+func compare(expected goloadenv.Schema, actual goloadenv.Schema) []string {
+	previous := map[string]goloadenv.SchemaField{}
+	for _, field := range expected.Fields {
+		previous[field.Env] = field
+	}
+	var changes []string
+	for _, field := range actual.Fields {
+		old, found := previous[field.Env]
+		delete(previous, field.Env)
+		switch {
+		case !found:
+			changes = append(changes, "added "+field.Env)
+		case !reflect.DeepEqual(old, field):
+			changes = append(changes, fmt.Sprintf("changed %s: %s", field.Env, describe(old, field)))
+		}
+	}
+	for _, field := range expected.Fields {
+		if _, removed := previous[field.Env]; removed {
+			changes = append(changes, "removed "+field.Env)
+		}
+	}
+	return changes
+}
+
+// describe lists the attributes that differ between two versions of a field.
+func describe(old goloadenv.SchemaField, new goloadenv.SchemaField) string {
+	var diffs []string
+	oldValue := reflect.ValueOf(old)
+	newValue := reflect.ValueOf(new)
+	for i := 0; i < oldValue.NumField(); i++ {
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		diffs = append(diffs, fmt.Sprintf("%s %s -> %s", oldValue.Type().Field(i).Name, format(oldValue.Field(i)), format(newValue.Field(i))))
+	}
+	return strings.Join(diffs, ", ")
+}
+
+// format formats an attribute of a field, dereferencing the default value.
+func format(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<none>"
+		}
+		v = v.Elem()
+	}
+	return fmt.Sprintf("%q", fmt.Sprint(v.Interface()))
+}
@@ -0,0 +1,52 @@
+package schematest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/munisense/goloadenv"
+)
+
+type config struct {
+	Host    string `env:"HOST"`
+	Port    int    `env:"PORT;default:8080"`
+	Timeout string `env:"TIMEOUT;optional"`
+}
+
+func TestAssert(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "testdata", "schema.golden.json")
+	*update = true
+	Assert(t, config{}, golden)
+	*update = false
+	if _, err := os.Stat(golden); err != nil {
+		t.Fatalf("Expected the golden schema to be written, got %v", err)
+	}
+	Assert(t, &config{}, golden)
+}
+
+func TestCompare(t *testing.T) {
+	expected, err := goloadenv.DescribeConfig(config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	actual, err := goloadenv.DescribeConfig(struct {
+		Host  string `env:"HOST" desc:"Hostname"`
+		Port  int    `env:"PORT;default:9090"`
+		Debug bool   `env:"DEBUG;optional"`
+	}{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	changes := compare(expected, actual)
+	want := []string{
+		`changed HOST: Description "" -> "Hostname"`,
+		`changed PORT: Default "8080" -> "9090"`,
+		"added DEBUG",
+		"removed TIMEOUT",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected %v, got %v", want, changes)
+	}
+}
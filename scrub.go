@@ -0,0 +1,58 @@
+package goloadenv
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ScrubEnv unsets the environment variables of the fields with the secret flag from the process environment, as a
+// defense in depth measure once the config is loaded, so child processes and inspection of /proc cannot see them. The
+// old names of a field migrating with the shadow or alias option and the *_FILE variable of a field with the file flag
+// are unset as well. The options should match the options the config was loaded with, so e.g. WithPrefix, WithTagName
+// and WithDerivedNames apply to the names, and the redaction policy of WithRedactionPolicy marks secret fields.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnv(&cfg)
+//	if err != nil {
+//	  return err
+//	}
+//	err = goloadenv.ScrubEnv(&cfg)
+func ScrubEnv(config interface{}, opts ...Option) error {
+	l := newLoader(opts...)
+	var names []string
+	err := l.iterate(config, func(f FieldInfo, _ reflect.Value) error {
+		if !hasSecretFlag(f.Tags) || f.Name == "" {
+			return nil
+		}
+		tags, err := parseTags(f.StructField, l.tagName)
+		if err != nil {
+			return err
+		}
+		if l.derivedNames && (tags["name"] == "" || tags["name"] == "-") {
+			tags["name"] = derivedName(f.StructField, tags)
+		}
+		prefix := strings.TrimSuffix(f.Name, tags["name"])
+		names = append(names, f.Name)
+		if shadow, hasShadow := f.Tags["shadow"]; hasShadow {
+			names = append(names, prefix+shadow)
+		}
+		names = append(names, aliases(f.Tags, prefix)...)
+		names = append(names, fallbacks(f.Tags, prefix)...)
+		if _, hasFile := f.Tags["file"]; hasFile || l.fileFallback {
+			names = append(names, f.Name+fileSuffix)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		err = os.Unsetenv(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package goloadenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestScrubEnv(t *testing.T) {
+	clearTestEnv()
+
+	for key, value := range map[string]string{
+		"APP_HOST":             "localhost",
+		"APP_DB_PASSWORD":      "hunter2",
+		"APP_DB_OLD_PASSWORD":  "hunter2",
+		"APP_TOKEN_FILE":       "/run/secrets/token",
+		"APP_UNRELATED_SECRET": "kept",
+	} {
+		err := os.Setenv(key, value)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	type DBConfig struct {
+		Password string `env:"PASSWORD;secret;shadow:OLD_PASSWORD"`
+	}
+	cfg := struct {
+		Host  string   `env:"HOST"`
+		DB    DBConfig `envPrefix:"DB_"`
+		Token string   `env:"TOKEN;secret;file;optional"`
+	}{}
+	err := ScrubEnv(&cfg, WithPrefix("APP_"))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	for _, key := range []string{"APP_DB_PASSWORD", "APP_DB_OLD_PASSWORD", "APP_TOKEN_FILE"} {
+		if _, found := os.LookupEnv(key); found {
+			t.Errorf("Expected %s to be unset", key)
+		}
+	}
+	for _, key := range []string{"APP_HOST", "APP_UNRELATED_SECRET"} {
+		if _, found := os.LookupEnv(key); !found {
+			t.Errorf("Expected %s to be kept", key)
+		}
+	}
+
+	t.Setenv("CFG_API_KEY", "hunter2")
+	t.Setenv("CFG_SIGNING_TOKEN", "hunter2")
+	tagged := struct {
+		APIKey       string `cfg:"API_KEY;secret"`
+		SigningToken string
+	}{}
+	policy := &RedactionPolicy{Pattern: DefaultRedactionPattern}
+	err = ScrubEnv(&tagged, WithTagName("cfg"), WithDerivedNames(), WithPrefix("CFG_"), WithRedactionPolicy(policy))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	for _, key := range []string{"CFG_API_KEY", "CFG_SIGNING_TOKEN"} {
+		if _, found := os.LookupEnv(key); found {
+			t.Errorf("Expected %s to be unset", key)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package goloadenv
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+)
+
+// secretSealer seals Secret values with random keys generated once per process, never leaving memory.
+type secretSealer struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// sealKey returns the sealer of Secret values.
+var sealKey = sync.OnceValues(func() (secretSealer, error) {
+	key := make([]byte, 64)
+	_, err := rand.Read(key)
+	if err != nil {
+		return secretSealer{}, err
+	}
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return secretSealer{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return secretSealer{}, err
+	}
+	return secretSealer{aead: aead, macKey: key[32:]}, nil
+})
+
+// Secret holds a secret string encrypted in memory with a per-process key, and only decrypts it on calls to Expose. It
+// can be used as the type of a field, e.g. `env:"DB_PASSWORD;secret"`, and prints masked. The nonce is derived from a
+// keyed hash of the secret, so equal secrets are encrypted equally and a reload with the same secret is not reported
+// as a change by Diff and Watcher. The zero value holds no secret.
+//
+// Example:
+//
+//	type Config struct {
+//	  Password goloadenv.Secret `env:"DB_PASSWORD;secret"`
+//	}
+//
+//	db, err := sql.Open("postgres", "password="+cfg.Password.Expose())
+type Secret struct {
+	nonce  []byte
+	sealed []byte
+}
+
+// NewSecret encrypts the given value into a Secret.
+func NewSecret(value string) (Secret, error) {
+	sealer, err := sealKey()
+	if err != nil {
+		return Secret{}, err
+	}
+	mac := hmac.New(sha256.New, sealer.macKey)
+	mac.Write([]byte(value))
+	nonce := mac.Sum(nil)[:sealer.aead.NonceSize()]
+	return Secret{nonce: nonce, sealed: sealer.aead.Seal(nil, nonce, []byte(value), nil)}, nil
+}
+
+// UnmarshalText encrypts the text into the Secret.
+func (s *Secret) UnmarshalText(text []byte) error {
+	secret, err := NewSecret(string(text))
+	if err != nil {
+		return err
+	}
+	*s = secret
+	return nil
+}
+
+// Expose decrypts and returns the secret, or an empty string for the zero value. The returned string should not be
+// kept around longer than needed.
+func (s Secret) Expose() string {
+	if s.sealed == nil {
+		return ""
+	}
+	sealer, err := sealKey()
+	if err != nil {
+		panic(err)
+	}
+	plaintext, err := sealer.aead.Open(nil, s.nonce, s.sealed, nil)
+	if err != nil {
+		panic("goloadenv: secret cannot be decrypted: " + err.Error())
+	}
+	return string(plaintext)
+}
+
+// IsZero reports whether the Secret holds no secret.
+func (s Secret) IsZero() bool {
+	return s.sealed == nil
+}
+
+// String returns the masked secret, so a Secret cannot be printed by accident.
+func (s Secret) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	return secretMask
+}
+
+// MarshalText returns the masked secret, so a Secret cannot be encoded by accident.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
@@ -0,0 +1,114 @@
+package goloadenv
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecret(t *testing.T) {
+	cfg := struct {
+		Password Secret `env:"DB_PASSWORD;secret"`
+		Token    Secret `env:"TOKEN;optional"`
+	}{}
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"DB_PASSWORD": "hunter2"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Password.Expose() != "hunter2" || !cfg.Token.IsZero() || cfg.Token.Expose() != "" {
+		t.Errorf("Expected DB_PASSWORD=hunter2 and TOKEN unset, got %s and %s", cfg.Password.Expose(), cfg.Token.Expose())
+	}
+	if strings.Contains(string(cfg.Password.sealed), "hunter2") {
+		t.Errorf("Expected the secret to be encrypted in memory")
+	}
+
+	reloaded := cfg
+	err = LoadEnvWithOptions(&reloaded, WithSources(MapSource{"DB_PASSWORD": "hunter2"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	changes, err := Diff(&cfg, &reloaded)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes for the same secret, got %v, %v", changes, err)
+	}
+	err = LoadEnvWithOptions(&reloaded, WithSources(MapSource{"DB_PASSWORD": "hunter3"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	changes, err = Diff(&cfg, &reloaded)
+	if err != nil || len(changes) != 1 || changes[0].Env != "DB_PASSWORD" {
+		t.Errorf("Expected a change of DB_PASSWORD for a rotated secret, got %v, %v", changes, err)
+	}
+
+	for _, format := range []string{"text", "json", "yaml", "dotenv"} {
+		got, err := Format(cfg, format)
+		if err != nil || strings.Contains(got, "hunter2") {
+			t.Errorf("Expected the %s output to mask the secret, got %s, %v", format, got, err)
+		}
+	}
+}
+
+func TestEncryptedValues(t *testing.T) {
+	RegisterDecryptor("reverse", func(ciphertext []byte) ([]byte, error) {
+		if len(ciphertext) == 0 {
+			return nil, errors.New("empty ciphertext")
+		}
+		plaintext := slices.Clone(ciphertext)
+		slices.Reverse(plaintext)
+		return plaintext, nil
+	})
+	type Config struct {
+		Password string `env:"DB_PASSWORD;encrypted"`
+		Token    string `env:"TOKEN;encrypted;optional"`
+	}
+
+	// "2retnuh" is MnJldG51aA in base64
+	var cfg Config
+	err := LoadEnvFromMap(&cfg, map[string]string{"DB_PASSWORD": "enc:reverse:MnJldG51aA=="})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Password != "hunter2" || cfg.Token != "" {
+		t.Errorf("Expected DB_PASSWORD=hunter2 and TOKEN unset, got %s and %s", cfg.Password, cfg.Token)
+	}
+	if printed := FormatString(&cfg); strings.Contains(printed, "hunter2") {
+		t.Errorf("Expected the decrypted value to be masked, got %s", printed)
+	}
+
+	for value, expected := range map[string]string{
+		"hunter2":                "error decrypting environment variable DB_PASSWORD: value is not encrypted, expected enc:NAME:CIPHERTEXT",
+		"enc:MnJldG51aA":         "error decrypting environment variable DB_PASSWORD: missing decryptor name, expected enc:NAME:CIPHERTEXT",
+		"enc:age:MnJldG51aA":     "error decrypting environment variable DB_PASSWORD: no decryptor registered as 'age'",
+		"enc:reverse:!":          "error decrypting environment variable DB_PASSWORD: invalid base64: illegal base64 data at input byte 0",
+		"enc:reverse:MnJldG51aA": "error decrypting environment variable DB_PASSWORD: invalid base64: illegal base64 data at input byte 8",
+		"enc:reverse:":           "error decrypting environment variable DB_PASSWORD: empty ciphertext",
+	} {
+		err = LoadEnvFromMap(&cfg, map[string]string{"DB_PASSWORD": value})
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s for %s, got %v", expected, value, err)
+		}
+	}
+}
+
+func TestSecretValuesMaskedInErrors(t *testing.T) {
+	type Config struct {
+		PIN     int           `env:"PIN;secret"`
+		Code    string        `env:"CODE;secret;regex:^[0-9]+$"`
+		Timeout time.Duration `env:"API_TOKEN_TTL;secret"`
+	}
+	err := LoadEnvFromMap(&Config{}, map[string]string{"PIN": "s3cr3t-pin", "CODE": "s3cr3t", "API_TOKEN_TTL": "s3cr3t-ttl"}, WithAllErrors())
+	expected := "error parsing '****' as environment variable PIN: invalid syntax for int\n" +
+		"invalid value '****' for environment variable CODE: must match ^[0-9]+$\n" +
+		"error parsing '****' as environment variable API_TOKEN_TTL: time: invalid duration \"****\""
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	_, err = LoadEnvDynamic([]FieldSpec{{Name: "PIN", Type: reflect.TypeFor[int](), Secret: true}}, WithSources(MapSource{"PIN": "s3cr3t-pin"}))
+	if err == nil || strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("Expected an error without the secret value, got %v", err)
+	}
+}
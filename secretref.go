@@ -0,0 +1,209 @@
+package goloadenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretResolver resolves references to secrets in an external store, such as HashiCorp Vault or AWS Secrets Manager.
+type SecretResolver interface {
+	// ResolveSecret returns the value of the secret the reference points to. The reference is the part of the
+	// secretref tag option after the scheme, e.g. secret/data/db#password for vault://secret/data/db#password.
+	ResolveSecret(ref string) (string, error)
+}
+
+// ContextSecretResolver is a SecretResolver that can be interrupted, the context of LoadEnvContext is passed to it
+// instead of calling ResolveSecret.
+type ContextSecretResolver interface {
+	SecretResolver
+	// ResolveSecretContext resolves the reference like ResolveSecret, returning early when the context is done.
+	ResolveSecretContext(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc is an adapter to allow the use of ordinary functions as a SecretResolver.
+type SecretResolverFunc func(ref string) (string, error)
+
+// ResolveSecret calls f(ref).
+func (f SecretResolverFunc) ResolveSecret(ref string) (string, error) {
+	return f(ref)
+}
+
+// SecretRefError is returned when the secret reference of a field cannot be resolved.
+type SecretRefError struct {
+	// Env is the name of the environment variable of the field.
+	Env string
+	// Ref is the secret reference.
+	Ref string
+	// Err is the error of the resolver.
+	Err error
+}
+
+func (e *SecretRefError) Error() string {
+	return fmt.Sprintf("error resolving secret %s for environment variable %s: %s", e.Ref, e.Env, e.Err)
+}
+
+func (e *SecretRefError) Unwrap() error {
+	return e.Err
+}
+
+// secretLookup returns a lookup that resolves the secret reference of a field when its variable is not found by the
+// given lookup, reporting whether the secret was used. The secret is resolved eagerly so errors surface here.
+// used internally by LoadEnv.
+func (l *loader) secretLookup(lookup func(string) (string, bool), tags map[string]string, ref string) (func(string) (string, bool), bool, error) {
+	name := tags["name"]
+	if _, found := lookup(name); found {
+		return lookup, false, nil
+	}
+	scheme, path, found := strings.Cut(ref, "://")
+	if !found {
+		return nil, false, &SecretRefError{Env: name, Ref: ref, Err: fmt.Errorf("expected scheme://reference")}
+	}
+	resolver, found := l.resolvers[scheme]
+	if !found {
+		return nil, false, &SecretRefError{Env: name, Ref: ref, Err: fmt.Errorf("no secret resolver registered for scheme %s", scheme)}
+	}
+	if l.pastSoftDeadline() {
+		return l.leaveUnresolved(lookup, tags, ref, errSoftDeadline)
+	}
+	secret, err := l.resolveSecret(scheme, resolver, path)
+	if err != nil && l.pastSoftDeadline() {
+		return l.leaveUnresolved(lookup, tags, ref, err)
+	}
+	if bound, hasTimeout := l.resolverTimeouts[scheme]; hasTimeout {
+		if err == nil {
+			bound.lastSecrets.Store(ref, secret)
+		} else if errors.Is(err, context.DeadlineExceeded) && l.ctx.Err() == nil {
+			// the resolver is slow, degrade to the last resolved secret or to the default value of the field
+			timeoutErr := &ResolverTimeoutError{Env: name, Ref: ref, Timeout: bound.timeout}
+			last, found := bound.lastSecrets.Load(ref)
+			_, hasDefault := tags["default"]
+			_, isOptional := tags["optional"]
+			if !found && !hasDefault && !isOptional {
+				return nil, false, timeoutErr
+			}
+			l.warn(timeoutErr)
+			if !found {
+				return lookup, false, nil
+			}
+			secret, err = last.(string), nil
+		}
+	}
+	if err != nil {
+		return nil, false, &SecretRefError{Env: name, Ref: ref, Err: err}
+	}
+	return func(key string) (string, bool) {
+		if key == name {
+			return secret, true
+		}
+		return lookup(key)
+	}, true, nil
+}
+
+// ResolverTimeoutError is the warning raised when a secret resolver with a timeout set by WithResolverTimeout does not
+// resolve a secret in time.
+type ResolverTimeoutError struct {
+	// Env is the name of the environment variable of the field.
+	Env string
+	// Ref is the secret reference.
+	Ref string
+	// Timeout is the timeout of the resolver.
+	Timeout time.Duration
+}
+
+func (e *ResolverTimeoutError) Error() string {
+	return fmt.Sprintf("resolving secret %s for environment variable %s timed out after %s", e.Ref, e.Env, e.Timeout)
+}
+
+// resolverTimeout bounds a secret resolver by a timeout, and holds the last secret it resolved for every reference, to
+// fall back to when the resolver times out on a later load with the same option.
+type resolverTimeout struct {
+	timeout     time.Duration
+	lastSecrets sync.Map
+}
+
+// resolveSecret resolves a reference with the resolver registered for its scheme, bounded by the context of the load
+// and the timeout of the resolver, if any. A resolver that does not implement ContextSecretResolver is abandoned rather
+// than interrupted when the context is done.
+func (l *loader) resolveSecret(scheme string, resolver SecretResolver, ref string) (secret string, err error) {
+	ctx, end := l.traceSource("secretref:" + scheme)
+	defer func() {
+		end(err)
+	}()
+	if bound, hasTimeout := l.resolverTimeouts[scheme]; hasTimeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, bound.timeout)
+		defer cancel()
+	}
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline && l.softDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-l.softDeadline))
+		defer cancel()
+	}
+	if contextResolver, isContext := resolver.(ContextSecretResolver); isContext {
+		return contextResolver.ResolveSecretContext(ctx, ref)
+	}
+	if ctx.Done() == nil {
+		return resolver.ResolveSecret(ref)
+	}
+	type result struct {
+		secret string
+		err    error
+	}
+	resolved := make(chan result, 1)
+	go func() {
+		secret, err := resolver.ResolveSecret(ref)
+		resolved <- result{secret, err}
+	}()
+	select {
+	case r := <-resolved:
+		return r.secret, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// errSoftDeadline is the reason of the secret references left unresolved as the soft deadline had been reached.
+var errSoftDeadline = errors.New("soft deadline reached")
+
+// PartialLoadError is returned by a load with WithSoftDeadline that left secret references unresolved as the deadline
+// of its context came near. Every other field is loaded, the unresolved fields hold their default value, if any.
+type PartialLoadError struct {
+	// Unresolved are the fields whose secret references were not resolved, in load order.
+	Unresolved []UnresolvedField
+}
+
+func (e *PartialLoadError) Error() string {
+	names := make([]string, len(e.Unresolved))
+	for i, field := range e.Unresolved {
+		names[i] = field.Env
+	}
+	return fmt.Sprintf("config loaded partially, secret references not resolved before the soft deadline: %s", strings.Join(names, ", "))
+}
+
+// UnresolvedField is a field whose secret reference was left unresolved by the soft deadline.
+type UnresolvedField struct {
+	// Env is the name of the environment variable of the field.
+	Env string
+	// Ref is the secret reference.
+	Ref string
+	// Err is the reason the reference was not resolved, the error of the abandoned resolution, if any.
+	Err error
+}
+
+// pastSoftDeadline reports whether less than the margin of WithSoftDeadline is left before the deadline of the load.
+func (l *loader) pastSoftDeadline() bool {
+	deadline, hasDeadline := l.ctx.Deadline()
+	return l.softDeadline > 0 && hasDeadline && time.Until(deadline) < l.softDeadline
+}
+
+// leaveUnresolved records a secret reference left unresolved by the soft deadline and makes its field optional, so it
+// falls back to its default value, or is left untouched, instead of failing the load.
+func (l *loader) leaveUnresolved(lookup func(string) (string, bool), tags map[string]string, ref string, err error) (func(string) (string, bool), bool, error) {
+	l.unresolved = append(l.unresolved, UnresolvedField{Env: tags["name"], Ref: ref, Err: err})
+	tags["optional"] = ""
+	return lookup, false, nil
+}
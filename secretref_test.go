@@ -0,0 +1,178 @@
+package goloadenv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSecretRef(t *testing.T) {
+	clearTestEnv()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/db" || r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+
+	err := os.Setenv("API_KEY", "from-env")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	someStruct := struct {
+		Password string `env:"DB_PASSWORD;secret;secretref:vault://secret/data/db#password"`
+		APIKey   string `env:"API_KEY;secretref:aws://prod/api#key"`
+		Token    string `env:"TOKEN;secretref:aws://prod/token"`
+	}{}
+	aws := SecretResolverFunc(func(ref string) (string, error) {
+		return "aws:" + ref, nil
+	})
+	report, err := LoadEnvReport(&someStruct, WithSecretResolver("vault", &VaultResolver{Address: server.URL, Token: "token"}), WithSecretResolver("aws", aws))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Password != "hunter2" || someStruct.APIKey != "from-env" || someStruct.Token != "aws:prod/token" {
+		t.Errorf("Expected DB_PASSWORD=hunter2, API_KEY=from-env and TOKEN=aws:prod/token, got %v", someStruct)
+	}
+	if report.Fields[0].Origin != OriginSecretRef || report.Fields[1].Origin != OriginEnv {
+		t.Errorf("Expected origins secretref and env, got %s and %s", report.Fields[0].Origin, report.Fields[1].Origin)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSecretResolver("vault", &VaultResolver{Address: server.URL, Token: "wrong"}))
+	expected := "error resolving secret vault://secret/data/db#password for environment variable DB_PASSWORD: vault returned status 403 for secret/data/db"
+	var refErr *SecretRefError
+	if !errors.As(err, &refErr) || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestLoadEnvContext(t *testing.T) {
+	clearTestEnv()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	someStruct := struct {
+		Password string `env:"DB_PASSWORD;secret;secretref:vault://secret/data/db#password"`
+	}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := LoadEnvContext(ctx, &someStruct, WithSecretResolver("vault", &VaultResolver{Address: server.URL, Token: "token"}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = LoadEnvContext(ctx, &someStruct, WithSources(MapSource{"DB_PASSWORD": "hunter2"}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestWithSoftDeadline(t *testing.T) {
+	clearTestEnv()
+
+	release := make(chan struct{})
+	defer close(release)
+	slow := SecretResolverFunc(func(string) (string, error) {
+		<-release
+		return "hunter2", nil
+	})
+	someStruct := struct {
+		Host     string `env:"HOST"`
+		Password string `env:"DB_PASSWORD;secretref:vault://db#password;default:fallback"`
+		Token    string `env:"API_TOKEN;secretref:vault://api#token"`
+	}{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := LoadEnvContext(ctx, &someStruct, WithSources(MapSource{"HOST": "db"}), WithSecretResolver("vault", slow), WithSoftDeadline(900*time.Millisecond))
+	expected := "config loaded partially, secret references not resolved before the soft deadline: DB_PASSWORD, API_TOKEN"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+	var partialErr *PartialLoadError
+	if !errors.As(err, &partialErr) || len(partialErr.Unresolved) != 2 || !errors.Is(partialErr.Unresolved[0].Err, context.DeadlineExceeded) ||
+		partialErr.Unresolved[1].Ref != "vault://api#token" {
+		t.Errorf("Expected the abandoned and the skipped reference, got %v", err)
+	}
+	if someStruct.Host != "db" || someStruct.Password != "fallback" || someStruct.Token != "" {
+		t.Errorf("Expected the partial config, got %+v", someStruct)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("Expected the load to return before the deadline, got %v", ctx.Err())
+	}
+}
+
+func TestWithResolverTimeout(t *testing.T) {
+	clearTestEnv()
+
+	slow := false
+	resolver := SecretResolverFunc(func(ref string) (string, error) {
+		if slow {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return "secret:" + ref, nil
+	})
+	someStruct := struct {
+		Token  string `env:"TOKEN;secret;secretref:slow://token"`
+		Region string `env:"REGION;secretref:slow://region;default:eu-west-1"`
+	}{}
+	opts := []Option{WithSecretResolver("slow", resolver), WithResolverTimeout("slow", 10*time.Millisecond)}
+	report, err := LoadEnvReport(&someStruct, opts...)
+	if err != nil || len(report.Warnings) != 0 {
+		t.Errorf("Expected no error or warnings, got %v, %v", err, report.Warnings)
+	}
+
+	slow = true
+	someStruct.Token, someStruct.Region = "", ""
+	report, err = LoadEnvReport(&someStruct, opts...)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Token != "secret:token" || someStruct.Region != "secret:region" {
+		t.Errorf("Expected the last resolved secrets, got %v", someStruct)
+	}
+	expected := "resolving secret slow://token for environment variable TOKEN timed out after 10ms"
+	var timeoutErr *ResolverTimeoutError
+	if len(report.Warnings) != 2 || !errors.As(report.Warnings[0], &timeoutErr) || report.Warnings[0].Error() != expected {
+		t.Errorf("Expected warning %s, got %v", expected, report.Warnings)
+	}
+
+	fresh := struct {
+		Region string `env:"REGION;secretref:slow://fresh;default:eu-west-1"`
+	}{}
+	err = LoadEnvWithOptions(&fresh, opts...)
+	if err != nil || fresh.Region != "eu-west-1" {
+		t.Errorf("Expected REGION=eu-west-1, got %s, %v", fresh.Region, err)
+	}
+
+	required := struct {
+		Token string `env:"TOKEN;secretref:slow://fresh-token"`
+	}{}
+	err = LoadEnvWithOptions(&required, opts...)
+	expected = "resolving secret slow://fresh-token for environment variable TOKEN timed out after 10ms"
+	if !errors.As(err, &timeoutErr) || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSecretResolver("slow", resolver), WithResolverTimeout("slow", 10*time.Millisecond))
+	if err == nil {
+		t.Errorf("Expected the last secrets of other options not to be used, got %v", someStruct)
+	}
+}
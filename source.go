@@ -0,0 +1,133 @@
+package goloadenv
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvSource is a source of environment variables, such as the process environment, a map, a .env file or a remote
+// configuration store.
+type EnvSource interface {
+	// Lookup returns the value of the variable and whether it is set.
+	Lookup(key string) (string, bool)
+}
+
+// EnvSourceFunc is an adapter to allow the use of ordinary functions as an EnvSource.
+type EnvSourceFunc func(key string) (string, bool)
+
+// Lookup calls f(key).
+func (f EnvSourceFunc) Lookup(key string) (string, bool) {
+	return f(key)
+}
+
+// ProcessEnv is the EnvSource of the process environment.
+var ProcessEnv EnvSource = processEnvSource{}
+
+// processEnvSource is the EnvSource of the process environment, a distinct type so WithSources can tell whether the
+// process environment is among its sources.
+type processEnvSource struct{}
+
+func (processEnvSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// MapSource is an EnvSource backed by a map, e.g. for tests that should not mutate the process environment.
+type MapSource map[string]string
+
+// Lookup returns the value of the key in the map.
+func (m MapSource) Lookup(key string) (string, bool) {
+	value, found := m[key]
+	return value, found
+}
+
+// DotEnvSource reads the given .env files, or ".env" when no paths are given, into an EnvSource. For variables set in
+// several files the first file wins, see LoadDotEnv for the file format.
+func DotEnvSource(paths ...string) (EnvSource, error) {
+	env, err := readDotEnvFiles(paths, false, false)
+	if err != nil {
+		return nil, err
+	}
+	return MapSource(env), nil
+}
+
+// KeyMapper maps the name of a variable to the key it is stored under in a source, e.g. DB_PASSWORD to
+// /myapp/db_password.
+type KeyMapper func(name string) string
+
+// KeyTemplate returns a KeyMapper filling in the name of a variable in a template, with {name} replaced by the name as
+// is and {lower} by the name in lower case, e.g. KeyTemplate("/myapp/{lower}") maps DB_PASSWORD to /myapp/db_password
+// and KeyTemplate("secret/myapp#{lower}") maps it to secret/myapp#db_password.
+func KeyTemplate(template string) KeyMapper {
+	return func(name string) string {
+		return strings.NewReplacer("{name}", name, "{lower}", strings.ToLower(name)).Replace(template)
+	}
+}
+
+// NamespacedSource returns an EnvSource looking variables up in the given source under the keys the mapper maps their
+// names to, so the same struct tag can be read from stores that name their keys differently without tags per field.
+// A KVSource wrapped in a NamespacedSource is still refreshed before loading.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithSecretSources(
+//	  goloadenv.NamespacedSource(ssm, goloadenv.KeyTemplate("/myapp/{lower}")),
+//	  goloadenv.NamespacedSource(vault, goloadenv.KeyTemplate("secret/myapp#{lower}")),
+//	))
+func NamespacedSource(source EnvSource, mapper KeyMapper) EnvSource {
+	return namespacedSource{source: source, mapKey: mapper}
+}
+
+// namespacedSource is the EnvSource returned by NamespacedSource.
+type namespacedSource struct {
+	source EnvSource
+	mapKey KeyMapper
+}
+
+func (s namespacedSource) Lookup(key string) (string, bool) {
+	return s.source.Lookup(s.mapKey(key))
+}
+
+// layeredSource looks a variable up in its sources in order, the first source that has it wins.
+type layeredSource []EnvSource
+
+func (s layeredSource) Lookup(key string) (string, bool) {
+	for _, source := range s {
+		if value, found := source.Lookup(key); found {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// secretSourceLookup returns a lookup that reads the variable of a secret field from the secret sources when it is not
+// found by the given lookup, reporting whether the secret sources were used. The secret is read eagerly so each source
+// is consulted at most once per field.
+// used internally by LoadEnv.
+func secretSourceLookup(lookup func(string) (string, bool), secretSources EnvSource, name string) (func(string) (string, bool), bool) {
+	if _, found := lookup(name); found {
+		return lookup, false
+	}
+	secret, found := secretSources.Lookup(name)
+	if !found {
+		return lookup, false
+	}
+	return func(key string) (string, bool) {
+		if key == name {
+			return secret, true
+		}
+		return lookup(key)
+	}, true
+}
+
+// layeredLookup returns a lookup that looks a variable up with every prefix, the last prefix that has it wins.
+// used internally by LoadEnv.
+func layeredLookup(lookup func(string) (string, bool), prefixes []string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		for i := len(prefixes) - 1; i >= 0; i-- {
+			if value, found := lookup(prefixes[i] + key); found {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
@@ -0,0 +1,111 @@
+package goloadenv
+
+import (
+	"errors"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var urlType = reflect.TypeFor[url.URL]()
+
+// parseURL parses an absolute URL, rejecting values without a scheme such as example.com/api, which url.Parse would
+// accept as a relative path.
+func parseURL(str string) (url.URL, error) {
+	parsed, err := url.Parse(str)
+	if err != nil {
+		return url.URL{}, err
+	}
+	if parsed.Scheme == "" {
+		return url.URL{}, errors.New("missing scheme in URL")
+	}
+	return *parsed, nil
+}
+
+// parseIPNet parses a network in CIDR notation, e.g. 10.0.0.0/8, keeping the address as given so 10.0.0.5/24 keeps
+// the host address.
+func parseIPNet(str string) (net.IPNet, error) {
+	ip, network, err := net.ParseCIDR(str)
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	return net.IPNet{IP: ip, Mask: network.Mask}, nil
+}
+
+// parseMailAddress parses an RFC 5322 address, e.g. ops@example.com or Ops <ops@example.com>, with the domain of the
+// address canonicalized, see canonicalAddress.
+func parseMailAddress(str string) (mail.Address, error) {
+	address, err := mail.ParseAddress(str)
+	if err != nil {
+		return mail.Address{}, err
+	}
+	address.Address = canonicalAddress(address.Address)
+	return *address, nil
+}
+
+// canonicalAddress lowercases the domain of an email address, which is case-insensitive, keeping the local part as is
+// since mail servers may treat it case-sensitively.
+func canonicalAddress(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return address
+	}
+	return address[:at+1] + strings.ToLower(address[at+1:])
+}
+
+// parseTCPAddr parses a host:port address, resolving a host name to one of its IP addresses.
+func parseTCPAddr(str string) (net.TCPAddr, error) {
+	address, err := net.ResolveTCPAddr("tcp", str)
+	if err != nil {
+		return net.TCPAddr{}, err
+	}
+	return *address, nil
+}
+
+// redactedURL formats a url.URL, or a pointer to one, with its password masked, reporting whether v holds a URL.
+func redactedURL(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == urlType && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Type() != urlType || !v.CanInterface() {
+		return "", false
+	}
+	value := v.Interface().(url.URL)
+	return value.Redacted(), true
+}
+
+var (
+	locationType = reflect.TypeFor[*time.Location]()
+	regexpType   = reflect.TypeFor[*regexp.Regexp]()
+	templateType = reflect.TypeFor[*template.Template]()
+)
+
+// parseTemplate parses a text/template, so syntax errors are reported at load time instead of on first execution.
+func parseTemplate(str string) (*template.Template, error) {
+	return template.New("").Parse(str)
+}
+
+// sourceValue returns the value a *time.Location, *regexp.Regexp or *template.Template was parsed from, which is
+// printed instead of their internal representation, reporting whether v holds one of them.
+func sourceValue(v reflect.Value) (string, bool) {
+	if !v.CanInterface() || v.Kind() != reflect.Ptr || v.IsNil() {
+		return "", false
+	}
+	switch value := v.Interface().(type) {
+	case *time.Location:
+		return value.String(), true
+	case *regexp.Regexp:
+		return value.String(), true
+	case *template.Template:
+		if value.Tree == nil {
+			return "", true
+		}
+		return value.Tree.Root.String(), true
+	}
+	return "", false
+}
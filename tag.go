@@ -0,0 +1,112 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// isKeyValueTag reports whether a tag uses the key=value syntax, e.g. name=DB_URL,default='postgres://db',optional,
+// instead of the semicolon syntax. Its first option is a key=value pair, while the first option of the semicolon syntax
+// is a variable name, which cannot contain an equals sign.
+func isKeyValueTag(tag string) bool {
+	first := tag
+	if end := strings.IndexAny(tag, ",;"); end >= 0 {
+		first = tag[:end]
+	}
+	return strings.Contains(first, "=")
+}
+
+// splitKeyValueTag splits a tag in the key=value syntax into the options of the semicolon syntax: the variable name
+// given by the name key first, followed by the other options as key:value, or key for flags. Options are separated by
+// commas and surrounding whitespace is ignored. A value containing a comma, or leading or trailing whitespace, is
+// single quoted, e.g. oneof='debug,info', in which \' and \\ escape a quote or backslash.
+func splitKeyValueTag(tag string) ([]string, error) {
+	name := ""
+	hasName := false
+	var options []string
+	for i := 0; i < len(tag); {
+		end := i
+		for end < len(tag) && tag[end] != ',' && tag[end] != '=' {
+			end++
+		}
+		key := strings.TrimSpace(tag[i:end])
+		if key == "" && end < len(tag) && tag[end] == '=' {
+			return nil, fmt.Errorf("missing name for tag option at offset %d", i)
+		}
+		if !isTagKey(key) {
+			return nil, fmt.Errorf("invalid tag option name '%s'", key)
+		}
+		i = end
+		value, hasValue := "", false
+		if i < len(tag) && tag[i] == '=' {
+			var err error
+			value, i, err = lexTagValue(tag, i+1)
+			if err != nil {
+				return nil, err
+			}
+			hasValue = true
+		}
+		// skip the separating comma
+		i++
+		switch {
+		case key == "":
+		case key == "name":
+			if hasName {
+				return nil, errors.New("duplicate tag: name")
+			}
+			name, hasName = value, true
+		case hasValue:
+			options = append(options, key+":"+value)
+		default:
+			options = append(options, key)
+		}
+	}
+	return append([]string{name}, options...), nil
+}
+
+// lexTagValue reads the value of an option in the key=value syntax starting at offset i, and returns it together with
+// the offset of the comma ending the option, or the end of the tag.
+func lexTagValue(tag string, i int) (string, int, error) {
+	for i < len(tag) && tag[i] == ' ' {
+		i++
+	}
+	if i >= len(tag) || tag[i] != '\'' {
+		end := i
+		for end < len(tag) && tag[end] != ',' {
+			end++
+		}
+		return strings.TrimSpace(tag[i:end]), end, nil
+	}
+	var value strings.Builder
+	for i++; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && (tag[i+1] == '\'' || tag[i+1] == '\\'):
+			value.WriteByte(tag[i+1])
+			i++
+		case tag[i] == '\'':
+			end := i + 1
+			for end < len(tag) && tag[end] == ' ' {
+				end++
+			}
+			if end < len(tag) && tag[end] != ',' {
+				return "", 0, fmt.Errorf("unexpected '%c' after quoted value in tag", tag[end])
+			}
+			return value.String(), end, nil
+		default:
+			value.WriteByte(tag[i])
+		}
+	}
+	return "", 0, errors.New("unterminated quoted value in tag")
+}
+
+// isTagKey reports whether a string is a valid option name of the key=value syntax, letters, digits and underscores,
+// or empty for an empty option.
+func isTagKey(key string) bool {
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package goloadenv
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hook receives the events of loads, to integrate them with a monitoring or eventing system, e.g. counting loads or
+// exporting the origin of every field as a metric. Hooks are registered with WithHook and called synchronously from
+// the goroutine of the load, so they should return quickly. Secret values are masked in the events.
+type Hook interface {
+	// OnFieldResolved is called after every field is loaded, successfully or not.
+	OnFieldResolved(field FieldReport)
+	// OnLoadComplete is called after a successful load with its report.
+	OnLoadComplete(report *Report)
+	// OnReload is called after every successful reload of a Watcher with the changed fields, which may be none.
+	OnReload(changes Changes)
+	// OnError is called with the error of a failed load or reload.
+	OnError(err error)
+}
+
+// NopHook implements Hook with methods that do nothing, to be embedded by hooks that only handle some events.
+//
+// Example:
+//
+//	type loadCounter struct {
+//	  goloadenv.NopHook
+//	  loads, failures atomic.Int64
+//	}
+//
+//	func (c *loadCounter) OnLoadComplete(*goloadenv.Report) { c.loads.Add(1) }
+//	func (c *loadCounter) OnError(error)                    { c.failures.Add(1) }
+type NopHook struct{}
+
+func (NopHook) OnFieldResolved(FieldReport) {}
+
+func (NopHook) OnLoadComplete(*Report) {}
+
+func (NopHook) OnReload(Changes) {}
+
+func (NopHook) OnError(error) {}
+
+// notifyLoad calls the hooks of the loader with the outcome of a load.
+func (l *loader) notifyLoad(err error) {
+	for _, hook := range l.hooks {
+		if err != nil {
+			hook.OnError(err)
+		} else {
+			hook.OnLoadComplete(l.report)
+		}
+	}
+}
+
+// Tracer starts the spans of loads and of their calls to remote sources, to be adapted to a tracing system such as
+// OpenTelemetry. It is registered with WithTracer.
+type Tracer interface {
+	// Start starts a span with the given name as a child of the span in the context, if any, and returns a context
+	// holding the new span. Loads are traced as goloadenv.load and calls to remote sources as goloadenv.source.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute of the span, such as the source of a goloadenv.source span or the number of
+	// defaults applied by a goloadenv.load span.
+	SetAttribute(key string, value any)
+	// End ends the span with the error of the traced operation, nil if it succeeded.
+	End(err error)
+}
+
+// Metrics records the measurements of loads, to be adapted to a metrics system such as OpenTelemetry or Prometheus. It
+// is registered with WithMetrics and called synchronously from the goroutine of the load, so it should return quickly.
+type Metrics interface {
+	// RecordLoad is called after every load, successful or not, with its statistics.
+	RecordLoad(stats LoadStats)
+	// RecordSource is called after every call to a remote source with its latency and error, nil if it succeeded.
+	// Key/value sources are named after the type of their fetcher, e.g. ConsulKV, and secret resolvers after their
+	// scheme, e.g. secretref:vault.
+	RecordSource(source string, latency time.Duration, err error)
+}
+
+// LoadStats are the statistics of a load passed to Metrics.
+type LoadStats struct {
+	// Duration is the time the load took, including the calls to remote sources and the hooks.
+	Duration time.Duration
+	// Fields is the number of fields loaded, successfully or not.
+	Fields int
+	// Defaults is the number of fields set to their default value.
+	Defaults int
+	// ValidationFailures is the number of validation tag options and Validate methods that rejected the config.
+	ValidationFailures int
+	// Err is the error of the load, nil if it succeeded.
+	Err error
+}
+
+// traceSource starts tracing a call to a remote source, returning the context for the call and a function ending the
+// trace with the error of the call.
+func (l *loader) traceSource(source string) (context.Context, func(error)) {
+	if l.tracer == nil && l.metrics == nil {
+		return l.ctx, func(error) {}
+	}
+	ctx := l.ctx
+	var span Span
+	if l.tracer != nil {
+		ctx, span = l.tracer.Start(ctx, "goloadenv.source")
+		span.SetAttribute("goloadenv.source", source)
+	}
+	start := time.Now()
+	return ctx, func(err error) {
+		if l.metrics != nil {
+			l.metrics.RecordSource(source, time.Since(start), err)
+		}
+		if span != nil {
+			span.End(err)
+		}
+	}
+}
+
+// observeLoad records the statistics of a load that started at the given time with the metrics of the loader, and
+// ends the span of the load, if any.
+func (l *loader) observeLoad(start time.Time, span Span, err error) {
+	stats := LoadStats{Duration: time.Since(start), Fields: len(l.report.Fields), Err: err}
+	for _, field := range l.report.Fields {
+		var validationErr *ValidationError
+		if field.Origin == OriginDefault {
+			stats.Defaults++
+		}
+		if errors.As(field.Err, &validationErr) {
+			stats.ValidationFailures++
+		}
+	}
+	if err != nil {
+		for _, problem := range loadProblems(err) {
+			var hookErr *HookError
+			if errors.As(problem, &hookErr) && hookErr.Hook == "Validate" {
+				stats.ValidationFailures++
+			}
+		}
+	}
+	if l.metrics != nil {
+		l.metrics.RecordLoad(stats)
+	}
+	if span != nil {
+		span.SetAttribute("goloadenv.fields", stats.Fields)
+		span.SetAttribute("goloadenv.defaults", stats.Defaults)
+		span.SetAttribute("goloadenv.validation_failures", stats.ValidationFailures)
+		span.End(err)
+	}
+}
@@ -0,0 +1,148 @@
+package goloadenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordingHook struct {
+	fields  []string
+	loads   int
+	reloads []Changes
+	errs    []string
+}
+
+func (h *recordingHook) OnFieldResolved(field FieldReport) {
+	h.fields = append(h.fields, field.Env+"="+formatValue(field.Value))
+}
+
+func (h *recordingHook) OnLoadComplete(*Report) { h.loads++ }
+
+func (h *recordingHook) OnReload(changes Changes) { h.reloads = append(h.reloads, changes) }
+
+func (h *recordingHook) OnError(err error) { h.errs = append(h.errs, err.Error()) }
+
+func TestHooks(t *testing.T) {
+	hook := &recordingHook{}
+	env := MapSource{"HOST": "localhost", "PASSWORD": "hunter2"}
+	w, err := NewWatcher[WatchConfig](WithSources(env), WithHook(hook), WithHook(NopHook{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expectedFields := []string{"HOST=localhost", "PORT=8080", "PASSWORD=" + secretMask}
+	if !reflect.DeepEqual(hook.fields, expectedFields) || hook.loads != 1 {
+		t.Errorf("Expected %v and one load, got %v and %d loads", expectedFields, hook.fields, hook.loads)
+	}
+
+	env["PORT"] = "9090"
+	_, err = w.Reload()
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expectedChanges := []Changes{{{Path: "Port", Env: "PORT", Old: 8080, New: 9090}}}
+	if !reflect.DeepEqual(hook.reloads, expectedChanges) || hook.loads != 2 {
+		t.Errorf("Expected %v and two loads, got %v and %d loads", expectedChanges, hook.reloads, hook.loads)
+	}
+
+	env["PORT"] = "invalid"
+	_, err = w.Reload()
+	expectedErrs := []string{"error parsing 'invalid' as environment variable PORT: invalid syntax for int"}
+	if err == nil || !reflect.DeepEqual(hook.errs, expectedErrs) || len(hook.reloads) != 1 {
+		t.Errorf("Expected %v and no reload, got %v and %v", expectedErrs, hook.errs, hook.reloads)
+	}
+}
+
+type recordingTracer struct {
+	spans []string
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	name   string
+	attrs  []string
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, &recordingSpan{tracer: t, name: name}
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) {
+	s.attrs = append(s.attrs, fmt.Sprintf("%s=%v", key, value))
+}
+
+func (s *recordingSpan) End(err error) {
+	s.tracer.spans = append(s.tracer.spans, fmt.Sprintf("%s%v err=%v", s.name, s.attrs, err))
+}
+
+type recordingMetrics struct {
+	loads   []LoadStats
+	sources []string
+}
+
+func (m *recordingMetrics) RecordLoad(stats LoadStats) {
+	m.loads = append(m.loads, stats)
+}
+
+func (m *recordingMetrics) RecordSource(source string, latency time.Duration, err error) {
+	m.sources = append(m.sources, fmt.Sprintf("%s err=%v", source, err))
+}
+
+func TestTracerAndMetrics(t *testing.T) {
+	type Config struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT;default:8080;max:65535"`
+		Timeout  string `env:"TIMEOUT;default:5s"`
+		Password string `env:"PASSWORD;secretref:vault://db#password"`
+	}
+	refreshes := 0
+	kv := NewKVSource(KVFetcherFunc(func(context.Context) (map[string]string, error) {
+		refreshes++
+		return map[string]string{"HOST": "db"}, nil
+	}), 0)
+	vault := SecretResolverFunc(func(ref string) (string, error) {
+		return "hunter2", nil
+	})
+	tracer := &recordingTracer{}
+	metrics := &recordingMetrics{}
+	err := LoadEnvWithOptions(&Config{}, WithSources(kv), WithSecretResolver("vault", vault), WithTracer(tracer), WithMetrics(metrics))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expectedSpans := []string{
+		"goloadenv.source[goloadenv.source=KVFetcherFunc] err=<nil>",
+		"goloadenv.source[goloadenv.source=secretref:vault] err=<nil>",
+		"goloadenv.load[goloadenv.fields=4 goloadenv.defaults=2 goloadenv.validation_failures=0] err=<nil>",
+	}
+	if !reflect.DeepEqual(tracer.spans, expectedSpans) {
+		t.Errorf("Expected spans %v, got %v", expectedSpans, tracer.spans)
+	}
+	expectedSources := []string{"KVFetcherFunc err=<nil>", "secretref:vault err=<nil>"}
+	if !reflect.DeepEqual(metrics.sources, expectedSources) {
+		t.Errorf("Expected sources %v, got %v", expectedSources, metrics.sources)
+	}
+	if len(metrics.loads) != 1 || metrics.loads[0].Fields != 4 || metrics.loads[0].Defaults != 2 || metrics.loads[0].Duration <= 0 {
+		t.Errorf("Expected one load of 4 fields with 2 defaults, got %+v", metrics.loads)
+	}
+
+	// the cached variables of the key/value source are not fetched again
+	err = LoadEnvWithOptions(&Config{}, WithSources(kv, MapSource{"PORT": "70000"}), WithSecretResolver("vault", SecretResolverFunc(func(string) (string, error) {
+		return "", errors.New("sealed")
+	})), WithTracer(tracer), WithMetrics(metrics), WithAllErrors())
+	if err == nil {
+		t.Fatalf("Expected an error, got none")
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected the key/value source to be refreshed once, got %d refreshes", refreshes)
+	}
+	expectedSources = append(expectedSources, "secretref:vault err=sealed")
+	if !reflect.DeepEqual(metrics.sources, expectedSources) {
+		t.Errorf("Expected sources %v, got %v", expectedSources, metrics.sources)
+	}
+	if len(metrics.loads) != 2 || metrics.loads[1].ValidationFailures != 1 || metrics.loads[1].Err != err {
+		t.Errorf("Expected a second load with one validation failure, got %+v", metrics.loads)
+	}
+}
@@ -0,0 +1,113 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const descTagName = "desc"
+
+// GenerateEnvTemplate generates a ready to fill .env template for a config struct, listing every environment variable
+// with its type, whether it is required, optional or has a default value, an example value from the example tag option
+// and its description from the desc struct tag. Required variables are left empty to be filled in, variables with a
+// default value or that are optional are commented out. The variables of the config struct itself come first, the
+// others follow in a section per group tag option or else per nested struct, headed by the name of the group or the
+// path of the struct, in the order they first appear. Fields with the omitempty flag holding their zero value are
+// left out, as are all optional fields holding their zero value with WithOmitEmpty.
+//
+// Example:
+//
+//	type Config struct {
+//	  Host string `env:"HOST" desc:"Hostname the server binds to"`
+//	  DB   struct {
+//	    Port int `env:"PORT;default:5432"`
+//	  } `envPrefix:"DB_"`
+//	}
+//
+// generates
+//
+//	# Hostname the server binds to
+//	# string, required
+//	HOST=
+//
+//	# --- DB ---
+//
+//	# int, default 5432
+//	# DB_PORT=5432
+func GenerateEnvTemplate(config interface{}, opts ...PrintOption) (string, error) {
+	options := newPrintOptions(opts)
+	var blocks, sections []string
+	entries := map[string][]string{}
+	err := Iterate(config, func(f FieldInfo, v reflect.Value) error {
+		if f.Name == "" || isOmitted(v, f.Tags, options.omitEmpty) {
+			return nil
+		}
+		section := templateSection(f)
+		if section == "" {
+			blocks = append(blocks, templateEntry(f))
+			return nil
+		}
+		if _, found := entries[section]; !found {
+			sections = append(sections, section)
+		}
+		entries[section] = append(entries[section], templateEntry(f))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, section := range sections {
+		blocks = append(blocks, "# --- "+section+" ---")
+		blocks = append(blocks, entries[section]...)
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+// templateSection returns the section of a variable in an .env template, its group tag option, else the path of the
+// nested struct it belongs to, or an empty string for a variable of the config struct itself.
+func templateSection(f FieldInfo) string {
+	if group := f.Tags["group"]; group != "" {
+		return group
+	}
+	lastDot := strings.LastIndex(f.Path, ".")
+	if lastDot < 0 {
+		return ""
+	}
+	return f.Path[:lastDot]
+}
+
+// templateEntry formats the comments and assignment of a single variable in an .env template.
+func templateEntry(f FieldInfo) string {
+	var lines []string
+	if desc := f.StructField.Tag.Get(descTagName); desc != "" {
+		lines = append(lines, "# "+desc)
+	}
+	details := []string{f.Type.String()}
+	defaultValue, hasDefault := f.Tags["default"]
+	_, isOptional := f.Tags["optional"]
+	switch {
+	case hasDefault:
+		details = append(details, "default "+defaultValue)
+	case isOptional:
+		details = append(details, "optional")
+	default:
+		details = append(details, "required")
+	}
+	if isSecret(f.Tags, f.Name) {
+		details = append(details, "secret")
+	}
+	if example, hasExample := f.Tags["example"]; hasExample {
+		details = append(details, "e.g. "+example)
+	}
+	lines = append(lines, "# "+strings.Join(details, ", "))
+	if docs := f.StructField.Tag.Get(docsTagName); docs != "" {
+		lines = append(lines, "# see "+docs)
+	}
+	if hasDefault || isOptional {
+		lines = append(lines, fmt.Sprintf("# %s=%s", f.Name, defaultValue))
+	} else {
+		lines = append(lines, f.Name+"=")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,154 @@
+package goloadenv
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateEnvTemplate(t *testing.T) {
+	cfg := struct {
+		Host     string `env:"HOST" desc:"Hostname the server binds to"`
+		Region   string `env:"REGION;example:eu-west-1"`
+		Port     int    `env:"PORT;default:8080"`
+		LogLevel string `env:"LOG_LEVEL;optional" docs:"https://wiki.example.com/logging"`
+		DB       struct {
+			Password string `env:"PASSWORD;secret"`
+		} `envPrefix:"DB_"`
+		runtime int
+	}{}
+
+	expected := `# Hostname the server binds to
+# string, required
+HOST=
+
+# string, required, e.g. eu-west-1
+REGION=
+
+# int, default 8080
+# PORT=8080
+
+# string, optional
+# see https://wiki.example.com/logging
+# LOG_LEVEL=
+
+# --- DB ---
+
+# string, required, secret
+DB_PASSWORD=
+`
+	got, err := GenerateEnvTemplate(&cfg)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	grouped := struct {
+		Cache struct {
+			URL string `env:"URL;optional"`
+		} `envPrefix:"CACHE_"`
+		Host   string `env:"HOST"`
+		DBHost string `env:"DB_HOST;group:database"`
+		DBPort int    `env:"DB_PORT;default:5432;group:database"`
+	}{}
+	expected = `# string, required
+HOST=
+
+# --- Cache ---
+
+# string, optional
+# CACHE_URL=
+
+# --- database ---
+
+# string, required
+DB_HOST=
+
+# int, default 5432
+# DB_PORT=5432
+`
+	got, err = GenerateEnvTemplate(&grouped)
+	if err != nil || got != expected {
+		t.Errorf("Expected %s, got %s, %v", expected, got, err)
+	}
+
+	omitted := struct {
+		Host   string `env:"HOST"`
+		Proxy  string `env:"PROXY;optional"`
+		Region string `env:"REGION;omitempty"`
+	}{}
+	expected = `# string, required
+HOST=
+
+# string, optional
+# PROXY=
+`
+	got, err = GenerateEnvTemplate(&omitted)
+	if err != nil || got != expected {
+		t.Errorf("Expected %s, got %s, %v", expected, got, err)
+	}
+	expected = `# string, required
+HOST=
+`
+	got, err = GenerateEnvTemplate(&omitted, WithOmitEmpty())
+	if err != nil || got != expected {
+		t.Errorf("Expected %s, got %s, %v", expected, got, err)
+	}
+}
+
+func TestDescribeConfig(t *testing.T) {
+	cfg := struct {
+		Host     string        `env:"HOST;example:db.internal" desc:"Hostname the server binds to"`
+		Timeout  time.Duration `env:"TIMEOUT;default:5s"`
+		LogLevel string        `env:"LOG_LEVEL;optional" docs:"https://wiki.example.com/logging"`
+		DB       struct {
+			Password string `env:"PASSWORD;secret"`
+		} `envPrefix:"DB_"`
+		runtime int
+	}{}
+	schema, err := DescribeConfig(cfg)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	timeout := "5s"
+	expected := []SchemaField{
+		{Path: "Host", Env: "HOST", Type: "string", Description: "Hostname the server binds to", Example: "db.internal"},
+		{Path: "Timeout", Env: "TIMEOUT", Type: "time.Duration", Default: &timeout},
+		{Path: "LogLevel", Env: "LOG_LEVEL", Type: "string", Optional: true, Docs: "https://wiki.example.com/logging"},
+		{Path: "DB.Password", Env: "DB_PASSWORD", Type: "string", Secret: true},
+	}
+	if !reflect.DeepEqual(schema.Fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, schema.Fields)
+	}
+	if !schema.Fields[0].Required() || schema.Fields[1].Required() || schema.Fields[2].Required() {
+		t.Errorf("Expected only HOST and DB_PASSWORD to be required, got %v", schema.Fields)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	cfg := struct {
+		Host     string `env:"HOST" desc:"Hostname the server binds to"`
+		Port     int    `env:"PORT;default:8080" desc:"Port the server listens on"`
+		Password string `env:"DB_PASSWORD;secret;default:changeme"`
+		Debug    bool   `env:"DEBUG;optional"`
+		DB       struct {
+			Name string `env:"NAME;default:" desc:"Database name"`
+		} `envPrefix:"DB_"`
+	}{}
+
+	expected := "Environment variables:\n" +
+		"  HOST         string  required  Hostname the server binds to\n" +
+		"  PORT         int     8080      Port the server listens on\n" +
+		"  DB_PASSWORD  string  ****\n" +
+		"  DEBUG        bool    optional\n" +
+		"  DB_NAME      string  \"\"        Database name\n"
+	if usage := Usage(&cfg); usage != expected {
+		t.Errorf("Expected %s, got %s", expected, usage)
+	}
+	expected = "error describing config: config must be a struct or a pointer to a struct\n"
+	if usage := Usage(1); usage != expected {
+		t.Errorf("Expected %s, got %s", expected, usage)
+	}
+}
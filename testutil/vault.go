@@ -0,0 +1,77 @@
+// Package testutil provides in-process fakes of the remote backends supported by goloadenv, preloaded from fixtures,
+// so that sources, resolvers and the config loading of applications can be tested against them in a few lines without
+// network access or containers.
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/munisense/goloadenv"
+)
+
+// VaultToken is the token accepted by a VaultServer.
+const VaultToken = "test-token"
+
+// VaultServer is a fake HashiCorp Vault server serving the key/value secrets engine, version 2, from a fixture map.
+type VaultServer struct {
+	// URL is the address of the server, e.g. http://127.0.0.1:43567.
+	URL string
+	// Secrets maps the path of a secret, including the data/ segment, e.g. secret/data/db, to its keys and values.
+	// It may be changed between loads, but not concurrently with one.
+	Secrets map[string]map[string]interface{}
+}
+
+// NewVaultServer starts a VaultServer serving the given secrets, which is closed when the test and its subtests
+// complete.
+//
+// Example:
+//
+//	vault := testutil.NewVaultServer(t, map[string]map[string]interface{}{
+//	  "secret/data/db": {"password": "hunter2"},
+//	})
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithSecretResolver("vault", vault.Resolver()))
+func NewVaultServer(t testing.TB, secrets map[string]map[string]interface{}) *VaultServer {
+	t.Helper()
+	vault := &VaultServer{Secrets: secrets}
+	server := httptest.NewServer(http.HandlerFunc(vault.serveHTTP))
+	t.Cleanup(server.Close)
+	vault.URL = server.URL
+	return vault
+}
+
+// Resolver returns a VaultResolver reading secrets from the server.
+func (v *VaultServer) Resolver() *goloadenv.VaultResolver {
+	return &goloadenv.VaultResolver{Address: v.URL, Token: VaultToken}
+}
+
+func (v *VaultServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != VaultToken {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	secret, found := v.Secrets[strings.TrimPrefix(r.URL.Path, "/v1/")]
+	if r.Method != http.MethodGet || !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     secret,
+			"metadata": map[string]interface{}{"version": 1},
+		},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// Setenv sets the given variables in the process environment for the duration of the test, see testing.T.Setenv.
+func Setenv(t testing.TB, env map[string]string) {
+	t.Helper()
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
@@ -0,0 +1,33 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/munisense/goloadenv"
+)
+
+func TestVaultServer(t *testing.T) {
+	vault := NewVaultServer(t, map[string]map[string]interface{}{
+		"secret/data/db": {"password": "hunter2", "port": 5432},
+	})
+	Setenv(t, map[string]string{"DB_HOST": "db"})
+
+	cfg := struct {
+		Host     string `env:"DB_HOST"`
+		Port     int    `env:"DB_PORT;secretref:vault://secret/data/db#port"`
+		Password string `env:"DB_PASSWORD;secret;secretref:vault://secret/data/db#password"`
+	}{}
+	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithSecretResolver("vault", vault.Resolver()))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.Host != "db" || cfg.Port != 5432 || cfg.Password != "hunter2" {
+		t.Errorf("Expected {db 5432 hunter2}, got %v", cfg)
+	}
+
+	_, err = vault.Resolver().ResolveSecret("secret/data/missing#password")
+	expected := "vault returned status 404 for secret/data/missing"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
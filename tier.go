@@ -0,0 +1,63 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// tiers are the values of the tier tag option, which govern how strictly a field is enforced.
+var tiers = map[string]struct{}{
+	// critical fields fail the load when they cannot be loaded, like fields without a tier
+	"critical": {},
+	// important fields fall back to their default value with a warning
+	"important": {},
+	// nice fields fall back to their default value silently
+	"nice": {},
+}
+
+// checkTier returns an error if the tier tag option of a field is not one of the tiers.
+func checkTier(tags map[string]string) error {
+	tier, hasTier := tags["tier"]
+	if _, known := tiers[tier]; hasTier && !known {
+		return fmt.Errorf("unknown tier '%s', expected critical, important or nice", tier)
+	}
+	return nil
+}
+
+// degradeField handles a field of the important or nice tier that failed to load with err by setting it to its default
+// value, or its zero value when it has none or the default cannot be parsed either, and returns the origin of the new
+// value. For important fields err is raised as a warning. Fields of other tiers are left to fail the load with err.
+func (l *loader) degradeField(field reflect.Value, tags map[string]string, err error, parent reflect.Value) (Origin, error) {
+	tier := tags["tier"]
+	if tier != "important" && tier != "nice" {
+		return OriginUnset, err
+	}
+	if tier == "important" {
+		l.warn(err)
+	}
+	return l.fallBackToDefault(field, tags, parent), nil
+}
+
+// fallBackToDefault sets a field that failed to load to its default value, or its zero value when it has none or the
+// default cannot be parsed either, and returns the origin of the new value. A template default is rendered with the
+// parent struct of the field.
+func (l *loader) fallBackToDefault(field reflect.Value, tags map[string]string, parent reflect.Value) Origin {
+	field.Set(reflect.Zero(field.Type()))
+	if _, hasDefault := tags["default"]; !hasDefault || renderDefault(tags, parent) != nil {
+		return OriginUnset
+	}
+	value, origin, err := getField(tags, func(key string) (string, bool) {
+		if key == tags["name"] {
+			return "", false
+		}
+		return l.lookup(key)
+	})
+	if err == nil {
+		err = setValue(field, value, tags)
+	}
+	if err != nil {
+		field.Set(reflect.Zero(field.Type()))
+		return OriginUnset
+	}
+	return origin
+}
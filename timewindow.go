@@ -0,0 +1,233 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeWindowType is the type of TimeWindow fields, whose time zone can be set with the tz tag option.
+var timeWindowType = reflect.TypeFor[TimeWindow]()
+
+// weekdayNames maps the lower case full and abbreviated weekday names to their weekday.
+var weekdayNames = func() map[string]time.Weekday {
+	names := map[string]time.Weekday{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		names[strings.ToLower(day.String())] = day
+		names[strings.ToLower(day.String()[:3])] = day
+	}
+	return names
+}()
+
+// TimeWindow is a daily window of time, such as a maintenance window, written as a start and end time of day, e.g.
+// MAINTENANCE_WINDOW=02:00-04:00. A window whose end is before its start wraps past midnight, e.g. 22:00-02:00, and an
+// end of 24:00 is midnight. The window can be restricted to days of the week by prefixing a list or range of days, e.g.
+// Mon-Fri 09:00-17:00 or Sat,Sun 02:00-04:00, where the days are those the window starts on. A time zone name can
+// follow the times, e.g. 02:00-04:00 Europe/Amsterdam, or be given with the tz tag option, e.g.
+// env:"MAINTENANCE_WINDOW;tz:Europe/Amsterdam". Windows without time zone are in UTC.
+type TimeWindow struct {
+	// Start is the start of the window as the time since midnight.
+	Start time.Duration
+	// End is the end of the window as the time since midnight, before Start when the window wraps past midnight.
+	End time.Duration
+	// Days are the days of the week the window starts on, every day when empty.
+	Days []time.Weekday
+	// Location is the time zone of the window, UTC when nil.
+	Location *time.Location
+}
+
+// UnmarshalText parses a window like 02:00-04:00, optionally prefixed by days and followed by a time zone name.
+func (w *TimeWindow) UnmarshalText(text []byte) error {
+	window, err := parseTimeWindow(string(text), nil)
+	if err != nil {
+		return err
+	}
+	*w = window
+	return nil
+}
+
+// MarshalText formats the window like String, so it can be parsed again.
+func (w TimeWindow) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// String formats the window, e.g. Mon,Fri 02:00-04:00 Europe/Amsterdam.
+func (w TimeWindow) String() string {
+	var parts []string
+	if len(w.Days) > 0 {
+		days := make([]string, len(w.Days))
+		for i, day := range w.Days {
+			days[i] = day.String()[:3]
+		}
+		parts = append(parts, strings.Join(days, ","))
+	}
+	parts = append(parts, formatTimeOfDay(w.Start)+"-"+formatTimeOfDay(w.End))
+	if w.Location != nil && w.Location != time.UTC {
+		parts = append(parts, w.Location.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Contains reports whether the given time falls within the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	local := t.In(w.location())
+	// the offset is read from the wall clock, as days with a daylight saving time change are not 24 hours long
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second + time.Duration(local.Nanosecond())
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End && w.onDay(local.Weekday())
+	}
+	// the window wraps past midnight, it contains the evening of its start day and the morning after
+	return offset >= w.Start && w.onDay(local.Weekday()) || offset < w.End && w.onDay(local.AddDate(0, 0, -1).Weekday())
+}
+
+// Next returns the start of the first window that starts after the given time.
+func (w TimeWindow) Next(t time.Time) time.Time {
+	local := t.In(w.location())
+	for days := 0; days <= 7; days++ {
+		day := local.AddDate(0, 0, days)
+		start := timeOfDay(day, w.Start)
+		if start.After(t) && w.onDay(day.Weekday()) {
+			return start
+		}
+	}
+	return time.Time{}
+}
+
+// timeOfDay returns the time on the day of the given time at the given wall clock time since midnight.
+func timeOfDay(day time.Time, offset time.Duration) time.Time {
+	hours, minutes, seconds := offset/time.Hour, offset%time.Hour/time.Minute, offset%time.Minute/time.Second
+	return time.Date(day.Year(), day.Month(), day.Day(), int(hours), int(minutes), int(seconds), int(offset%time.Second), day.Location())
+}
+
+// onDay reports whether the window starts on the given day of the week.
+func (w TimeWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, windowDay := range w.Days {
+		if windowDay == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (w TimeWindow) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+// parseTimeWindow parses a TimeWindow, in the given time zone unless the value names one.
+func parseTimeWindow(str string, location *time.Location) (TimeWindow, error) {
+	fields := strings.Fields(str)
+	window := TimeWindow{Location: location}
+	// the times are the only field with a colon, the days come before and the time zone after them
+	times := -1
+	for i, field := range fields {
+		if strings.Contains(field, ":") {
+			times = i
+			break
+		}
+	}
+	if times < 0 || times > 1 || len(fields) > times+2 {
+		return TimeWindow{}, fmt.Errorf("invalid time window '%s', expected e.g. 02:00-04:00, Mon-Fri 09:00-17:00 or 02:00-04:00 Europe/Amsterdam", str)
+	}
+	if times == 1 {
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return TimeWindow{}, err
+		}
+		window.Days = days
+	}
+	start, end, found := strings.Cut(fields[times], "-")
+	if !found {
+		return TimeWindow{}, fmt.Errorf("invalid time window '%s', expected a start and end time like 02:00-04:00", str)
+	}
+	var err error
+	window.Start, err = parseTimeOfDay(start, false)
+	if err == nil {
+		window.End, err = parseTimeOfDay(end, true)
+	}
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	if window.Start == window.End {
+		return TimeWindow{}, fmt.Errorf("empty time window '%s', the start and end time are equal", str)
+	}
+	if len(fields) > times+1 {
+		window.Location, err = time.LoadLocation(fields[times+1])
+		if err != nil {
+			return TimeWindow{}, fmt.Errorf("invalid time zone '%s'", fields[times+1])
+		}
+	}
+	return window, nil
+}
+
+// parseWeekdays parses a comma separated list of days and ranges of days, e.g. Mon-Fri or Sat,Sun, in week order.
+func parseWeekdays(str string) ([]time.Weekday, error) {
+	var selected [7]bool
+	for _, item := range strings.Split(str, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		from, found := weekdayNames[strings.ToLower(first)]
+		if !found {
+			return nil, fmt.Errorf("unknown weekday '%s'", first)
+		}
+		to := from
+		if isRange {
+			to, found = weekdayNames[strings.ToLower(last)]
+			if !found {
+				return nil, fmt.Errorf("unknown weekday '%s'", last)
+			}
+		}
+		// a range like Fri-Mon wraps past the end of the week
+		for day := from; ; day = (day + 1) % 7 {
+			selected[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	var days []time.Weekday
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		if selected[day] {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// parseTimeOfDay parses a time of day like 02:00 or 02:00:30 into the time since midnight. 24:00 is accepted as the
+// end of a window.
+func parseTimeOfDay(str string, isEnd bool) (time.Duration, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM or HH:MM:SS", str)
+	}
+	var values [3]int
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || len(part) != 2 {
+			return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM or HH:MM:SS", str)
+		}
+		values[i] = value
+	}
+	offset := time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute + time.Duration(values[2])*time.Second
+	if values[1] > 59 || values[2] > 59 || offset > 24*time.Hour || offset == 24*time.Hour && !isEnd {
+		return 0, fmt.Errorf("invalid time of day '%s'", str)
+	}
+	return offset, nil
+}
+
+// formatTimeOfDay formats the time since midnight as HH:MM, or HH:MM:SS when it has seconds.
+func formatTimeOfDay(offset time.Duration) string {
+	hours, minutes, seconds := int(offset/time.Hour), int(offset%time.Hour/time.Minute), int(offset%time.Minute/time.Second)
+	if seconds != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d", hours, minutes)
+}
@@ -0,0 +1,77 @@
+package goloadenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnknownVariableError is returned by a load with WithRejectUnknown when variables under a managed prefix do not map to
+// any field of the config struct, which usually means a variable name is misspelled.
+type UnknownVariableError struct {
+	// Names are the sorted names of the unknown variables.
+	Names []string
+}
+
+// Error returns a string representation of the UnknownVariableError.
+func (e *UnknownVariableError) Error() string {
+	if len(e.Names) == 1 {
+		return "unknown environment variable: " + e.Names[0]
+	}
+	return fmt.Sprintf("unknown environment variables: %s", strings.Join(e.Names, ", "))
+}
+
+// WithRejectUnknown fails the load with an UnknownVariableError when the environment, the .env files or the sources
+// hold variables starting with the given prefix that do not map to any field, catching typos such as MYAPP_DB_HSOT
+// for MYAPP_DB_HOST that would otherwise be silently ignored. A variable is known when it is the name of a field, or
+// when the load looked it up, such as the aliases, fallbacks, *_FILE variables, indexed slice elements and prefix map
+// entries of the fields. The option can be given several times to manage several prefixes.
+//
+// Example:
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithPrefix("MYAPP_"), goloadenv.WithRejectUnknown("MYAPP_"))
+func WithRejectUnknown(prefix string) Option {
+	return func(l *loader) {
+		l.rejectUnknown = append(l.rejectUnknown, prefix)
+	}
+}
+
+// recordLookups wraps the lookup to record the looked up variable names, the known variables of WithRejectUnknown.
+// used internally by LoadEnv.
+func (l *loader) recordLookups() {
+	if len(l.rejectUnknown) == 0 {
+		return
+	}
+	l.lookedUp = map[string]struct{}{}
+	lookup := l.lookup
+	l.lookup = func(key string) (string, bool) {
+		l.lookedUp[key] = struct{}{}
+		return lookup(key)
+	}
+}
+
+// unknownVariables returns an UnknownVariableError for the variables under the prefixes of WithRejectUnknown that are
+// neither the name of a field nor looked up by the load, or nil if there are none.
+// used internally by LoadEnv.
+func (l *loader) unknownVariables() error {
+	if len(l.rejectUnknown) == 0 {
+		return nil
+	}
+	var unknown []string
+	for _, name := range l.envNames() {
+		_, isField := l.names[name]
+		_, isLookedUp := l.lookedUp[name]
+		if isField || isLookedUp {
+			continue
+		}
+		for _, prefix := range l.rejectUnknown {
+			if strings.HasPrefix(name, prefix) {
+				unknown = append(unknown, name)
+				break
+			}
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return &UnknownVariableError{Names: unknown}
+}
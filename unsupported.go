@@ -0,0 +1,78 @@
+package goloadenv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnsupportedFieldError is returned when a field has a type that cannot be parsed from an environment variable: a
+// channel, a function, an unsafe pointer or an interface without a registered factory or parser, or a pointer, slice,
+// array or map of such a type. It is returned when a value is supplied for the field, by its variable, its *_FILE
+// variable or a default, or when the field is required, unless the WithIgnoreUnsupported option skips such fields.
+// Optional fields without a value are skipped. Skipped fields are listed in Report.Skipped.
+type UnsupportedFieldError struct {
+	// Path is the dotted path of the field from the root config struct.
+	Path string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Kind is the unsupported kind, of the field itself or of its element type.
+	Kind reflect.Kind
+}
+
+// Error returns a string representation of the UnsupportedFieldError.
+func (e *UnsupportedFieldError) Error() string {
+	if e.Type.Kind() != e.Kind {
+		return fmt.Sprintf("field '%s' of type %s is not supported: %s elements cannot be parsed from a string", e.Path, e.Type, e.Kind)
+	}
+	return fmt.Sprintf("field '%s' of type %s is not supported: %s values cannot be parsed from a string", e.Path, e.Type, e.Kind)
+}
+
+// unsupportedField returns an UnsupportedFieldError when the type of a field cannot be parsed from a string. Fields
+// decoded with the format or encoding option are left to the decoder, which reports its own errors.
+func unsupportedField(field reflect.StructField, tags map[string]string, path string) error {
+	_, hasFormat := tags["format"]
+	_, hasEncoding := tags["encoding"]
+	if hasFormat || hasEncoding {
+		return nil
+	}
+	kind, unsupported := unsupportedKind(field.Type)
+	if !unsupported {
+		return nil
+	}
+	return &UnsupportedFieldError{Path: path, Type: field.Type, Kind: kind}
+}
+
+// unsupportedKind returns the kind that makes a type impossible to parse from a string, and whether there is one.
+func unsupportedKind(typ reflect.Type) (reflect.Kind, bool) {
+	if hasCustomParser(typ) || reflect.PointerTo(typ).Implements(scannerType) {
+		return reflect.Invalid, false
+	}
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Interface:
+		return typ.Kind(), true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return unsupportedKind(typ.Elem())
+	case reflect.Map:
+		if kind, unsupported := unsupportedKind(typ.Key()); unsupported {
+			return kind, true
+		}
+		return unsupportedKind(typ.Elem())
+	}
+	return reflect.Invalid, false
+}
+
+// valueSupplied reports whether a value is supplied for a field, by its variable, its *_FILE variable or a default, or
+// must be as the field is required.
+// used internally by LoadEnv.
+func (l *loader) valueSupplied(tags map[string]string) bool {
+	_, hasDefault := tags["default"]
+	_, isOptional := tags["optional"]
+	if hasDefault || !isOptional {
+		return true
+	}
+	if _, found := l.lookup(tags["name"]); found {
+		return true
+	}
+	_, found := l.lookup(tags["name"] + fileSuffix)
+	return found
+}
@@ -0,0 +1,60 @@
+package goloadenv
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage returns a help listing of the environment variables of a config struct, like the usage output of a command:
+// one aligned row per variable with its type, its default value or whether it is required or optional, and its
+// description from the desc struct tag. Default values of secret fields are masked. It is suitable for printing when
+// the config cannot be loaded. The config may be a struct or a pointer to a struct, its values are not used.
+//
+// Example:
+//
+//	if err := goloadenv.LoadEnv(&cfg); err != nil {
+//	  fmt.Fprintf(os.Stderr, "%v\n\n%s", err, goloadenv.Usage(&cfg))
+//	  os.Exit(2)
+//	}
+//
+// prints
+//
+//	Environment variables:
+//	  HOST         string  required  Hostname the server binds to
+//	  PORT         int     8080      Port the server listens on
+//	  DB_PASSWORD  string  required
+func Usage(config interface{}) string {
+	schema, err := DescribeConfig(config)
+	if err != nil {
+		return fmt.Sprintf("error describing config: %v\n", err)
+	}
+	var builder strings.Builder
+	builder.WriteString("Environment variables:\n")
+	tw := tabwriter.NewWriter(&builder, 0, 4, 2, ' ', 0)
+	for _, field := range schema.Fields {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", field.Env, field.Type, usageDefault(field), field.Description)
+	}
+	_ = tw.Flush()
+	// tabwriter pads the empty last column of rows without a description
+	lines := strings.Split(builder.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// usageDefault describes the default value of a variable in the Usage listing.
+func usageDefault(field SchemaField) string {
+	switch {
+	case field.Default != nil && field.Secret:
+		return secretMask
+	case field.Default != nil && *field.Default == "":
+		return `""`
+	case field.Default != nil:
+		return *field.Default
+	case field.Optional:
+		return "optional"
+	}
+	return "required"
+}
@@ -0,0 +1,330 @@
+package goloadenv
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError represents a value that was parsed successfully but violates a validation constraint of its field.
+type ValidationError struct {
+	Env string
+	// Rule is the violated tag option, e.g. "max:65535".
+	Rule  string
+	Value string
+	// Reason describes why the value violates the rule.
+	Reason string
+}
+
+// Error returns a string representation of the ValidationError.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid value '%s' for environment variable %s: %s", e.Value, e.Env, e.Reason)
+}
+
+// validationRules are the tag options enforced by validateField, in the order they are checked.
+var validationRules = []string{"min", "max", "after", "before", "oneof", "regex"}
+
+// validateField enforces the validation tag options of a field on its parsed value. The length options apply to the
+// value as a whole, the other options to every element of slices and arrays individually. Pointers are dereferenced.
+// used internally by LoadEnv.
+func validateField(field reflect.Value, tags map[string]string) error {
+	err := validateLength(field, tags)
+	if err != nil {
+		return err
+	}
+	return validateValue(field, tags)
+}
+
+// validateValue enforces the validation tag options of a field but for the length options, recursing into the elements
+// of slices and arrays.
+func validateValue(field reflect.Value, tags map[string]string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		return validateValue(field.Elem(), tags)
+	}
+	err := validateStrength(field, tags)
+	if err != nil {
+		return err
+	}
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !hasCustomParser(field.Type()) {
+			for i := 0; i < field.Len(); i++ {
+				err := validateValue(field.Index(i), tags)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	_, isPath := tags["path"]
+	if _, mustExist := tags["mustexist"]; isPath || mustExist {
+		err := normalizePath(field, tags)
+		if err != nil {
+			return err
+		}
+	}
+	for _, rule := range codeRules {
+		if _, hasRule := tags[rule]; hasRule {
+			err := validateCode(field, tags, rule)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if _, isEmail := tags["email"]; isEmail {
+		err := validateEmail(field, tags)
+		if err != nil {
+			return err
+		}
+	}
+	if _, isHostPort := tags["hostport"]; isHostPort {
+		err := validateHostPort(field, tags)
+		if err != nil {
+			return err
+		}
+	}
+	for _, rule := range validationRules {
+		arg, hasRule := tags[rule]
+		if !hasRule {
+			continue
+		}
+		reason, err := checkRule(field, rule, arg)
+		if err != nil {
+			return &EnvParseError{value: fmt.Sprint(field.Interface()), env: tags["name"], err: err}
+		}
+		if reason != "" {
+			return &ValidationError{Env: tags["name"], Rule: rule + ":" + arg, Value: fmt.Sprint(field.Interface()), Reason: reason}
+		}
+	}
+	return nil
+}
+
+// checkRule checks a single validation rule, returning the reason the value violates it or an empty string if it
+// does not. An error is returned if the rule itself is invalid for the field.
+func checkRule(field reflect.Value, rule string, arg string) (string, error) {
+	switch rule {
+	case "min", "max":
+		// a bare number bounds a duration in nanoseconds, like any other integer
+		if _, err := strconv.ParseFloat(arg, 64); field.Type() == durationType && err != nil {
+			return checkDurationRule(time.Duration(field.Int()), rule, arg)
+		}
+		value, isNumber := numericValue(field)
+		if !isNumber {
+			return "", fmt.Errorf("%s only applies to numeric fields, not %s", rule, field.Type())
+		}
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s bound '%s'", rule, arg)
+		}
+		if rule == "min" && value < bound {
+			return fmt.Sprintf("must be at least %s", arg), nil
+		}
+		if rule == "max" && value > bound {
+			return fmt.Sprintf("must be at most %s", arg), nil
+		}
+	case "after", "before":
+		if field.Type() != timeType {
+			return "", fmt.Errorf("%s only applies to time.Time fields, not %s", rule, field.Type())
+		}
+		bound, err := time.Parse(time.RFC3339, arg)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s bound '%s', expected RFC 3339", rule, arg)
+		}
+		value := field.Interface().(time.Time)
+		if rule == "after" && !value.After(bound) {
+			return fmt.Sprintf("must be after %s", arg), nil
+		}
+		if rule == "before" && !value.Before(bound) {
+			return fmt.Sprintf("must be before %s", arg), nil
+		}
+	case "oneof":
+		allowed := strings.Split(arg, ",")
+		if !slices.Contains(allowed, fmt.Sprint(field.Interface())) {
+			return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")), nil
+		}
+	case "regex":
+		if field.Kind() != reflect.String {
+			return "", fmt.Errorf("regex only applies to string fields, not %s", field.Type())
+		}
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex '%s': %w", arg, err)
+		}
+		if !re.MatchString(field.String()) {
+			return fmt.Sprintf("must match %s", arg), nil
+		}
+	}
+	return "", nil
+}
+
+// checkDurationRule checks a min or max rule of a time.Duration field, whose bound is a duration, e.g. min:1s.
+func checkDurationRule(value time.Duration, rule string, arg string) (string, error) {
+	bound, err := time.ParseDuration(arg)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s bound '%s'", rule, arg)
+	}
+	if rule == "min" && value < bound {
+		return fmt.Sprintf("must be at least %s", arg), nil
+	}
+	if rule == "max" && value > bound {
+		return fmt.Sprintf("must be at most %s", arg), nil
+	}
+	return "", nil
+}
+
+// validateEmail enforces the email option on a string field, which must hold a single RFC 5322 address, and
+// canonicalizes it to the bare address with a lowercase domain, e.g. Ops <ops@EXAMPLE.com> to ops@example.com.
+func validateEmail(field reflect.Value, tags map[string]string) error {
+	if field.Kind() != reflect.String {
+		return &EnvParseError{value: fmt.Sprint(field.Interface()), env: tags["name"], err: fmt.Errorf("email only applies to string fields, not %s", field.Type())}
+	}
+	address, err := mail.ParseAddress(field.String())
+	if err != nil {
+		return &ValidationError{Env: tags["name"], Rule: "email", Value: field.String(), Reason: "must be an email address"}
+	}
+	field.SetString(canonicalAddress(address.Address))
+	return nil
+}
+
+// numericValue returns the value of an integer, unsigned integer or float field as a float64.
+func numericValue(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	}
+	return 0, false
+}
+
+// strengthRules are the tag options enforced by validateStrength.
+var strengthRules = []string{"minBytes", "minEntropy"}
+
+// validateStrength enforces the minimum length and entropy of secret material in string and []byte fields, e.g.
+// minBytes:32 for an HMAC signing key. A []byte field with the encoding option holds the decoded bytes, so those are
+// measured rather than their base64 or hex text. The value itself is never included in the error.
+func validateStrength(field reflect.Value, tags map[string]string) error {
+	var data []byte
+	switch {
+	case field.Kind() == reflect.String:
+		data = []byte(field.String())
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		data = field.Bytes()
+	default:
+		return nil
+	}
+	for _, rule := range strengthRules {
+		arg, hasRule := tags[rule]
+		if !hasRule {
+			continue
+		}
+		bound, err := strconv.Atoi(arg)
+		if err != nil {
+			return &EnvParseError{value: secretMask, env: tags["name"], err: fmt.Errorf("invalid %s bound '%s'", rule, arg)}
+		}
+		var reason string
+		switch {
+		case rule == "minBytes" && len(data) < bound:
+			reason = fmt.Sprintf("must be at least %d bytes, got %d", bound, len(data))
+		case rule == "minEntropy" && entropyBits(data) < float64(bound):
+			reason = fmt.Sprintf("must have at least %d bits of entropy, got %.0f", bound, entropyBits(data))
+		}
+		if reason != "" {
+			return &ValidationError{Env: tags["name"], Rule: rule + ":" + arg, Value: secretMask, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// lengthRules are the tag options constraining the length of strings, in characters, and of slices, arrays and maps,
+// in elements.
+var lengthRules = []string{"notempty", "minlen", "maxlen"}
+
+// validateLength enforces the length tag options of a field on its value as a whole, also when the variable is set to
+// the empty string. Pointers are dereferenced, a nil pointer has no length to check.
+// used internally by LoadEnv.
+func validateLength(field reflect.Value, tags map[string]string) error {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	var length int
+	switch field.Kind() {
+	case reflect.String:
+		length = utf8.RuneCountInString(field.String())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		length = field.Len()
+	}
+	for _, rule := range lengthRules {
+		arg, hasRule := tags[rule]
+		if !hasRule {
+			continue
+		}
+		value := fmt.Sprint(field.Interface())
+		if isSecret(tags, tags["name"]) {
+			value = secretMask
+		}
+		switch field.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		default:
+			return &EnvParseError{value: value, env: tags["name"], err: fmt.Errorf("%s only applies to strings, slices, arrays and maps, not %s", rule, field.Type())}
+		}
+		var reason string
+		if rule == "notempty" {
+			if length == 0 {
+				return &ValidationError{Env: tags["name"], Rule: rule, Value: value, Reason: "must not be empty"}
+			}
+			continue
+		}
+		bound, err := strconv.Atoi(arg)
+		if err != nil {
+			return &EnvParseError{value: value, env: tags["name"], err: fmt.Errorf("invalid %s bound '%s'", rule, arg)}
+		}
+		switch {
+		case rule == "minlen" && length < bound:
+			reason = fmt.Sprintf("length must be at least %d, got %d", bound, length)
+		case rule == "maxlen" && length > bound:
+			reason = fmt.Sprintf("length must be at most %d, got %d", bound, length)
+		}
+		if reason != "" {
+			return &ValidationError{Env: tags["name"], Rule: rule + ":" + arg, Value: value, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// entropyBits estimates the entropy of data in bits from the Shannon entropy of its byte distribution. It is an upper
+// bound that catches repetitive or low variety values, not a proof of randomness.
+func entropyBits(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	perByte := 0.0
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(len(data))
+		perByte -= p * math.Log2(p)
+	}
+	return perByte * float64(len(data))
+}
@@ -0,0 +1,429 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type ValidatedConfig struct {
+	Port     int           `env:"PORT;min:1;max:65535"`
+	LogLevel string        `env:"LOG_LEVEL;oneof:debug,info,warn,error;default:info"`
+	Email    string        `env:"EMAIL;regex:^.+@.+$;optional"`
+	Ratios   []float64     `env:"RATIOS;min:0;max:1;optional"`
+	Timeout  time.Duration `env:"TIMEOUT;min:1s;max:10m;default:30s"`
+	Backoff  time.Duration `env:"BACKOFF;min:1000;optional"`
+	Cutoff   time.Time     `env:"CUTOFF;after:2024-01-01T00:00:00Z;before:2030-01-01T00:00:00Z;optional"`
+}
+
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		env      map[string]string
+		expected string
+	}{
+		{map[string]string{"PORT": "8080"}, ""},
+		{map[string]string{"PORT": "70000"}, "invalid value '70000' for environment variable PORT: must be at most 65535"},
+		{map[string]string{"PORT": "0"}, "invalid value '0' for environment variable PORT: must be at least 1"},
+		{map[string]string{"PORT": "80", "LOG_LEVEL": "trace"}, "invalid value 'trace' for environment variable LOG_LEVEL: must be one of debug, info, warn, error"},
+		{map[string]string{"PORT": "80", "EMAIL": "ops"}, "invalid value 'ops' for environment variable EMAIL: must match ^.+@.+$"},
+		{map[string]string{"PORT": "80", "RATIOS": "[0.5,1.5]"}, "invalid value '1.5' for environment variable RATIOS: must be at most 1"},
+		{map[string]string{"PORT": "80", "TIMEOUT": "500ms"}, "invalid value '500ms' for environment variable TIMEOUT: must be at least 1s"},
+		{map[string]string{"PORT": "80", "TIMEOUT": "1h"}, "invalid value '1h0m0s' for environment variable TIMEOUT: must be at most 10m"},
+		{map[string]string{"PORT": "80", "BACKOFF": "1µs"}, ""},
+		{map[string]string{"PORT": "80", "BACKOFF": "1ns"}, "invalid value '1ns' for environment variable BACKOFF: must be at least 1000"},
+		{map[string]string{"PORT": "80", "CUTOFF": "2025-06-01T00:00:00Z"}, ""},
+		{map[string]string{"PORT": "80", "CUTOFF": "2023-06-01T00:00:00Z"}, "invalid value '2023-06-01 00:00:00 +0000 UTC' for environment variable CUTOFF: must be after 2024-01-01T00:00:00Z"},
+		{map[string]string{"PORT": "80", "CUTOFF": "2031-06-01T00:00:00Z"}, "invalid value '2031-06-01 00:00:00 +0000 UTC' for environment variable CUTOFF: must be before 2030-01-01T00:00:00Z"},
+	}
+	for _, test := range tests {
+		clearTestEnv()
+		for key, value := range test.env {
+			err := os.Setenv(key, value)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}
+
+		err := LoadEnv(&ValidatedConfig{})
+		if test.expected == "" {
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected %s, got %v", test.expected, err)
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected a ValidationError, got %T", err)
+		}
+	}
+}
+
+func TestLengthFields(t *testing.T) {
+	type LengthConfig struct {
+		Name   string            `env:"NAME;notempty"`
+		Code   string            `env:"CODE;minlen:2;maxlen:3;optional"`
+		Hosts  []string          `env:"HOSTS;minlen:1;maxlen:2;optional"`
+		Token  string            `env:"TOKEN;secret;minlen:8;optional"`
+		Labels map[string]string `env:"LABELS;notempty;optional"`
+	}
+	tests := []struct {
+		env      MapSource
+		expected string
+	}{
+		{MapSource{"NAME": "app", "CODE": "日本", "HOSTS": "[a,b]", "LABELS": "a=b"}, ""},
+		{MapSource{"NAME": ""}, "invalid value '' for environment variable NAME: must not be empty"},
+		{MapSource{"NAME": "app", "CODE": "a"}, "invalid value 'a' for environment variable CODE: length must be at least 2, got 1"},
+		{MapSource{"NAME": "app", "CODE": "abcd"}, "invalid value 'abcd' for environment variable CODE: length must be at most 3, got 4"},
+		{MapSource{"NAME": "app", "HOSTS": "[a,b,c]"}, "invalid value '[a b c]' for environment variable HOSTS: length must be at most 2, got 3"},
+		{MapSource{"NAME": "app", "HOSTS": "[]"}, "invalid value '[]' for environment variable HOSTS: length must be at least 1, got 0"},
+		{MapSource{"NAME": "app", "TOKEN": "short"}, "invalid value '****' for environment variable TOKEN: length must be at least 8, got 5"},
+		{MapSource{"NAME": "app", "LABELS": ""}, "invalid value 'map[]' for environment variable LABELS: must not be empty"},
+	}
+	for _, test := range tests {
+		err := LoadEnvWithOptions(&LengthConfig{}, WithSources(test.env))
+		if test.expected == "" {
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			continue
+		}
+		var validationErr *ValidationError
+		if err == nil || err.Error() != test.expected || !errors.As(err, &validationErr) {
+			t.Errorf("Expected %s, got %v", test.expected, err)
+		}
+	}
+
+	elements := struct {
+		Hosts []string `env:"HOSTS;maxlen:2"`
+		Ports []int    `env:"PORTS;minlen:1"`
+	}{}
+	err := LoadEnvWithOptions(&elements, WithSources(MapSource{"HOSTS": "[a.example.com,b.example.com]", "PORTS": "[80,443]"}))
+	if err != nil || len(elements.Hosts) != 2 || len(elements.Ports) != 2 {
+		t.Errorf("Expected the lengths to apply to the slices rather than their elements, got %v and %+v", err, elements)
+	}
+
+	err = LoadEnvWithOptions(&struct {
+		Port int `env:"PORT;notempty"`
+	}{}, WithSources(MapSource{"PORT": "80"}))
+	expected := "error parsing '80' as environment variable PORT: notempty only applies to strings, slices, arrays and maps, not int"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	strict := struct {
+		Hosts []string `env:"HOSTS;minlen:1;maxlen:5"`
+	}{}
+	err = LoadEnvFromMap(&strict, map[string]string{"HOSTS": "[a]"}, WithStrictMode())
+	if err != nil || len(strict.Hosts) != 1 {
+		t.Errorf("Expected the length options to be known in strict mode, got %v and %v", err, strict.Hosts)
+	}
+}
+
+func TestEmailFields(t *testing.T) {
+	cfg := struct {
+		From       string         `env:"FROM;email"`
+		Recipients []string       `env:"RECIPIENTS;email"`
+		OnCall     []mail.Address `env:"ON_CALL;optional"`
+	}{}
+
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{
+		"FROM":       "Alerts <alerts@Example.COM>",
+		"RECIPIENTS": "[ops@example.com,Dev <Dev@EXAMPLE.org>]",
+		"ON_CALL":    "[a@example.com,B <b@Example.com>]",
+	}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.From != "alerts@example.com" || !reflect.DeepEqual(cfg.Recipients, []string{"ops@example.com", "Dev@example.org"}) {
+		t.Errorf("Expected canonical addresses, got %v and %v", cfg.From, cfg.Recipients)
+	}
+	if len(cfg.OnCall) != 2 || cfg.OnCall[1].Name != "B" || cfg.OnCall[1].Address != "b@example.com" {
+		t.Errorf("Expected two on-call addresses, got %v", cfg.OnCall)
+	}
+
+	err = LoadEnvWithOptions(&cfg, WithSources(MapSource{"FROM": "alerts@example.com", "RECIPIENTS": "[ops@example.com,ops]"}))
+	expected := "invalid value 'ops' for environment variable RECIPIENTS: must be an email address"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestCodeFields(t *testing.T) {
+	cfg := struct {
+		Country   string   `env:"COUNTRY;iso3166"`
+		Currency  string   `env:"CURRENCY;iso4217"`
+		Languages []string `env:"LANGUAGES;bcp47"`
+	}{}
+
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"COUNTRY": "nl", "CURRENCY": "eur", "LANGUAGES": "[EN_us,zh-hant-TW,es-419,gsw,de-DE-1996]"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	expectedLanguages := []string{"en-US", "zh-Hant-TW", "es-419", "gsw", "de-DE-1996"}
+	if cfg.Country != "NL" || cfg.Currency != "EUR" || !reflect.DeepEqual(cfg.Languages, expectedLanguages) {
+		t.Errorf("Expected NL, EUR and %v, got %v", expectedLanguages, cfg)
+	}
+
+	tests := []struct {
+		env      MapSource
+		expected string
+	}{
+		{MapSource{"COUNTRY": "XX", "CURRENCY": "EUR", "LANGUAGES": "[en]"}, "invalid value 'XX' for environment variable COUNTRY: must be an ISO 3166-1 alpha-2 country code"},
+		{MapSource{"COUNTRY": "NL", "CURRENCY": "EURO", "LANGUAGES": "[en]"}, "invalid value 'EURO' for environment variable CURRENCY: must be an ISO 4217 currency code"},
+		{MapSource{"COUNTRY": "NL", "CURRENCY": "EUR", "LANGUAGES": "[en,english]"}, "invalid value 'english' for environment variable LANGUAGES: must be a BCP 47 language tag"},
+		{MapSource{"COUNTRY": "NL", "CURRENCY": "EUR", "LANGUAGES": "[en-XY]"}, "invalid value 'en-XY' for environment variable LANGUAGES: must be a BCP 47 language tag"},
+	}
+	for _, test := range tests {
+		err := LoadEnvWithOptions(&cfg, WithSources(test.env))
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected %s, got %v", test.expected, err)
+		}
+	}
+}
+
+func TestPathFields(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfg := struct {
+		DataDir  string   `env:"DATA_DIR;path;default:~/data/../cache/"`
+		LogDir   string   `env:"LOG_DIR;path"`
+		Plugins  []string `env:"PLUGINS;path;optional"`
+		CertsDir string   `env:"CERTS_DIR;path;mustexist"`
+	}{}
+
+	err := LoadEnvWithOptions(&cfg, WithSources(MapSource{"BASE": "/var/lib/app", "LOG_DIR": "${BASE}//logs", "PLUGINS": "[~/a,./b/]", "CERTS_DIR": home}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if cfg.DataDir != filepath.Join(home, "cache") || cfg.LogDir != filepath.FromSlash("/var/lib/app/logs") || cfg.CertsDir != home {
+		t.Errorf("Expected expanded and cleaned paths, got %v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Plugins, []string{filepath.Join(home, "a"), "b"}) {
+		t.Errorf("Expected [%s b], got %v", filepath.Join(home, "a"), cfg.Plugins)
+	}
+
+	missing := filepath.Join(home, "missing")
+	err = LoadEnvWithOptions(&cfg, WithSources(MapSource{"LOG_DIR": "/logs", "CERTS_DIR": missing}))
+	expected := fmt.Sprintf("invalid value '%s' for environment variable CERTS_DIR: must exist", missing)
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestSecretStrength(t *testing.T) {
+	tests := map[string]string{
+		"c2VjcmV0LXNpZ25pbmcta2V5LXdpdGgtZW5vdWdoLWJ5dGVz": "",
+		"short": "invalid value '****' for environment variable JWT_SECRET: must be at least 32 bytes, got 5",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa": "invalid value '****' for environment variable JWT_SECRET: must have at least 128 bits of entropy, got 0",
+	}
+	for value, expected := range tests {
+		clearTestEnv()
+		err := os.Setenv("JWT_SECRET", value)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		someStruct := struct {
+			Secret string `env:"JWT_SECRET;secret;minBytes:32;minEntropy:128"`
+		}{}
+		err = LoadEnv(&someStruct)
+		if expected == "" {
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	}
+
+	// the decoded bytes are measured, not the 24 characters of base64
+	binary := struct {
+		Key []byte `env:"HMAC_KEY;secret;encoding:base64;minBytes:20"`
+	}{}
+	err := LoadEnvFromMap(&binary, map[string]string{"HMAC_KEY": "c2VjcmV0LXNpZ25pbmcta2V5"})
+	expected := "invalid value '****' for environment variable HMAC_KEY: must be at least 20 bytes, got 18"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	cfg := ValidatedConfig{Port: 80}
+	checklist, err := ValidateEnv(&cfg, WithSources(MapSource{"LOG_LEVEL": "trace", "EMAIL": "ops@example.com"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if checklist.OK() || len(checklist.Missing) != 1 || checklist.Missing[0].Env != "PORT" || len(checklist.Malformed) != 1 || checklist.Malformed[0].Env != "LOG_LEVEL" {
+		t.Errorf("Expected PORT missing and LOG_LEVEL malformed, got %v", checklist)
+	}
+	if cfg.Port != 80 || cfg.Email != "" {
+		t.Errorf("Expected the config to be unchanged, got %v", cfg)
+	}
+
+	checklist, err = ValidateEnv(ValidatedConfig{}, WithSources(MapSource{"PORT": "8080"}))
+	if err != nil || !checklist.OK() {
+		t.Errorf("Expected an empty checklist, got %v, %v", checklist, err)
+	}
+
+	// the options of the caller are not appended to in place
+	opts := make([]Option, 1, 2)
+	opts[0] = WithSources(MapSource{"PORT": "8080"})
+	_, err = ValidateEnv(ValidatedConfig{}, opts...)
+	if err != nil || opts[:2][1] != nil {
+		t.Errorf("Expected the options to be left untouched, got %v", err)
+	}
+}
+
+type DeployMode int
+
+const (
+	ModeDev DeployMode = iota
+	ModeStaging
+	ModeProd
+)
+
+type LogFormat string
+
+func TestEnumFields(t *testing.T) {
+	someStruct := struct {
+		Mode    DeployMode   `env:"MODE;enum:dev=0,staging=1,prod=2"`
+		Format  LogFormat    `env:"LOG_FORMAT;enum:text,json;default:text"`
+		Targets []DeployMode `env:"TARGETS;enum:dev=0, staging=1, prod=2;optional"`
+	}{}
+	err := LoadEnvWithOptions(&someStruct, WithSources(MapSource{"MODE": "prod", "LOG_FORMAT": "json", "TARGETS": "[dev,staging]"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Mode != ModeProd || someStruct.Format != "json" || !reflect.DeepEqual(someStruct.Targets, []DeployMode{ModeDev, ModeStaging}) {
+		t.Errorf("Expected MODE=prod, LOG_FORMAT=json and TARGETS=[dev,staging], got %v", someStruct)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(MapSource{"MODE": "2"}))
+	expected := "error parsing '2' as environment variable MODE: must be one of dev, staging, prod"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestTierFields(t *testing.T) {
+	someStruct := struct {
+		Host    string `env:"HOST;tier:critical"`
+		Workers int    `env:"WORKERS;tier:important;default:4"`
+		Cache   int    `env:"CACHE_SIZE;tier:nice"`
+		Retries int    `env:"RETRIES;tier:nice;default:3"`
+	}{}
+	report, err := LoadEnvReport(&someStruct, WithSources(MapSource{"HOST": "db", "WORKERS": "many", "RETRIES": "x"}))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if someStruct.Workers != 4 || someStruct.Cache != 0 || someStruct.Retries != 3 {
+		t.Errorf("Expected WORKERS=4, CACHE_SIZE=0 and RETRIES=3, got %v", someStruct)
+	}
+	expected := "error parsing 'many' as environment variable WORKERS: invalid syntax for int"
+	if len(report.Warnings) != 1 || report.Warnings[0].Error() != expected {
+		t.Errorf("Expected warning %s, got %v", expected, report.Warnings)
+	}
+	if report.Fields[1].Origin != OriginDefault || report.Fields[2].Origin != OriginUnset {
+		t.Errorf("Expected origins default and unset, got %s and %s", report.Fields[1].Origin, report.Fields[2].Origin)
+	}
+
+	err = LoadEnvWithOptions(&someStruct, WithSources(MapSource{}))
+	expected = "environment variable not found: HOST"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	invalid := struct {
+		Host string `env:"HOST;tier:optional"`
+	}{}
+	err = LoadEnvWithOptions(&invalid, WithSources(MapSource{}))
+	expected = "error getting tags for field: 'Host': unknown tier 'optional', expected critical, important or nice"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	type TLS struct {
+		Enabled  bool   `env:"ENABLED;default:false"`
+		CertFile string `env:"CERT_FILE;requiredif:ENABLED=true"`
+		KeyFile  string `env:"KEY_FILE;requiredif:ENABLED=true"`
+	}
+	type Config struct {
+		TLS      TLS    `envPrefix:"TLS_"`
+		Mode     string `env:"MODE;default:local"`
+		Region   string `env:"REGION;requiredif:MODE=cloud"`
+		ProxyURL string `env:"PROXY_URL;optional"`
+		ProxyCA  string `env:"PROXY_CA;requiredif:PROXY_URL"`
+	}
+
+	var config Config
+	err := LoadEnvFromMap(&config, map[string]string{})
+	if err != nil {
+		t.Errorf("Expected the conditional fields to be optional, got %v", err)
+	}
+
+	err = LoadEnvFromMap(&config, map[string]string{"TLS_ENABLED": "yes", "TLS_CERT_FILE": "cert.pem", "MODE": "cloud", "PROXY_URL": "http://proxy"}, WithAllErrors())
+	expected := "error loading field 'TLS.KeyFile': environment variable not found: TLS_KEY_FILE, required when TLS_ENABLED=true, got TLS_ENABLED='yes'\n" +
+		"environment variable not found: REGION, required when MODE=cloud, got MODE='cloud'\n" +
+		"environment variable not found: PROXY_CA, required when PROXY_URL, got PROXY_URL='http://proxy'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	ordered := struct {
+		CertFile string `env:"CERT_FILE;requiredif:TLS=true"`
+		TLS      string `env:"TLS;encrypted;default:enc:reverse:ZXVydA=="`
+	}{}
+	RegisterDecryptor("reverse", func(ciphertext []byte) ([]byte, error) {
+		plaintext := slices.Clone(ciphertext)
+		slices.Reverse(plaintext)
+		return plaintext, nil
+	})
+	err = LoadEnvFromMap(&ordered, map[string]string{})
+	// the value of a secret condition is masked
+	expected = "environment variable not found: CERT_FILE, required when TLS=true, got TLS='****'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	_, err = Get[string]("CA;requiredif:=x", WithSources(MapSource{}))
+	expected = "error parsing '=x' as environment variable CA: invalid requiredif condition '=x', expected NAME or NAME=VALUE"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+func TestEmptyValuesAreValidated(t *testing.T) {
+	config := struct {
+		Port    int    `env:"PORT;min:1"`
+		Level   string `env:"LEVEL;oneof:debug,info"`
+		Name    string `env:"NAME;regex:^[a-z]+$"`
+		Email   string `env:"EMAIL;email"`
+		Bind    string `env:"BIND;hostport"`
+		Region  string `env:"REGION;requiredif:MODE=cloud"`
+		Comment string `env:"COMMENT;oneof:a,b;optional"`
+	}{}
+	env := map[string]string{"PORT": "", "LEVEL": "", "NAME": "", "EMAIL": "", "BIND": "", "REGION": "", "MODE": "cloud"}
+	err := LoadEnvFromMap(&config, env, WithAllErrors())
+	expected := "invalid value '0' for environment variable PORT: must be at least 1\n" +
+		"invalid value '' for environment variable LEVEL: must be one of debug, info\n" +
+		"invalid value '' for environment variable NAME: must match ^[a-z]+$\n" +
+		"invalid value '' for environment variable EMAIL: must be an email address\n" +
+		"invalid value '' for environment variable BIND: must be a host:port address\n" +
+		"environment variable not found: REGION, required when MODE=cloud, got MODE='cloud'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,94 @@
+package goloadenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultResolver is a SecretResolver that reads secrets from the key/value secrets engine of HashiCorp Vault, for
+// references like secret/data/db#password: the path of the secret followed by the key within the secret. Both version
+// 1 and version 2 of the engine are supported, for version 2 the path includes the data/ segment.
+//
+// Example:
+//
+//	type Config struct {
+//	  DBPassword string `env:"DB_PASSWORD;secret;secretref:vault://secret/data/db#password"`
+//	}
+//
+//	err := goloadenv.LoadEnvWithOptions(&cfg, goloadenv.WithSecretResolver("vault", &goloadenv.VaultResolver{}))
+type VaultResolver struct {
+	// Address is the address of the Vault server, defaults to the VAULT_ADDR environment variable.
+	Address string
+	// Token is the Vault token, defaults to the VAULT_TOKEN environment variable.
+	Token string
+	// Client is the HTTP client used for requests, defaults to a client with a 10 second timeout.
+	Client *http.Client
+}
+
+// ResolveSecret reads the key of the secret at the path of the reference.
+func (v *VaultResolver) ResolveSecret(ref string) (string, error) {
+	return v.ResolveSecretContext(context.Background(), ref)
+}
+
+// ResolveSecretContext reads the key of the secret at the path of the reference, aborting the request when the
+// context is done.
+func (v *VaultResolver) ResolveSecretContext(ctx context.Context, ref string) (string, error) {
+	path, key, found := strings.Cut(ref, "#")
+	if !found || path == "" || key == "" {
+		return "", fmt.Errorf("invalid vault reference '%s', expected path#key", ref)
+	}
+	address := v.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	token := v.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(address, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, path)
+	}
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", fmt.Errorf("error decoding vault response: %w", err)
+	}
+	data := body.Data
+	// version 2 of the engine nests the secret in data.data
+	if nested, isV2 := data["data"].(map[string]interface{}); isV2 {
+		data = nested
+	}
+	value, found := data[key]
+	if !found {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, path)
+	}
+	if str, isString := value.(string); isString {
+		return str, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,110 @@
+package goloadenv
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Watcher keeps a config struct of type T up to date by reloading it periodically, so long-running services can pick
+// up changed environment variables, .env files and secret files without a restart. Every reload loads a fresh T, the
+// current config is swapped atomically when it changed and is never modified, so it is safe to use from any goroutine.
+//
+// Example:
+//
+//	w, err := goloadenv.NewWatcher[Config](goloadenv.WithDotEnv(".env"), goloadenv.WithFileFallback())
+//	if err != nil {
+//	  return err
+//	}
+//	go w.Watch(ctx, 30*time.Second, func(changes goloadenv.Changes) {
+//	  for _, change := range changes {
+//	    slog.Info("config changed", "field", change.Path, "old", change.Old, "new", change.New)
+//	  }
+//	}, nil)
+//	cfg := w.Config()
+type Watcher[T any] struct {
+	opts    []Option
+	hooks   []Hook
+	current atomic.Pointer[T]
+	// mu serializes reloads so changes are reported in order, and guards the history.
+	mu      sync.Mutex
+	history []LoadRecord
+	// secretKey keys the secret fingerprints of the history, it is generated randomly per watcher.
+	secretKey []byte
+}
+
+// NewWatcher loads a config struct of type T with the given options like LoadEnvWithOptions, and returns a Watcher
+// that reloads it with the same options.
+func NewWatcher[T any](opts ...Option) (*Watcher[T], error) {
+	w := &Watcher[T]{opts: append([]Option{}, opts...), hooks: newLoader(opts...).hooks, secretKey: make([]byte, 32)}
+	_, err := rand.Read(w.secretKey)
+	if err != nil {
+		return nil, err
+	}
+	config := new(T)
+	report, err := LoadEnvReport(config, w.opts...)
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(config)
+	w.record(report, nil, nil)
+	return w, nil
+}
+
+// Config returns the current config, it must not be modified.
+func (w *Watcher[T]) Config() *T {
+	return w.current.Load()
+}
+
+// Reload loads the config again and swaps it in when any field changed, returning the changed fields. When the load
+// fails the current config is kept and the error is returned.
+func (w *Watcher[T]) Reload() (Changes, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	config := new(T)
+	report, err := LoadEnvReport(config, w.opts...)
+	if err != nil {
+		w.record(report, nil, err)
+		return nil, err
+	}
+	return w.swap(config, report), nil
+}
+
+// swap swaps in a reloaded config when any field changed, records the reload and notifies the hooks, returning the
+// changed fields.
+func (w *Watcher[T]) swap(config *T, report *Report) Changes {
+	// both configs have type T, so Diff cannot fail
+	changes, _ := Diff(w.current.Load(), config)
+	if len(changes) > 0 {
+		w.current.Store(config)
+	}
+	w.record(report, changes, nil)
+	for _, hook := range w.hooks {
+		hook.OnReload(changes)
+	}
+	return changes
+}
+
+// Watch reloads the config at the given interval until the context is done, calling onChange with the changed fields
+// after every reload that changed the config, and onError, if not nil, with the error of every failed reload. It
+// returns the error of the context.
+func (w *Watcher[T]) Watch(ctx context.Context, interval time.Duration, onChange func(Changes), onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		changes, err := w.Reload()
+		switch {
+		case err != nil && onError != nil:
+			onError(err)
+		case len(changes) > 0 && onChange != nil:
+			onChange(changes)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package goloadenv
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type WatchConfig struct {
+	Host     string `env:"HOST"`
+	Port     int    `env:"PORT;default:8080"`
+	Password string `env:"PASSWORD;secret"`
+}
+
+func TestWatcher(t *testing.T) {
+	var mu sync.Mutex
+	env := MapSource{"HOST": "localhost", "PASSWORD": "hunter2"}
+	lookup := func(key string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		return env.Lookup(key)
+	}
+
+	w, err := NewWatcher[WatchConfig](WithLookupFunc(lookup))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	initial := w.Config()
+	if initial.Host != "localhost" || initial.Port != 8080 {
+		t.Errorf("Expected HOST=localhost and PORT=8080, got %v", initial)
+	}
+
+	changes, err := w.Reload()
+	if err != nil || len(changes) != 0 || w.Config() != initial {
+		t.Errorf("Expected no changes, got %v, %v", changes, err)
+	}
+
+	mu.Lock()
+	env["PORT"] = "9090"
+	env["PASSWORD"] = "hunter3"
+	mu.Unlock()
+	changed := make(chan Changes, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() {
+		_ = w.Watch(ctx, time.Millisecond, func(changes Changes) {
+			changed <- changes
+			cancel()
+		}, nil)
+	}()
+	select {
+	case changes = <-changed:
+	case <-ctx.Done():
+		t.Fatalf("Expected changes before the timeout")
+	}
+	expected := Changes{
+		{Path: "Port", Env: "PORT", Old: 8080, New: 9090},
+		{Path: "Password", Env: "PASSWORD", Old: secretMask, New: secretMask},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+	if initial.Port != 8080 || w.Config().Port != 9090 {
+		t.Errorf("Expected the old config to be kept and the new one swapped in, got %v and %v", initial, w.Config())
+	}
+
+	mu.Lock()
+	env["PORT"] = "invalid"
+	mu.Unlock()
+	_, err = w.Reload()
+	if err == nil || w.Config().Port != 9090 {
+		t.Errorf("Expected an error and the current config to be kept, got %v and %v", err, w.Config())
+	}
+}
+
+func TestWatcherReloadGroup(t *testing.T) {
+	type GroupedConfig struct {
+		Host string `env:"HOST"`
+		*EmbeddedDBConfig
+	}
+	env := MapSource{"HOST": "localhost", "DB_NAME": "app"}
+	w, err := NewWatcher[GroupedConfig](WithSources(env))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	initial := w.Config()
+
+	env["HOST"] = "example.com"
+	env["DB_NAME"] = "app2"
+	changes, err := w.ReloadGroup("database")
+	if err != nil || len(changes) != 1 || changes[0].Path != "DBName" {
+		t.Errorf("Expected DBName to change, got %v, %v", changes, err)
+	}
+	if cfg := w.Config(); cfg.Host != "localhost" || cfg.DBName != "app2" {
+		t.Errorf("Expected only the database group to be reloaded, got %v", cfg)
+	}
+	if initial.DBName != "app" {
+		t.Errorf("Expected the previous config to be unchanged, got %v", initial.DBName)
+	}
+
+	// a reload of a group that does not change keeps the fingerprint of the whole config
+	changes, err = w.ReloadGroup("database")
+	history := w.History()
+	if err != nil || len(changes) != 0 || history[len(history)-1].Fingerprint != history[len(history)-2].Fingerprint {
+		t.Errorf("Expected the fingerprint to be kept, got %v, %v", history, err)
+	}
+	fingerprint, err := Fingerprint(w.Config(), WithSources(env))
+	if err != nil || history[len(history)-1].Fingerprint != fingerprint {
+		t.Errorf("Expected the fingerprint of the whole config %s, got %s, %v", fingerprint, history[len(history)-1].Fingerprint, err)
+	}
+
+	_, err = w.ReloadGroup("cache")
+	expected := "no fields in group cache"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
+
+type EmbeddedDBConfig struct {
+	DBName string `env:"DB_NAME;group:database"`
+}
+
+func TestWatcherHistory(t *testing.T) {
+	env := MapSource{"HOST": "localhost", "PASSWORD": "hunter2"}
+	w, err := NewWatcher[WatchConfig](WithSources(env))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	env["PORT"] = "invalid"
+	_, _ = w.Reload()
+	env["PORT"] = "9090"
+	_, _ = w.Reload()
+	for i := 0; i < historySize-2; i++ {
+		_, _ = w.Reload()
+	}
+
+	history := w.History()
+	if len(history) != historySize {
+		t.Fatalf("Expected %d records, got %d", historySize, len(history))
+	}
+	failed, changed, unchanged := history[0], history[1], history[2]
+	if failed.Error == "" || failed.Fingerprint != "" {
+		t.Errorf("Expected the initial load to be dropped and the oldest record to fail, got %v", failed)
+	}
+	if changed.Error != "" || !reflect.DeepEqual(changed.Changed, []string{"Port"}) || len(changed.Fingerprint) != 64 {
+		t.Errorf("Expected the oldest record to change Port, got %v", changed)
+	}
+	if unchanged.Fingerprint != changed.Fingerprint || unchanged.SecretFingerprint != changed.SecretFingerprint || len(unchanged.Changed) != 0 {
+		t.Errorf("Expected an unchanged reload with the same fingerprint, got %v", unchanged)
+	}
+
+	env["PASSWORD"] = "hunter3"
+	_, _ = w.Reload()
+	history = w.History()
+	rotated := history[len(history)-1]
+	if rotated.Fingerprint != changed.Fingerprint || rotated.SecretFingerprint == changed.SecretFingerprint || len(rotated.SecretFingerprint) != 64 {
+		t.Errorf("Expected a rotated secret to only change the secret fingerprint, got %v", rotated)
+	}
+
+	recorder := httptest.NewRecorder()
+	w.HistoryHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	var served []LoadRecord
+	err = json.Unmarshal(recorder.Body.Bytes(), &served)
+	if err != nil || len(served) != historySize || served[1].Fingerprint != changed.Fingerprint {
+		t.Errorf("Expected the history as JSON, got %s", recorder.Body)
+	}
+}
+
+func TestDiffFormatting(t *testing.T) {
+	old := WatchConfig{Host: "localhost", Port: 8080, Password: "hunter2"}
+	new := WatchConfig{Host: "db", Port: 8080, Password: "hunter3"}
+	changes, err := Diff(&old, &new)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := "HOST: localhost -> db\nPASSWORD: **** -> ****"
+	if changes.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, changes.String())
+	}
+
+	var builder strings.Builder
+	logger := slog.New(slog.NewTextHandler(&builder, &slog.HandlerOptions{ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}}))
+	logger.Info("config changed", "changes", changes)
+	expected = "level=INFO msg=\"config changed\" changes.HOST.old=localhost changes.HOST.new=db changes.PASSWORD.old=**** changes.PASSWORD.new=****\n"
+	if builder.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, builder.String())
+	}
+}
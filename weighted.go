@@ -0,0 +1,67 @@
+package goloadenv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WeightedEndpoint is a named endpoint with a relative weight, e.g. for traffic splitting or failover.
+type WeightedEndpoint struct {
+	Name   string
+	Weight int
+}
+
+// WeightedEndpoints is an ordered list of weighted endpoints, parsed from comma separated name=weight pairs like
+// ENDPOINTS=us-east=10,eu-west=5. Names must be unique, weights must not be negative and at least one weight must be
+// positive.
+type WeightedEndpoints []WeightedEndpoint
+
+// UnmarshalText parses a list of comma separated name=weight pairs.
+func (e *WeightedEndpoints) UnmarshalText(text []byte) error {
+	entries, err := parseMapString(string(text))
+	if err != nil {
+		return err
+	}
+	endpoints := make(WeightedEndpoints, 0, len(entries))
+	seen := map[string]struct{}{}
+	for _, entry := range entries {
+		name := strings.TrimSpace(entry[0])
+		if _, duplicate := seen[name]; duplicate {
+			return fmt.Errorf("duplicate endpoint '%s'", name)
+		}
+		seen[name] = struct{}{}
+		weight, err := strconv.Atoi(strings.TrimSpace(entry[1]))
+		if err != nil {
+			return fmt.Errorf("invalid weight '%s' for endpoint '%s'", entry[1], name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("negative weight %d for endpoint '%s'", weight, name)
+		}
+		endpoints = append(endpoints, WeightedEndpoint{Name: name, Weight: weight})
+	}
+	if endpoints.Total() == 0 {
+		return errors.New("at least one endpoint must have a positive weight")
+	}
+	*e = endpoints
+	return nil
+}
+
+// Total returns the sum of the weights of all endpoints.
+func (e WeightedEndpoints) Total() int {
+	total := 0
+	for _, endpoint := range e {
+		total += endpoint.Weight
+	}
+	return total
+}
+
+// String formats the endpoints back into comma separated name=weight pairs.
+func (e WeightedEndpoints) String() string {
+	pairs := make([]string, len(e))
+	for i, endpoint := range e {
+		pairs[i] = endpoint.Name + "=" + strconv.Itoa(endpoint.Weight)
+	}
+	return strings.Join(pairs, ",")
+}